@@ -0,0 +1,99 @@
+package routey
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrCORSAnyOriginCredentials is the panic of [CORS] when credentials are
+// allowed for any origin, which would let any site make credentialed requests.
+var ErrCORSAnyOriginCredentials = errors.New("CORS credentials cannot be allowed for any origin")
+
+// CORSOptions configures the headers set by [CORS].
+type CORSOptions struct {
+	// AllowedOrigins that can make requests, "*" allows any origin.
+	AllowedOrigins []string
+	// AllowedMethods that can be used by cross origin requests.
+	// Defaults to GET, HEAD and POST when empty.
+	AllowedMethods []string
+	// AllowedHeaders that can be sent by cross origin requests.
+	AllowedHeaders []string
+	// AllowCredentials allows requests to include cookies and
+	// authorization headers. Cannot be used when any origin is allowed.
+	AllowCredentials bool
+	// MaxAge is how long the result of a preflight request can be cached,
+	// rounded down to the nearest second. Not sent when zero.
+	MaxAge time.Duration
+}
+
+func (o CORSOptions) allowsOrigin(origin string) bool {
+	return slices.Contains(o.AllowedOrigins, "*") ||
+		slices.Contains(o.AllowedOrigins, origin)
+}
+
+func (o CORSOptions) allowedMethods() string {
+	if len(o.AllowedMethods) == 0 {
+		return strings.Join([]string{http.MethodGet, http.MethodHead, http.MethodPost}, ", ")
+	}
+	return strings.Join(o.AllowedMethods, ", ")
+}
+
+// CORS returns a middleware setting the Access-Control headers on requests from
+// an allowed origin, responding to preflight requests with a 204.
+//
+// Preflight requests are only seen by the middleware for routes with an OPTIONS
+// handler, such as those added by [Router.AutoOptions], or when the middleware
+// is deferred with [Router.DeferMiddleware].
+//
+// CORS panics with [ErrCORSAnyOriginCredentials] when the options allow
+// credentials for any origin.
+func CORS(opts CORSOptions) Middleware {
+	anyOrigin := slices.Contains(opts.AllowedOrigins, "*")
+	if anyOrigin && opts.AllowCredentials {
+		panic(ErrCORSAnyOriginCredentials)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			origin := req.Header.Get("Origin")
+			header := w.Header()
+			header.Add("Vary", "Origin")
+
+			if origin == "" || !opts.allowsOrigin(origin) {
+				next.ServeHTTP(w, req)
+				return
+			}
+
+			if anyOrigin {
+				header.Set("Access-Control-Allow-Origin", "*")
+			} else {
+				header.Set("Access-Control-Allow-Origin", origin)
+			}
+
+			if opts.AllowCredentials {
+				header.Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			isPreflight := req.Method == http.MethodOptions &&
+				req.Header.Get("Access-Control-Request-Method") != ""
+			if !isPreflight {
+				next.ServeHTTP(w, req)
+				return
+			}
+
+			header.Set("Access-Control-Allow-Methods", opts.allowedMethods())
+			if len(opts.AllowedHeaders) > 0 {
+				header.Set("Access-Control-Allow-Headers", strings.Join(opts.AllowedHeaders, ", "))
+			}
+
+			if seconds := int(opts.MaxAge.Seconds()); seconds > 0 {
+				header.Set("Access-Control-Max-Age", strconv.Itoa(seconds))
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
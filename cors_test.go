@@ -0,0 +1,105 @@
+package routey_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/zhamlin/routey"
+	"github.com/zhamlin/routey/internal/test"
+)
+
+func newCORSRouter(t *testing.T) *routey.Router {
+	t.Helper()
+
+	r := newTestRouter(t)
+	r.AutoOptions = true
+	r.Use(routey.CORS(routey.CORSOptions{
+		AllowedOrigins:   []string{"https://example.com"},
+		AllowedMethods:   []string{http.MethodGet, http.MethodPut},
+		AllowedHeaders:   []string{"Content-Type", "Authorization"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	}))
+
+	r.Get("/users", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	return r
+}
+
+func TestCORS_Preflight(t *testing.T) {
+	r := newCORSRouter(t)
+
+	req := newRequest(t, http.MethodOptions, "/users", nil)
+	req.Header.Set("Origin", "https://example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPut)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	header := w.Header()
+	test.Equal(t, w.Code, http.StatusNoContent)
+	test.Equal(t, header.Get("Access-Control-Allow-Origin"), "https://example.com")
+	test.Equal(t, header.Get("Access-Control-Allow-Methods"), "GET, PUT")
+	test.Equal(t, header.Get("Access-Control-Allow-Headers"), "Content-Type, Authorization")
+	test.Equal(t, header.Get("Access-Control-Allow-Credentials"), "true")
+	test.Equal(t, header.Get("Access-Control-Max-Age"), "600")
+}
+
+func TestCORS_AllowedOrigin(t *testing.T) {
+	r := newCORSRouter(t)
+
+	req := newRequest(t, http.MethodGet, "/users", nil)
+	req.Header.Set("Origin", "https://example.com")
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	header := w.Header()
+	test.Equal(t, w.Code, http.StatusOK)
+	test.Equal(t, header.Get("Access-Control-Allow-Origin"), "https://example.com")
+	test.Equal(t, header.Get("Access-Control-Allow-Credentials"), "true")
+	test.Equal(t, header.Get("Access-Control-Allow-Methods"), "")
+	test.Equal(t, header.Get("Vary"), "Origin")
+}
+
+func TestCORS_DisallowedOrigin(t *testing.T) {
+	r := newCORSRouter(t)
+
+	req := newRequest(t, http.MethodGet, "/users", nil)
+	req.Header.Set("Origin", "https://other.com")
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	test.Equal(t, w.Code, http.StatusOK)
+	test.Equal(t, w.Header().Get("Access-Control-Allow-Origin"), "")
+}
+
+func TestCORS_AnyOrigin(t *testing.T) {
+	r := newTestRouter(t)
+	r.With(routey.CORS(routey.CORSOptions{AllowedOrigins: []string{"*"}})).
+		Get("/", func(http.ResponseWriter, *http.Request) {})
+
+	req := newRequest(t, http.MethodGet, "/", nil)
+	req.Header.Set("Origin", "https://example.com")
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	test.Equal(t, w.Header().Get("Access-Control-Allow-Origin"), "*")
+}
+
+func TestCORS_AnyOriginWithCredentials(t *testing.T) {
+	defer func() {
+		test.Equal(t, recover(), any(routey.ErrCORSAnyOriginCredentials))
+	}()
+
+	routey.CORS(routey.CORSOptions{
+		AllowedOrigins:   []string{"*"},
+		AllowCredentials: true,
+	})
+	t.Error("expected CORS to panic")
+}
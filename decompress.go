@@ -0,0 +1,33 @@
+package routey
+
+import (
+	"net/http"
+
+	"github.com/zhamlin/routey/extractor"
+)
+
+// DecompressRequest returns a middleware decompressing request bodies with
+// a gzip or deflate Content-Encoding, see [extractor.DecompressBody]. Errors
+// decompressing the body are passed to the Response handler of r with a 400,
+// or written directly when r has no Response handler.
+//
+// Body size limits, such as [route.Info] MaxBodySize, apply to the
+// decompressed body.
+func DecompressRequest(r *Router) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			err := extractor.DecompressBody(req)
+			if err == nil {
+				next.ServeHTTP(w, req)
+				return
+			}
+
+			code := http.StatusBadRequest
+			if r.Response == nil {
+				http.Error(w, http.StatusText(code), code)
+				return
+			}
+			r.Response(w, req, extractor.Response{Error: err, Status: code})
+		})
+	}
+}
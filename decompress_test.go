@@ -0,0 +1,102 @@
+package routey_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/zhamlin/routey"
+	"github.com/zhamlin/routey/extractor"
+	"github.com/zhamlin/routey/internal/test"
+)
+
+func compress(t *testing.T, encoding, body string) io.Reader {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	var w io.WriteCloser = gzip.NewWriter(buf)
+	if encoding == "deflate" {
+		w = zlib.NewWriter(buf)
+	}
+
+	_, err := w.Write([]byte(body))
+	test.NoError(t, err)
+	test.NoError(t, w.Close())
+	return buf
+}
+
+func TestDecompressRequest(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+	}
+	type input struct {
+		Body routey.JSON[user]
+	}
+
+	for _, encoding := range []string{"gzip", "deflate"} {
+		t.Run(encoding, func(t *testing.T) {
+			r := newTestRouter(t)
+			r.Use(routey.DecompressRequest(r))
+
+			var got user
+			routey.Post(r, "/", func(in input) (any, error) {
+				got = in.Body.V
+				return nil, nil
+			})
+
+			req := newRequest(t, http.MethodPost, "/", compress(t, encoding, `{"name": "a"}`))
+			req.Header.Set("Content-Encoding", encoding)
+
+			compareRespStatus(t, r, req, http.StatusOK)
+			test.Equal(t, got, user{Name: "a"})
+		})
+	}
+}
+
+func TestDecompressRequest_InvalidBody(t *testing.T) {
+	r := newTestRouter(t)
+	r.Use(routey.DecompressRequest(r))
+
+	var gotResp extractor.Response
+	r.Response = func(w http.ResponseWriter, _ *http.Request, resp extractor.Response) {
+		gotResp = resp
+		http.Error(w, "", resp.Status)
+	}
+	r.Post("/", func(http.ResponseWriter, *http.Request) {})
+
+	req := newRequest(t, http.MethodPost, "/", strings.NewReader(`{"name": "a"}`))
+	req.Header.Set("Content-Encoding", "gzip")
+
+	compareRespStatus(t, r, req, http.StatusBadRequest)
+	test.IsError(t, gotResp.Error, extractor.ErrDecompressBody)
+}
+
+func TestDecompressRequest_CorruptBody(t *testing.T) {
+	type input struct {
+		Body routey.JSON[struct{}]
+	}
+
+	r := newTestRouter(t)
+	r.Use(routey.DecompressRequest(r))
+
+	var gotResp extractor.Response
+	r.Response = func(w http.ResponseWriter, _ *http.Request, resp extractor.Response) {
+		gotResp = resp
+		http.Error(w, "", resp.Status)
+	}
+	routey.Post(r, "/", func(input) (any, error) { return nil, nil })
+
+	// a valid gzip header followed by a truncated stream
+	body, err := io.ReadAll(compress(t, "gzip", `{"name": "a"}`))
+	test.NoError(t, err)
+
+	req := newRequest(t, http.MethodPost, "/", bytes.NewReader(body[:len(body)-4]))
+	req.Header.Set("Content-Encoding", "gzip")
+
+	compareRespStatus(t, r, req, http.StatusBadRequest)
+	test.IsError(t, gotResp.Error, extractor.ErrDecompressBody)
+}
@@ -21,6 +21,9 @@ type ErrorConfig struct {
 
 	// Whether or not to stop after the first extractor error.
 	CollectAll bool
+	// Whether or not to recover panics in typed handlers, passing an
+	// [extractor.PanicError] to the Response handler instead.
+	RecoverPanics bool
 }
 
 func (e ErrorConfig) color() structs.Colors {
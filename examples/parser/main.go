@@ -2,7 +2,6 @@
 package main
 
 import (
-	"encoding/json"
 	"errors"
 	"log/slog"
 	"net/http"
@@ -41,15 +40,9 @@ func parseObject(value any, params []string) error {
 func newRouter() *routey.Router {
 	r := routey.New()
 
-	r.Response = func(w http.ResponseWriter, _ *http.Request, resp extractor.Response) {
-		b, _ := json.Marshal(resp.Response)
-		w.Write(b)
-	}
+	r.Response = extractor.JSONResponse(extractor.JSONResponseOptions{})
 
-	r.Params.Parser = param.Parsers{
-		parseObject,
-		r.Params.Parser,
-	}.Parse
+	r.Params.AddParser(parseObject)
 
 	return r
 }
@@ -0,0 +1,178 @@
+package extractor
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/zhamlin/routey/param"
+	"github.com/zhamlin/routey/route"
+)
+
+// BodyDecoder decodes the http request body into dest, a pointer to
+// the value of a [Body].
+type BodyDecoder func(r *http.Request, dest any, opts param.Opts) error
+
+var bodyDecoders = struct {
+	sync.RWMutex
+	m map[string]BodyDecoder
+}{
+	m: map[string]BodyDecoder{
+		"application/json": func(r *http.Request, dest any, _ param.Opts) error {
+			return decodeBodyJSON(r, dest)
+		},
+		"application/xml": func(r *http.Request, dest any, _ param.Opts) error {
+			return decodeBodyXML(r, dest)
+		},
+		"application/x-www-form-urlencoded": func(r *http.Request, dest any, opts param.Opts) error {
+			if err := r.ParseForm(); err != nil {
+				return fmt.Errorf("%w: %w", ErrReadBody, err)
+			}
+			return decodeForm(r.PostForm, dest, FormTagKey(opts.FormTagKey), opts)
+		},
+	},
+}
+
+// RegisterBodyDecoder registers fn to decode the bodies of requests with the
+// media type, replacing any decoder already registered for it. Decoders
+// for json, xml and url encoded forms are registered by default.
+func RegisterBodyDecoder(mediaType string, fn BodyDecoder) {
+	bodyDecoders.Lock()
+	defer bodyDecoders.Unlock()
+	bodyDecoders.m[mediaType] = fn
+}
+
+// UnregisterBodyDecoder removes the decoder registered for the media type.
+func UnregisterBodyDecoder(mediaType string) {
+	bodyDecoders.Lock()
+	defer bodyDecoders.Unlock()
+	delete(bodyDecoders.m, mediaType)
+}
+
+func getBodyDecoder(mediaType string) (BodyDecoder, bool) {
+	bodyDecoders.RLock()
+	defer bodyDecoders.RUnlock()
+	fn, has := bodyDecoders.m[mediaType]
+	return fn, has
+}
+
+// BodyMediaTypes returns the media types with a decoder registered, sorted.
+func BodyMediaTypes() []string {
+	bodyDecoders.RLock()
+	defer bodyDecoders.RUnlock()
+	return slices.Sorted(maps.Keys(bodyDecoders.m))
+}
+
+// Body allows T to be decoded from the http request body by the decoder
+// registered for the media type of the Content-Type header, see
+// [RegisterBodyDecoder]. Requests with a media type without a decoder
+// respond with a 415.
+type Body[T any] struct{ V T }
+
+func (b *Body[T]) Extract(r *http.Request, _ *route.Info, opts param.Opts) error {
+	hasBody := r.Body != nil && r.ContentLength > 0
+	if !hasBody {
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	decode, has := getBodyDecoder(mediaType)
+	if !has {
+		return statusCodeError{
+			err:    fmt.Errorf("%w: %q", ErrUnsupportedContentType, mediaType),
+			status: http.StatusUnsupportedMediaType,
+		}
+	}
+
+	return decode(r, &b.V, opts)
+}
+
+func (Body[T]) Source() string {
+	return "body"
+}
+
+func (b Body[T]) Inner() any {
+	return b.V
+}
+
+func (b Body[T]) CanParse(_ param.Parser, _ reflect.StructField, _ any) error {
+	return nil
+}
+
+// ContentTypes returns the media types the body can be decoded from.
+func (Body[T]) ContentTypes() []string {
+	return BodyMediaTypes()
+}
+
+var ErrDecompressBody = errors.New("error decompressing http request body")
+
+// decompressError wraps an error from decompressing the body, responding with a 400.
+func decompressError(err error) error {
+	return statusCodeError{
+		err:    fmt.Errorf("%w: %w", ErrDecompressBody, err),
+		status: http.StatusBadRequest,
+	}
+}
+
+// DecompressBody replaces the body of requests with a gzip or deflate
+// Content-Encoding with a reader decompressing it, and removes the header.
+// Requests without a body are left as is, and the Content-Length is kept
+// so extractors still see the body.
+//
+// Errors decompressing the body are wrapped with [ErrDecompressBody]
+// and respond with a 400, both from DecompressBody and when reading.
+func DecompressBody(r *http.Request) error {
+	hasBody := r.Body != nil && r.ContentLength != 0
+	encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding")))
+
+	if !hasBody || encoding == "" {
+		return nil
+	}
+
+	var body io.ReadCloser
+	var err error
+
+	switch encoding {
+	case "gzip", "x-gzip":
+		body, err = gzip.NewReader(r.Body)
+	case "deflate":
+		// the deflate content coding is zlib, see RFC 9110 section 8.4.1.2
+		body, err = zlib.NewReader(r.Body)
+	default:
+		return nil
+	}
+
+	if err != nil {
+		return decompressError(err)
+	}
+
+	r.Body = decompressReader{ReadCloser: body, compressed: r.Body}
+	r.Header.Del("Content-Encoding")
+	return nil
+}
+
+type decompressReader struct {
+	io.ReadCloser
+	compressed io.ReadCloser
+}
+
+func (d decompressReader) Read(p []byte) (int, error) {
+	n, err := d.ReadCloser.Read(p)
+	if err != nil && !errors.Is(err, io.EOF) {
+		err = decompressError(err)
+	}
+	return n, err
+}
+
+func (d decompressReader) Close() error {
+	return errors.Join(d.ReadCloser.Close(), d.compressed.Close())
+}
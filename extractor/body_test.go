@@ -0,0 +1,82 @@
+package extractor_test
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/zhamlin/routey"
+	"github.com/zhamlin/routey/extractor"
+	"github.com/zhamlin/routey/internal/test"
+	"github.com/zhamlin/routey/param"
+)
+
+type bodyUser struct {
+	Name string `json:"name" xml:"name" form:"name"`
+}
+
+func TestBodyExtractor_ContentTypes(t *testing.T) {
+	tests := []struct {
+		contentType string
+		body        string
+	}{
+		{contentType: "application/json", body: `{"name": "a"}`},
+		{contentType: "application/json; charset=utf-8", body: `{"name": "a"}`},
+		{contentType: "application/xml", body: `<user><name>a</name></user>`},
+		{contentType: "application/x-www-form-urlencoded", body: `name=a`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			r := newRequest(t, http.MethodPost, "/", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", tt.contentType)
+
+			got := routey.Body[bodyUser]{}
+			err := got.Extract(r, nil, param.Opts{Parser: param.ParseString})
+			test.NoError(t, err)
+			test.Equal(t, got.V, bodyUser{Name: "a"})
+		})
+	}
+}
+
+func TestBodyExtractor_UnsupportedMediaType(t *testing.T) {
+	r := newRequest(t, http.MethodPost, "/", strings.NewReader(`name: a`))
+	r.Header.Set("Content-Type", "application/yaml")
+
+	got := routey.Body[bodyUser]{}
+	err := got.Extract(r, nil, param.Opts{})
+	test.IsError(t, err, extractor.ErrUnsupportedContentType)
+
+	var coder extractor.StatusCoder
+	test.WantError(t, err, &coder)
+	test.Equal(t, coder.StatusCode(), http.StatusUnsupportedMediaType)
+}
+
+func TestBodyExtractor_EmptyBody(t *testing.T) {
+	r := newRequest(t, http.MethodPost, "/", nil)
+
+	got := routey.Body[bodyUser]{}
+	test.NoError(t, got.Extract(r, nil, param.Opts{}))
+}
+
+func TestRegisterBodyDecoder(t *testing.T) {
+	mediaType := "text/plain"
+	extractor.RegisterBodyDecoder(mediaType, func(r *http.Request, dest any, _ param.Opts) error {
+		body, err := extractor.GetAndSetBody(r)
+		if err != nil {
+			return err
+		}
+		dest.(*bodyUser).Name = string(body)
+		return nil
+	})
+	t.Cleanup(func() { extractor.UnregisterBodyDecoder(mediaType) })
+
+	r := newRequest(t, http.MethodPost, "/", strings.NewReader(`a`))
+	r.Header.Set("Content-Type", mediaType)
+
+	got := routey.Body[bodyUser]{}
+	test.NoError(t, got.Extract(r, nil, param.Opts{}))
+	test.Equal(t, got.V, bodyUser{Name: "a"})
+	test.Equal(t, slices.Contains(got.ContentTypes(), mediaType), true)
+}
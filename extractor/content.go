@@ -0,0 +1,123 @@
+package extractor
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"net/http"
+	"slices"
+	"sync"
+)
+
+// ResponseEncoder encodes the value returned by a handler in a [Content] to w.
+type ResponseEncoder func(w io.Writer, value any) error
+
+var responseEncoders = struct {
+	sync.RWMutex
+	m map[string]ResponseEncoder
+}{
+	m: map[string]ResponseEncoder{
+		"application/json": func(w io.Writer, value any) error {
+			return json.NewEncoder(w).Encode(value)
+		},
+		"application/xml": func(w io.Writer, value any) error {
+			return xml.NewEncoder(w).Encode(value)
+		},
+		"text/plain": func(w io.Writer, value any) error {
+			_, err := fmt.Fprint(w, value)
+			return err
+		},
+	},
+}
+
+// RegisterResponseEncoder registers fn to encode the values of a [Content] with the
+// media type, replacing any encoder already registered for it. Encoders for json,
+// xml and plain text are registered by default.
+func RegisterResponseEncoder(mediaType string, fn ResponseEncoder) {
+	responseEncoders.Lock()
+	defer responseEncoders.Unlock()
+	responseEncoders.m[mediaType] = fn
+}
+
+// UnregisterResponseEncoder removes the encoder registered for the media type.
+func UnregisterResponseEncoder(mediaType string) {
+	responseEncoders.Lock()
+	defer responseEncoders.Unlock()
+	delete(responseEncoders.m, mediaType)
+}
+
+func getResponseEncoder(mediaType string) (ResponseEncoder, bool) {
+	responseEncoders.RLock()
+	defer responseEncoders.RUnlock()
+	fn, has := responseEncoders.m[mediaType]
+	return fn, has
+}
+
+// ResponseMediaTypes returns the media types with an encoder registered, sorted.
+func ResponseMediaTypes() []string {
+	responseEncoders.RLock()
+	defer responseEncoders.RUnlock()
+	return slices.Sorted(maps.Keys(responseEncoders.m))
+}
+
+var ErrNoResponseEncoder = errors.New("no response encoder for the content type")
+
+// Content is returned by handlers to respond with Value encoded by the encoder
+// registered for the media type of Type, see [RegisterResponseEncoder]. It is
+// written with Type as the Content-Type header instead of being passed to the
+// [ResponseHandler], which only receives errors from encoding it.
+//
+// Routes documented with openapi3 should declare the content type of the
+// response with option.ContentResponse, as it is only known once the
+// handler returns.
+type Content struct {
+	Type  string
+	Value any
+	// Status is the http status code of the response, 200 when zero.
+	Status int
+}
+
+// directResponse is implemented by responses written by [Handler]
+// instead of being passed to the [ResponseHandler].
+type directResponse interface {
+	write(w http.ResponseWriter, r *http.Request) error
+}
+
+// writtenError is returned by a [directResponse] failing after the response
+// was written, so the error can only be passed to the ErrorSink.
+type writtenError struct {
+	error
+}
+
+func (e writtenError) Unwrap() error {
+	return e.error
+}
+
+// write encodes the value to w, writing nothing when it fails.
+func (c Content) write(w http.ResponseWriter, _ *http.Request) error {
+	mediaType, _, _ := mime.ParseMediaType(c.Type)
+	encode, has := getResponseEncoder(mediaType)
+	if !has {
+		return fmt.Errorf("%w: %q", ErrNoResponseEncoder, c.Type)
+	}
+
+	var b bytes.Buffer
+	if err := encode(&b, c.Value); err != nil {
+		return fmt.Errorf("error encoding response as %s: %w", mediaType, err)
+	}
+
+	status := c.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	w.Header().Set("Content-Type", c.Type)
+	w.WriteHeader(status)
+	_, _ = w.Write(b.Bytes())
+	return nil
+}
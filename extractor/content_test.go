@@ -0,0 +1,60 @@
+package extractor_test
+
+import (
+	"encoding/csv"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/zhamlin/routey"
+	"github.com/zhamlin/routey/extractor"
+	"github.com/zhamlin/routey/internal/test"
+)
+
+func TestContent_RegisteredEncoder(t *testing.T) {
+	mediaType := "text/csv"
+	extractor.RegisterResponseEncoder(mediaType, func(w io.Writer, value any) error {
+		return csv.NewWriter(w).WriteAll(value.([][]string))
+	})
+	t.Cleanup(func() { extractor.UnregisterResponseEncoder(mediaType) })
+
+	params := extractor.HandlerParams{
+		Response: func(http.ResponseWriter, *http.Request, extractor.Response) {
+			t.Fatal("expected the content to be written without the response handler")
+		},
+	}
+
+	fn := func(struct{}) (routey.Content, error) {
+		rows := [][]string{{"id", "name"}, {"1", "a"}}
+		return routey.Content{Type: "text/csv; charset=utf-8", Value: rows}, nil
+	}
+	h := extractor.Handler(fn, params)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, newRequest(t, http.MethodGet, "/", nil))
+
+	test.Equal(t, w.Code, http.StatusOK)
+	test.Equal(t, w.Header().Get("Content-Type"), "text/csv; charset=utf-8")
+	test.Equal(t, w.Body.String(), "id,name\n1,a\n")
+}
+
+func TestContent_NoEncoder(t *testing.T) {
+	var gotErr error
+	params := extractor.HandlerParams{
+		Response: func(_ http.ResponseWriter, _ *http.Request, resp extractor.Response) {
+			gotErr = resp.Error
+		},
+	}
+
+	fn := func(struct{}) (any, error) {
+		return routey.Content{Type: "application/yaml", Value: "a"}, nil
+	}
+	h := extractor.Handler(fn, params)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, newRequest(t, http.MethodGet, "/", nil))
+
+	test.IsError(t, gotErr, extractor.ErrNoResponseEncoder)
+	test.Equal(t, w.Body.Len(), 0)
+}
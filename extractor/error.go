@@ -9,6 +9,27 @@ import (
 	"github.com/zhamlin/routey/internal/structs"
 )
 
+// FieldError is returned for each field that failed to be
+// extracted when collecting all errors.
+type FieldError struct {
+	// Name of the param the field represents.
+	Name string
+	// Source of the param, empty if the field has no source.
+	Source string
+	Err    error
+}
+
+func (e *FieldError) Error() string {
+	if e.Source == "" {
+		return fmt.Sprintf("%q: %s", e.Name, e.Err)
+	}
+	return fmt.Sprintf("%s %q: %s", e.Source, e.Name, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
 // UnknownFieldTypeError represents a field that has no way
 // of being extracted.
 type UnknownFieldTypeError struct {
@@ -1,21 +1,38 @@
 package extractor
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"encoding/xml"
 	"errors"
 	"fmt"
+	"io"
+	"mime"
 	"net/http"
 	"net/url"
 	"reflect"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"unsafe"
 
+	"github.com/zhamlin/routey/internal/structs"
 	"github.com/zhamlin/routey/param"
 	"github.com/zhamlin/routey/route"
 )
 
-var ErrParamFailedToExtract = errors.New("failed to extract param")
+var (
+	ErrParamFailedToExtract = errors.New("failed to extract param")
+	ErrParamRequired        = param.ErrMissingRequired
+)
+
+// ParamError wraps an error parsing or validating a param of the request
+// with [ErrParamFailedToExtract], responding with a 400.
+func ParamError(err error) error {
+	return WithStatusCode(fmt.Errorf("%w: %w", ErrParamFailedToExtract, err), http.StatusBadRequest)
+}
 
 func GetAndSetQueryValues(r *http.Request) url.Values {
 	type cachedQueryKey struct{}
@@ -32,10 +49,55 @@ func GetAndSetQueryValues(r *http.Request) url.Values {
 	return values
 }
 
+var ErrReadBody = errors.New("error reading http request body")
+
+type cachedBodyKey struct{}
+
+// GetAndSetBody reads and caches the http request body. r.Body is
+// replaced with a reader over the cached bytes so it can be read again.
+func GetAndSetBody(r *http.Request) ([]byte, error) {
+	ctx := r.Context()
+	body, ok := ctx.Value(cachedBodyKey{}).([]byte)
+
+	if !ok {
+		if r.Body != nil {
+			b, err := io.ReadAll(r.Body)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrReadBody, err)
+			}
+			body = b
+		}
+
+		ctx = context.WithValue(ctx, cachedBodyKey{}, body)
+		*r = *r.WithContext(ctx)
+	}
+
+	if r.Body != nil {
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	return body, nil
+}
+
+// bodyReader returns a reader over the body cached by [GetAndSetBody],
+// or r.Body when it has not been cached.
+func bodyReader(r *http.Request) io.Reader {
+	if body, ok := r.Context().Value(cachedBodyKey{}).([]byte); ok {
+		return bytes.NewReader(body)
+	}
+	return r.Body
+}
+
 var (
 	_ ParamExtractor = &Query[string]{}
 	_ ParamExtractor = &Path[string]{}
+	_ ParamExtractor = &QueryOrForm[string]{}
+	_ ParamExtractor = &Form[struct{}]{}
+	_ ParamExtractor = &OneOfBody[JSON[string], Form[struct{}]]{}
+	_ ParamExtractor = &Body[string]{}
 	_ Extractor      = &JSON[string]{}
+	_ Extractor      = &XML[string]{}
+	_ Extractor      = &RawBody{}
 )
 
 // Path allows T to be parsed from the url path.
@@ -48,7 +110,7 @@ func (p *Path[T]) Extract(r *http.Request, _ *route.Info, opts param.Opts) error
 	err := opts.Parse(&p.Value, []string{value})
 
 	if err != nil {
-		return fmt.Errorf("%w: %w", ErrParamFailedToExtract, err)
+		return ParamError(err)
 	}
 	return nil
 }
@@ -68,10 +130,18 @@ type Query[T any] struct {
 
 func (q *Query[T]) Extract(r *http.Request, _ *route.Info, opts param.Opts) error {
 	values := GetAndSetQueryValues(r)
-	err := opts.Parse(&q.Value, values[opts.Name])
+	params := values[opts.Name]
+
+	if len(params) == 0 && opts.Required && opts.Default == "" {
+		return ParamError(&param.MissingRequiredError{
+			Name:   opts.Name,
+			Source: q.Source(),
+		})
+	}
 
+	err := opts.Parse(&q.Value, params)
 	if err != nil {
-		return fmt.Errorf("%w: %w", ErrParamFailedToExtract, err)
+		return ParamError(err)
 	}
 	return nil
 }
@@ -84,11 +154,80 @@ func (q Query[T]) Inner() any {
 	return q.Value
 }
 
+// QueryOrForm allows T to be parsed from the url query params, or from the
+// url encoded form in the http request body when the query does not contain
+// the param. Query values take precedence over form values. The form is only
+// read for POST, PUT and PATCH requests with the Content-Type set to
+// application/x-www-form-urlencoded, see [http.Request.ParseForm].
+type QueryOrForm[T any] struct {
+	Value T
+}
+
+func (q *QueryOrForm[T]) Extract(r *http.Request, _ *route.Info, opts param.Opts) error {
+	params := GetAndSetQueryValues(r)[opts.Name]
+
+	if len(params) == 0 {
+		if err := r.ParseForm(); err != nil {
+			return fmt.Errorf("%w: %w", ErrReadBody, err)
+		}
+		params = r.PostForm[opts.Name]
+	}
+
+	if len(params) == 0 && opts.Required && opts.Default == "" {
+		return ParamError(&param.MissingRequiredError{
+			Name:   opts.Name,
+			Source: q.Source(),
+		})
+	}
+
+	err := opts.Parse(&q.Value, params)
+	if err != nil {
+		return ParamError(err)
+	}
+	return nil
+}
+
+// Source returns query, as the param is documented as a query param.
+func (QueryOrForm[T]) Source() string {
+	return "query"
+}
+
+func (q QueryOrForm[T]) Inner() any {
+	return q.Value
+}
+
+// Validator is implemented by types validating themselves after being decoded.
+type Validator interface {
+	Validate() error
+}
+
+var ErrValidate = errors.New("http request body failed validation")
+
+// validate calls Validate when v implements [Validator]. Errors without a
+// status code respond with a 400.
+func validate(v any) error {
+	validator, ok := v.(Validator)
+	if !ok {
+		return nil
+	}
+
+	err := validator.Validate()
+	if err == nil {
+		return nil
+	}
+
+	return WithStatusCode(fmt.Errorf("%w: %w", ErrValidate, err), http.StatusBadRequest)
+}
+
 // JSON allows T to be json decoded from the http request body.
+// After decoding, Validate is called when T or *T implements [Validator].
 type JSON[T any] struct{ V T }
 
 func (v *JSON[T]) Extract(r *http.Request, _ *route.Info) error {
-	return decodeBodyJSON(r, &v.V)
+	if err := decodeBodyJSON(r, &v.V); err != nil {
+		return err
+	}
+	return validate(&v.V)
 }
 
 func (JSON[T]) Source() string {
@@ -103,20 +242,250 @@ func (v JSON[T]) CanParse(_ param.Parser, _ reflect.StructField, value any) erro
 	return nil
 }
 
+func (JSON[T]) ContentType() string {
+	return "application/json"
+}
+
+// XML allows T to be xml decoded from the http request body.
+type XML[T any] struct{ V T }
+
+func (v *XML[T]) Extract(r *http.Request, _ *route.Info) error {
+	return decodeBodyXML(r, &v.V)
+}
+
+func (XML[T]) Source() string {
+	return "body"
+}
+
+func (v XML[T]) Inner() any {
+	return v.V
+}
+
+func (v XML[T]) CanParse(_ param.Parser, _ reflect.StructField, value any) error {
+	return nil
+}
+
+func (XML[T]) ContentType() string {
+	return "application/xml"
+}
+
+// DefaultFormTagKey is the struct tag used by [Form] to get the name of each
+// field, unless another is set by [param.Config].
+const DefaultFormTagKey = "form"
+
+// FormTagKey returns the struct tag naming the fields of form bodies,
+// the key when set or else [DefaultFormTagKey].
+func FormTagKey(key string) string {
+	if key == "" {
+		return DefaultFormTagKey
+	}
+	return key
+}
+
+// Form allows T to be parsed from the url encoded form in the http request body.
+// Each field of T is parsed from the form value named by its `form` tag,
+// or the tag set by [param.Config].
+type Form[T any] struct{ V T }
+
+func (v *Form[T]) Extract(r *http.Request, _ *route.Info, opts param.Opts) error {
+	if err := r.ParseForm(); err != nil {
+		return fmt.Errorf("%w: %w", ErrReadBody, err)
+	}
+
+	return decodeForm(r.PostForm, &v.V, FormTagKey(opts.FormTagKey), opts)
+}
+
+func (Form[T]) Source() string {
+	return "body"
+}
+
+func (v Form[T]) Inner() any {
+	return v.V
+}
+
+func (v Form[T]) CanParse(_ param.Parser, _ reflect.StructField, value any) error {
+	return nil
+}
+
+func (Form[T]) ContentType() string {
+	return "application/x-www-form-urlencoded"
+}
+
+var ErrFormDecode = errors.New("error decoding http request body as form")
+
+// decodeForm parses the values into the fields of the struct dest points to,
+// using the struct tag with the provided key as the name of each field.
+func decodeForm(values url.Values, dest any, key string, opts param.Opts) error {
+	v := reflect.ValueOf(dest).Elem()
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("type: %T: %w: %w", dest, ErrFormDecode, param.ErrNonStructArg)
+	}
+
+	for i := range v.NumField() {
+		field := v.Type().Field(i)
+		name := structs.TagName(field, key)
+
+		if name == "" || !field.IsExported() {
+			continue
+		}
+
+		params, has := values[name]
+		if !has {
+			continue
+		}
+
+		if err := param.Parse(opts.Parser, v.Field(i).Addr().Interface(), params); err != nil {
+			err = fmt.Errorf("%w: %q: %w", ErrFormDecode, name, err)
+			return WithStatusCode(err, http.StatusBadRequest)
+		}
+	}
+
+	return nil
+}
+
+// ContentTyper is the interface implemented by body extractors
+// that decode a specific content type.
+type ContentTyper interface {
+	ContentType() string
+}
+
+var (
+	ErrUnsupportedContentType = errors.New("unsupported content type")
+	ErrNoContentType          = errors.New("body extractor has no content type")
+)
+
+// OneOfBody allows the http request body to be extracted by either A or B,
+// chosen by matching the Content-Type header to their content types.
+// Only the field of the chosen extractor is set.
+type OneOfBody[A, B ContentTyper] struct {
+	A *A
+	B *B
+}
+
+func (o *OneOfBody[A, B]) Extract(r *http.Request, info *route.Info, opts param.Opts) error {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	var a A
+	if a.ContentType() == mediaType {
+		o.A = &a
+		return extractBody(&a, r, info, opts)
+	}
+
+	var b B
+	if b.ContentType() == mediaType {
+		o.B = &b
+		return extractBody(&b, r, info, opts)
+	}
+
+	return fmt.Errorf("%w: %q", ErrUnsupportedContentType, mediaType)
+}
+
+func (OneOfBody[A, B]) Source() string {
+	return "body"
+}
+
+// Bodies returns the zero value of each body extractor.
+func (OneOfBody[A, B]) Bodies() []ContentTyper {
+	var a A
+	var b B
+	return []ContentTyper{a, b}
+}
+
+func (o OneOfBody[A, B]) CanParse(_ param.Parser, _ reflect.StructField, _ any) error {
+	for _, body := range o.Bodies() {
+		if !isExtractorType(reflect.TypeOf(body)) {
+			return fmt.Errorf("type: %T: %w", body, ErrExtactType)
+		}
+
+		if body.ContentType() == "" {
+			return fmt.Errorf("type: %T: %w", body, ErrNoContentType)
+		}
+	}
+	return nil
+}
+
+func extractBody(body any, r *http.Request, info *route.Info, opts param.Opts) error {
+	switch e := body.(type) {
+	case ParamExtractor:
+		return e.Extract(r, info, opts)
+	case Extractor:
+		return e.Extract(r, info)
+	}
+	return fmt.Errorf("type: %T: %w", body, ErrExtactType)
+}
+
+// RawBody allows the raw http request body to be read. The body is
+// buffered before any fields are extracted when a handler has a RawBody,
+// so other extractors, such as [JSON], can still read it.
+type RawBody struct{ V []byte }
+
+func (b *RawBody) Extract(r *http.Request, _ *route.Info) error {
+	body, err := GetAndSetBody(r)
+	if err != nil {
+		return err
+	}
+
+	b.V = body
+	return nil
+}
+
 var ErrJSONDecode = errors.New("error decoding http request body as json")
 
 func decodeBodyJSON(r *http.Request, dest any) error {
+	return decodeBody(r, dest, ErrJSONDecode, func(body io.Reader) error {
+		return json.NewDecoder(body).Decode(&dest)
+	})
+}
+
+var ErrXMLDecode = errors.New("error decoding http request body as xml")
+
+func decodeBodyXML(r *http.Request, dest any) error {
+	return decodeBody(r, dest, ErrXMLDecode, func(body io.Reader) error {
+		return xml.NewDecoder(body).Decode(dest)
+	})
+}
+
+// decodeBody decodes the body straight from the request, unless it was
+// buffered by [GetAndSetBody]. The rest of the body is read after decoding
+// so errors reading it, such as a corrupt compressed stream, are returned.
+func decodeBody(r *http.Request, dest any, decodeErr error, decode func(io.Reader) error) error {
 	hasBody := r.Body != nil && r.ContentLength > 0
-	if hasBody {
-		if err := json.NewDecoder(r.Body).Decode(&dest); err != nil {
-			return fmt.Errorf("type: %T: %w: %w", dest, ErrJSONDecode, err)
+	if !hasBody {
+		return nil
+	}
+
+	body := &bodyErrReader{r: bodyReader(r)}
+	if err := decode(body); err != nil {
+		if body.err != nil {
+			return fmt.Errorf("%w: %w", ErrReadBody, body.err)
+		}
+
+		return statusCodeError{
+			err:    fmt.Errorf("type: %T: %w: %w", dest, decodeErr, err),
+			status: http.StatusBadRequest,
 		}
 	}
 
+	if _, err := io.Copy(io.Discard, body); err != nil {
+		return fmt.Errorf("%w: %w", ErrReadBody, err)
+	}
 	return nil
 }
 
-var extractors = sync.Map{}
+// bodyErrReader keeps the first error reading r, other than io.EOF,
+// to tell them apart from errors decoding the body.
+type bodyErrReader struct {
+	r   io.Reader
+	err error
+}
+
+func (b *bodyErrReader) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	if err != nil && !errors.Is(err, io.EOF) && b.err == nil {
+		b.err = err
+	}
+	return n, err
+}
 
 type fnExtractor[T any] struct {
 	fn func(*http.Request) (T, error)
@@ -126,9 +495,109 @@ func (f fnExtractor[T]) ExtractType(r *http.Request) (any, error) {
 	return f.fn(r)
 }
 
-func Register[T any](f func(*http.Request) (T, error)) {
+// ExtractorRegistry holds the extractors used for handler argument
+// fields of a specific type. The zero value is ready to use.
+type ExtractorRegistry struct {
+	extractors sync.Map
+	// incremented on every change, invalidating cached extractors
+	version atomic.Uint64
+}
+
+// NewExtractorRegistry returns an empty [ExtractorRegistry].
+func NewExtractorRegistry() *ExtractorRegistry {
+	return &ExtractorRegistry{}
+}
+
+func (e *ExtractorRegistry) getVersion() uint64 {
+	if e == nil {
+		return 0
+	}
+	return e.version.Load()
+}
+
+func (e *ExtractorRegistry) load(t reflect.Type) (any, bool) {
+	if e == nil {
+		return nil, false
+	}
+	return e.extractors.Load(t)
+}
+
+// Unregister removes the extractor registered for t.
+// Handlers already created will continue using the extractor.
+func (e *ExtractorRegistry) Unregister(t reflect.Type) {
+	e.extractors.Delete(t)
+	e.version.Add(1)
+}
+
+// Reset removes all registered extractors.
+func (e *ExtractorRegistry) Reset() {
+	e.extractors.Clear()
+	e.version.Add(1)
+}
+
+// Clone returns a new registry containing the same extractors as e.
+func (e *ExtractorRegistry) Clone() *ExtractorRegistry {
+	if e == nil {
+		return nil
+	}
+
+	cloned := NewExtractorRegistry()
+	e.extractors.Range(func(key, value any) bool {
+		cloned.extractors.Store(key, value)
+		return true
+	})
+	return cloned
+}
+
+// Types returns all types with an extractor registered,
+// sorted by the types name.
+func (e *ExtractorRegistry) Types() []reflect.Type {
+	var types []reflect.Type
+	e.extractors.Range(func(key, _ any) bool {
+		types = append(types, key.(reflect.Type))
+		return true
+	})
+
+	slices.SortFunc(types, func(a, b reflect.Type) int {
+		return strings.Compare(a.String(), b.String())
+	})
+	return types
+}
+
+// RegisterExtractorIn registers f in e to be used for any
+// handler argument fields of type T.
+func RegisterExtractorIn[T any](e *ExtractorRegistry, f func(*http.Request) (T, error)) {
 	t := reflect.TypeFor[T]()
-	extractors.Store(t, fnExtractor[T]{f})
+	e.extractors.Store(t, fnExtractor[T]{f})
+	e.version.Add(1)
+}
+
+// extractors is the global registry, used when a handlers
+// registry does not contain an extractor for a type.
+var extractors = NewExtractorRegistry()
+
+// RegisterExtractor registers f to be used for any handler
+// argument fields of type T, replacing any extractor already
+// registered for T.
+func RegisterExtractor[T any](f func(*http.Request) (T, error)) {
+	RegisterExtractorIn(extractors, f)
+}
+
+// Register is an alias of [RegisterExtractor].
+func Register[T any](f func(*http.Request) (T, error)) {
+	RegisterExtractor(f)
+}
+
+// UnregisterExtractor removes the extractor registered for T.
+// Handlers already created will continue using the extractor.
+func UnregisterExtractor[T any]() {
+	extractors.Unregister(reflect.TypeFor[T]())
+}
+
+// RegisteredTypes returns all types with an extractor registered,
+// sorted by the types name.
+func RegisteredTypes() []reflect.Type {
+	return extractors.Types()
 }
 
 // Extractor is the interface implemented by an object that can
@@ -154,29 +623,53 @@ type Response struct {
 	Response any
 	// Error from the handler
 	Error error
-	Info  *route.Info
+	// Status is the http status code of the error, if it implements
+	// [StatusCoder]. Zero when the error does not provide one.
+	Status int
+	Info   *route.Info
 }
 
 type ResponseHandler func(http.ResponseWriter, *http.Request, Response)
 
 type HandlerParams struct {
-	Response         ResponseHandler
-	ErrorSink        func(error)
-	Parser           param.Parser
-	Namer            param.Namer
-	ParamPather      param.Pather
+	Response  ResponseHandler
+	ErrorSink func(error)
+	Parser    param.Parser
+	// ContextParser is tried before the Parser when set.
+	ContextParser param.ContextParser
+	Namer         param.Namer
+	// JSONNames names params after the json tag of their field, see [param.Config].
+	JSONNames bool
+	// FormTagKey names the fields of form bodies, see [param.Config].
+	FormTagKey  string
+	Joiner      param.Joiner
+	ParamPather param.Pather
+	// Extractors is checked before the global registry
+	// when looking up an extractor for a type.
+	Extractors       *ExtractorRegistry
 	Pattern          string
 	RouteInfo        *route.Info
 	CollectAllErrors bool
+	// RecoverPanics converts panics while extracting the inputs or
+	// calling the handler into a [PanicError] passed to Response.
+	RecoverPanics bool
+	// PoolArgs reuses the argument structs between requests, zeroing them
+	// before reuse. Extractors must not keep pointers into the struct
+	// after the request is handled.
+	PoolArgs bool
 }
 
 func Handler[T, R any](handler func(T) (R, error), params HandlerParams) http.HandlerFunc {
 	typ := reflect.TypeFor[T]()
-	extractInputs, err := extractorFor(typ, extractorForOpts{
+	extractInputs, err := cachedExtractorFor(typ, extractorForOpts{
 		Parser:           params.Parser,
+		ContextParser:    params.ContextParser,
 		Namer:            params.Namer,
+		JSONNames:        params.JSONNames,
+		FormTagKey:       params.FormTagKey,
+		Joiner:           params.Joiner,
 		Pather:           params.ParamPather,
-		RouteInfo:        params.RouteInfo,
+		Extractors:       params.Extractors,
 		CollectAllErrors: params.CollectAllErrors,
 	})
 
@@ -185,33 +678,163 @@ func Handler[T, R any](handler func(T) (R, error), params HandlerParams) http.Ha
 		return nil
 	}
 
+	newArgs, releaseArgs := argsAllocator[T](params.PoolArgs)
+
 	return func(w http.ResponseWriter, r *http.Request) {
 		var out R
-		var args T
+		args := newArgs()
 
-		err := extractInputs(w, r, unsafe.Pointer(&args))
-		if err == nil {
-			out, err = handler(args)
+		call := func() (err error) {
+			if params.RecoverPanics {
+				defer RecoverPanic(&err)
+			}
+
+			err = extractInputs(w, r, params.RouteInfo, unsafe.Pointer(args))
+			if err == nil {
+				out, err = handler(*args)
+			}
+			return err
+		}
+		err := call()
+
+		if direct, ok := any(out).(directResponse); ok && err == nil {
+			err = direct.write(w, r)
+
+			var written writtenError
+			if errors.As(err, &written) {
+				if params.ErrorSink != nil {
+					params.ErrorSink(written.error)
+				}
+				err = nil
+			}
+
+			if err == nil {
+				releaseArgs(args)
+				return
+			}
 		}
 
 		if f := params.Response; f != nil {
 			f(w, r, Response{
 				Response: out,
 				Error:    err,
+				Status:   statusCode(err),
 				Info:     params.RouteInfo,
 			})
 		}
+		releaseArgs(args)
+	}
+}
+
+// argsAllocator returns funcs to get and release a handlers argument struct.
+// When pooled, released structs are zeroed and reused by later requests.
+func argsAllocator[T any](pooled bool) (func() *T, func(*T)) {
+	if !pooled {
+		return func() *T { return new(T) }, func(*T) {}
 	}
+
+	pool := &sync.Pool{
+		New: func() any { return new(T) },
+	}
+
+	get := func() *T {
+		return pool.Get().(*T)
+	}
+	release := func(args *T) {
+		var zero T
+		*args = zero
+		pool.Put(args)
+	}
+	return get, release
 }
 
-type extractorFn func(http.ResponseWriter, *http.Request, unsafe.Pointer) error
+type extractorFn func(http.ResponseWriter, *http.Request, *route.Info, unsafe.Pointer) error
 
 type extractorForOpts struct {
 	Namer            param.Namer
+	JSONNames        bool
+	FormTagKey       string
+	Joiner           param.Joiner
 	Parser           param.Parser
+	ContextParser    param.ContextParser
 	Pather           param.Pather
-	RouteInfo        *route.Info
+	Extractors       *ExtractorRegistry
 	CollectAllErrors bool
+
+	// fields containing the current struct, innermost first
+	parents []reflect.StructField
+}
+
+// extractorCacheKey identifies the options an extractor was created with.
+// Funcs are compared by the address of their value, which is shared by
+// copies of the same func.
+type extractorCacheKey struct {
+	typ               reflect.Type
+	namer             unsafe.Pointer
+	jsonNames         bool
+	joiner            unsafe.Pointer
+	parser            unsafe.Pointer
+	contextParser     unsafe.Pointer
+	formTagKey        string
+	pather            param.Pather
+	extractors        *ExtractorRegistry
+	extractorsVersion uint64
+	globalVersion     uint64
+	collectAllErrors  bool
+}
+
+type cachedExtractor struct {
+	fn extractorFn
+	// keeps the funcs in the key alive so their addresses are not reused
+	opts extractorForOpts
+}
+
+// extractorCache holds the extractors created for handler argument types.
+var extractorCache sync.Map
+
+func funcAddr[F any](f F) unsafe.Pointer {
+	return *(*unsafe.Pointer)(unsafe.Pointer(&f))
+}
+
+// cacheable reports whether the extractor created with opts can be cached,
+// which requires the key to be comparable.
+func (opts extractorForOpts) cacheable() bool {
+	return opts.Pather == nil || reflect.TypeOf(opts.Pather).Comparable()
+}
+
+// cachedExtractorFor returns the extractor for argType, reusing the one
+// created for a previous handler with the same type and options.
+func cachedExtractorFor(argType reflect.Type, opts extractorForOpts) (extractorFn, error) {
+	if !opts.cacheable() {
+		return extractorFor(argType, opts)
+	}
+
+	key := extractorCacheKey{
+		typ:               argType,
+		namer:             funcAddr(opts.Namer),
+		jsonNames:         opts.JSONNames,
+		joiner:            funcAddr(opts.Joiner),
+		parser:            funcAddr(opts.Parser),
+		contextParser:     funcAddr(opts.ContextParser),
+		formTagKey:        opts.FormTagKey,
+		pather:            opts.Pather,
+		extractors:        opts.Extractors,
+		extractorsVersion: opts.Extractors.getVersion(),
+		globalVersion:     extractors.getVersion(),
+		collectAllErrors:  opts.CollectAllErrors,
+	}
+
+	if cached, has := extractorCache.Load(key); has {
+		return cached.(cachedExtractor).fn, nil
+	}
+
+	fn, err := extractorFor(argType, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	extractorCache.Store(key, cachedExtractor{fn: fn, opts: opts})
+	return fn, nil
 }
 
 func findRelatedExtractors(f reflect.StructField, opts extractorForOpts) []reflect.Type {
@@ -239,9 +862,11 @@ func extractorFor(argType reflect.Type, opts extractorForOpts) (extractorFn, err
 
 	numFields := argType.NumField()
 	fns := make([]extractorFn, numFields)
+	fields := make([]reflect.StructField, numFields)
 
 	for i := range fns {
 		f := argType.Field(i)
+		fields[i] = f
 		fn, err := extractorFromFieldWithRelated(f, opts)
 
 		var want *UnknownFieldTypeError
@@ -255,21 +880,96 @@ func extractorFor(argType reflect.Type, opts extractorForOpts) (extractorFn, err
 		fns[i] = fn
 	}
 
-	return func(w http.ResponseWriter, r *http.Request, argsPtr unsafe.Pointer) error {
+	// buffer the body ahead of the fields decoding it, otherwise
+	// they read it straight from the request
+	bufferBody := len(opts.parents) == 0 && hasRawBody(argType)
+
+	return func(w http.ResponseWriter, r *http.Request, info *route.Info, argsPtr unsafe.Pointer) error {
+		if bufferBody {
+			if _, err := GetAndSetBody(r); err != nil {
+				return err
+			}
+		}
+
 		var allErrors []error
-		for _, fn := range fns {
-			if err := fn(w, r, argsPtr); err != nil {
+		for i, fn := range fns {
+			if err := fn(w, r, info, argsPtr); err != nil {
 				if !opts.CollectAllErrors {
 					return err
 				}
 
-				allErrors = append(allErrors, err)
+				allErrors = appendFieldErrors(allErrors, fields[i], opts, err)
 			}
 		}
 		return errors.Join(allErrors...)
 	}, nil
 }
 
+// hasRawBody reports whether typ, or a struct nested in it, has a [RawBody] field.
+func hasRawBody(typ reflect.Type) bool {
+	rawBody := reflect.TypeFor[RawBody]()
+	for i := range typ.NumField() {
+		f := typ.Field(i).Type
+		if f == rawBody || f == reflect.PointerTo(rawBody) {
+			return true
+		}
+
+		if f.Kind() == reflect.Struct && hasRawBody(f) {
+			return true
+		}
+	}
+	return false
+}
+
+// appendFieldErrors wraps err in a [FieldError] for the field. Errors from
+// nested structs already contain field errors and are flattened instead.
+func appendFieldErrors(
+	errs []error,
+	field reflect.StructField,
+	opts extractorForOpts,
+	err error,
+) []error {
+	var fieldErr *FieldError
+	if errors.As(err, &fieldErr) {
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			return append(errs, joined.Unwrap()...)
+		}
+		return append(errs, err)
+	}
+
+	name, source := fieldNameAndSource(field, opts)
+	return append(errs, &FieldError{
+		Name:   name,
+		Source: source,
+		Err:    err,
+	})
+}
+
+func fieldNameAndSource(field reflect.StructField, opts extractorForOpts) (string, string) {
+	type sourcer interface {
+		Source() string
+	}
+
+	var source string
+	if s, ok := reflect.New(field.Type).Interface().(sourcer); ok {
+		source = s.Source()
+	}
+
+	namer := opts.Namer
+	if namer == nil {
+		namer = func(name, _ string) string { return name }
+	}
+
+	config := param.Config{
+		Namer:     namer,
+		JSONNames: opts.JSONNames,
+		Joiner:    opts.Joiner,
+	}
+	name := config.FieldName(field, source)
+	name = param.NestedName(name, opts.parents, config, source)
+	return name, source
+}
+
 func fieldValue(field reflect.StructField, ptr unsafe.Pointer) reflect.Value {
 	fieldPtr := unsafe.Add(ptr, field.Offset)
 	return reflect.NewAt(field.Type, fieldPtr)
@@ -284,9 +984,9 @@ func extractExtractor(field reflect.StructField, opts extractorForOpts) extracto
 		return nil
 	}
 
-	return func(_ http.ResponseWriter, r *http.Request, argBasePtr unsafe.Pointer) error {
+	return func(_ http.ResponseWriter, r *http.Request, info *route.Info, argBasePtr unsafe.Pointer) error {
 		field := fieldValue(field, argBasePtr).Interface()
-		return field.(Extractor).Extract(r, opts.RouteInfo)
+		return field.(Extractor).Extract(r, info)
 	}
 }
 
@@ -295,16 +995,31 @@ func extractParamExtractor(field reflect.StructField, opts extractorForOpts) ext
 		return nil
 	}
 
-	source := reflect.New(field.Type).Interface().(ParamExtractor).Source()
-	name := param.NameFromField(field, opts.Namer, source)
+	name, _ := fieldNameAndSource(field, opts)
+	defaultValue := field.Tag.Get("default")
+	// invalid values are reported by param.InfoFromStruct
+	required, _ := param.RequiredFromField(field)
+	enum := param.EnumFromField(field)
+	separator := param.SeparatorFromField(field)
+	minLength, maxLength, _ := param.LengthFromField(field)
+	parser := param.ParserForField(field, opts.Parser)
 
-	return func(_ http.ResponseWriter, r *http.Request, argBasePtr unsafe.Pointer) error {
+	return func(_ http.ResponseWriter, r *http.Request, info *route.Info, argBasePtr unsafe.Pointer) error {
 		field := fieldValue(field, argBasePtr).Interface()
-		return field.(ParamExtractor).Extract(r, opts.RouteInfo, param.Opts{
-			Name:    name,
-			Default: "",
-			Pather:  opts.Pather,
-			Parser:  opts.Parser,
+		return field.(ParamExtractor).Extract(r, info, param.Opts{
+			Name:      name,
+			Default:   defaultValue,
+			Required:  required,
+			Pather:    opts.Pather,
+			Parser:    parser,
+			Enum:      enum,
+			Separator: separator,
+			MinLength: minLength,
+			MaxLength: maxLength,
+
+			FormTagKey:    opts.FormTagKey,
+			ContextParser: opts.ContextParser,
+			Context:       r.Context(),
 		})
 	}
 }
@@ -314,7 +1029,7 @@ func extractHTTPRequest(field reflect.StructField, _ extractorForOpts) extractor
 		return nil
 	}
 
-	return func(_ http.ResponseWriter, r *http.Request, argBasePtr unsafe.Pointer) error {
+	return func(_ http.ResponseWriter, r *http.Request, _ *route.Info, argBasePtr unsafe.Pointer) error {
 		field := fieldValue(field, argBasePtr).Interface()
 		req := field.(**http.Request)
 		*req = r
@@ -328,7 +1043,7 @@ func extractHTTPResponse(field reflect.StructField, _ extractorForOpts) extracto
 		return nil
 	}
 
-	return func(w http.ResponseWriter, _ *http.Request, argBasePtr unsafe.Pointer) error {
+	return func(w http.ResponseWriter, _ *http.Request, _ *route.Info, argBasePtr unsafe.Pointer) error {
 		field := fieldValue(field, argBasePtr).Interface()
 		resp := field.(*http.ResponseWriter)
 		*resp = w
@@ -338,13 +1053,18 @@ func extractHTTPResponse(field reflect.StructField, _ extractorForOpts) extracto
 
 var ErrExtactType = errors.New("could not extract type")
 
-func extractFromExtractors(field reflect.StructField, _ extractorForOpts) extractorFn {
+func extractFromExtractors(field reflect.StructField, opts extractorForOpts) extractorFn {
 	type typeExtractor interface {
 		ExtractType(*http.Request) (any, error)
 	}
 
-	if extractor, has := extractors.Load(field.Type); has {
-		return func(_ http.ResponseWriter, r *http.Request, argBasePtr unsafe.Pointer) error {
+	extractor, has := opts.Extractors.load(field.Type)
+	if !has {
+		extractor, has = extractors.load(field.Type)
+	}
+
+	if has {
+		return func(_ http.ResponseWriter, r *http.Request, _ *route.Info, argBasePtr unsafe.Pointer) error {
 			field := fieldValue(field, argBasePtr)
 			t, err := extractor.(typeExtractor).ExtractType(r)
 
@@ -361,23 +1081,62 @@ func extractFromExtractors(field reflect.StructField, _ extractorForOpts) extrac
 	return nil
 }
 
+func isExtractorType(typ reflect.Type) bool {
+	ptr := reflect.PointerTo(typ)
+	return ptr.Implements(extType) || ptr.Implements(paramExtType)
+}
+
+// extractFromStructPointerOfExtractors allocates the struct pointed to by
+// the field, setting the field after all of its extractors succeed.
+func extractFromStructPointerOfExtractors(
+	field reflect.StructField,
+	opts extractorForOpts,
+) (extractorFn, error) {
+	typ := field.Type.Elem()
+	if typ.Kind() != reflect.Struct || isExtractorType(typ) {
+		//nolint:nilnil
+		return nil, nil
+	}
+
+	opts.parents = append([]reflect.StructField{field}, opts.parents...)
+	fn, err := extractorFor(typ, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, info *route.Info, argsPtr unsafe.Pointer) error {
+		value := reflect.New(typ)
+		if err := fn(w, r, info, value.UnsafePointer()); err != nil {
+			return err
+		}
+
+		fieldValue(field, argsPtr).Elem().Set(value)
+		return nil
+	}, nil
+}
+
 func extractFromStructOfExtractors(
 	field reflect.StructField,
 	opts extractorForOpts,
 ) (extractorFn, error) {
+	if field.Type.Kind() == reflect.Pointer {
+		return extractFromStructPointerOfExtractors(field, opts)
+	}
+
 	if field.Type.Kind() != reflect.Struct {
 		//nolint:nilnil
 		return nil, nil
 	}
 
+	opts.parents = append([]reflect.StructField{field}, opts.parents...)
 	fn, err := extractorFor(field.Type, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	return func(w http.ResponseWriter, r *http.Request, argsPtr unsafe.Pointer) error {
+	return func(w http.ResponseWriter, r *http.Request, info *route.Info, argsPtr unsafe.Pointer) error {
 		fieldPtr := unsafe.Add(argsPtr, field.Offset)
-		return fn(w, r, fieldPtr)
+		return fn(w, r, info, fieldPtr)
 	}, nil
 }
 
@@ -395,12 +1154,21 @@ func extractorFromFieldWithRelated(
 	return fn, err
 }
 
+// skipField is the extractor of fields set by the handler, see [param.SkipFromField].
+func skipField(http.ResponseWriter, *http.Request, *route.Info, unsafe.Pointer) error {
+	return nil
+}
+
 func extractorFromField(
 	field reflect.StructField,
 	opts extractorForOpts,
 ) (extractorFn, error) {
 	type fn func(reflect.StructField, extractorForOpts) extractorFn
 
+	if param.SkipFromField(field) {
+		return skipField, nil
+	}
+
 	// TODO: allow extractor to specify help
 	fns := []fn{
 		extractHTTPRequest,
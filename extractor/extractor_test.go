@@ -7,9 +7,12 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/zhamlin/routey"
 	"github.com/zhamlin/routey/extractor"
@@ -64,6 +67,126 @@ func TestJSONExtractor_InvalidJSON(t *testing.T) {
 	test.WantError(t, err, &want)
 }
 
+type validatedBody struct {
+	Name string `json:"name"`
+}
+
+func (b validatedBody) Validate() error {
+	if b.Name == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+func TestJSONExtractor_Validate(t *testing.T) {
+	r := newRequest(t, http.MethodPost, "/", strings.NewReader(`{"name": "a"}`))
+	got := routey.JSON[validatedBody]{}
+	test.NoError(t, got.Extract(r, nil))
+
+	r = newRequest(t, http.MethodPost, "/", strings.NewReader(`{"name": ""}`))
+	err := got.Extract(r, nil)
+	test.IsError(t, err, extractor.ErrValidate)
+
+	var want extractor.StatusCoder
+	test.WantError(t, err, &want)
+	test.Equal(t, want.StatusCode(), http.StatusBadRequest)
+}
+
+func TestJSONExtractor_ValidateCollectAllErrors(t *testing.T) {
+	type input struct {
+		Int  routey.Query[int]
+		Body routey.JSON[validatedBody]
+	}
+	h := func(input) (any, error) { return nil, nil }
+
+	r := routey.New()
+	r.Errors.CollectAll = true
+
+	var got []string
+	r.Response = func(_ http.ResponseWriter, _ *http.Request, resp extractor.Response) {
+		var joined interface {
+			Unwrap() []error
+		}
+		test.WantError(t, resp.Error, &joined)
+
+		for _, err := range joined.Unwrap() {
+			var fieldErr *extractor.FieldError
+			test.WantError(t, err, &fieldErr)
+			got = append(got, fieldErr.Name)
+		}
+	}
+
+	routey.Post(r, "/", h)
+	req := newRequest(t, http.MethodPost, "/?int=a", strings.NewReader(`{"name": ""}`))
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	test.MatchAsJSON(t, got, []string{"int", "body"})
+}
+
+func TestXMLExtractor_ValidXML(t *testing.T) {
+	type Body struct {
+		ID   int    `xml:"id,attr"`
+		Name string `xml:"name"`
+	}
+	r := newRequest(t, http.MethodPost, "/", strings.NewReader(`<user id="1"><name>a</name></user>`))
+
+	got := routey.XML[Body]{}
+	err := got.Extract(r, nil)
+	test.NoError(t, err)
+	test.Equal(t, got.V, Body{ID: 1, Name: "a"})
+}
+
+func TestXMLExtractor_InvalidXML(t *testing.T) {
+	r := newRequest(t, http.MethodPost, "/", strings.NewReader(`<user>`))
+	val := routey.XML[struct{}]{}
+	err := val.Extract(r, nil)
+
+	test.IsError(t, err, extractor.ErrXMLDecode)
+
+	var coder extractor.StatusCoder
+	test.WantError(t, err, &coder)
+	test.Equal(t, coder.StatusCode(), http.StatusBadRequest)
+}
+
+func TestXMLExtractor_EmptyBody(t *testing.T) {
+	r := newRequest(t, http.MethodPost, "/", nil)
+	val := routey.XML[struct{ Name string }]{}
+	test.NoError(t, val.Extract(r, nil))
+}
+
+func TestFormExtractor_UsesFormTags(t *testing.T) {
+	type Body struct {
+		Name    string `form:"user_name"`
+		Age     int    `form:"age"`
+		Ignored string `form:"-"`
+	}
+	body := strings.NewReader("user_name=bob&age=2&Ignored=value")
+	r := newRequest(t, http.MethodPost, "/", body)
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	got := routey.Form[Body]{}
+	err := got.Extract(r, &route.Info{}, param.Opts{
+		Parser: param.Parsers{param.ParseInt, param.ParseString}.Parse,
+	})
+	test.NoError(t, err)
+
+	test.Equal(t, got.V, Body{Name: "bob", Age: 2})
+}
+
+func TestFormExtractor_ErrorParsing(t *testing.T) {
+	type Body struct {
+		Age int `form:"age"`
+	}
+	r := newRequest(t, http.MethodPost, "/", strings.NewReader("age=two"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	got := routey.Form[Body]{}
+	err := got.Extract(r, &route.Info{}, param.Opts{
+		Parser: param.ParseInt,
+	})
+	test.IsError(t, err, extractor.ErrFormDecode)
+}
+
 func TestQueryExtractor_ValidValue(t *testing.T) {
 	r := newRequest(t, http.MethodPost, "/?query=1", nil)
 	got := routey.Query[int]{}
@@ -117,6 +240,52 @@ func TestQueryExtractor_MissingParam(t *testing.T) {
 	test.Equal(t, got.Value, want)
 }
 
+func TestQueryExtractor_MissingRequiredParam(t *testing.T) {
+	r := newRequest(t, http.MethodPost, "/", nil)
+	got := routey.Query[int]{}
+	err := got.Extract(r, &route.Info{}, param.Opts{
+		Name:     "query",
+		Required: true,
+		Parser:   param.ParseInt,
+	})
+
+	test.IsError(t, err, extractor.ErrParamRequired)
+
+	var want *param.MissingRequiredError
+	test.WantError(t, err, &want)
+	test.Equal(t, want.Name, "query")
+	test.Equal(t, want.Source, "query")
+}
+
+func TestQueryExtractor_RequiredParamPresent(t *testing.T) {
+	r := newRequest(t, http.MethodPost, "/?query=1", nil)
+	got := routey.Query[int]{}
+	err := got.Extract(r, &route.Info{}, param.Opts{
+		Name:     "query",
+		Required: true,
+		Parser:   param.ParseInt,
+	})
+	test.NoError(t, err)
+
+	want := 1
+	test.Equal(t, got.Value, want)
+}
+
+func TestQueryExtractor_RequiredParamWithDefault(t *testing.T) {
+	r := newRequest(t, http.MethodPost, "/", nil)
+	got := routey.Query[int]{}
+	err := got.Extract(r, &route.Info{}, param.Opts{
+		Name:     "query",
+		Default:  "1",
+		Required: true,
+		Parser:   param.ParseInt,
+	})
+	test.NoError(t, err)
+
+	want := 1
+	test.Equal(t, got.Value, want)
+}
+
 type testPather struct {
 	value string
 }
@@ -180,6 +349,40 @@ func TestHandler_ValidExtractor(t *testing.T) {
 	test.Equal(t, got, want)
 }
 
+type upperParam string
+
+func (u *upperParam) ParseParam(params []string) error {
+	*u = upperParam(strings.ToUpper(params[0]))
+	return nil
+}
+
+func TestHandler_FieldParser(t *testing.T) {
+	type Input struct {
+		Value routey.Query[upperParam]
+	}
+
+	var got upperParam
+	fn := func(i Input) (any, error) {
+		got = i.Value.Value
+		return nil, nil
+	}
+
+	params := extractor.HandlerParams{
+		ErrorSink: func(err error) {
+			test.NoError(t, err)
+		},
+		// the parser cannot parse the type, so the fields method must be used
+		Parser:    param.ParseInt,
+		Namer:     func(string, string) string { return "value" },
+		RouteInfo: &route.Info{},
+	}
+	handler := extractor.Handler(fn, params)
+
+	r := newRequest(t, http.MethodGet, "/?value=abc", nil)
+	handler(httptest.NewRecorder(), r)
+	test.Equal(t, got, upperParam("ABC"))
+}
+
 func TestHandler_ExtractHttpRequest(t *testing.T) {
 	type Input struct{ r *http.Request }
 	fn := func(i Input) (any, error) {
@@ -284,6 +487,32 @@ func TestHandler_ErrorNoExtractorForField(t *testing.T) {
 	extractor.Handler(fn, params)
 }
 
+func TestHandler_SkipField(t *testing.T) {
+	type Input struct {
+		Value    routey.Query[int]
+		Computed map[string]int `param:"-"`
+	}
+
+	var got Input
+	params := extractor.HandlerParams{
+		Parser:    param.ParseInt,
+		Namer:     param.NamerCapitals,
+		ErrorSink: func(err error) { test.NoError(t, err) },
+	}
+
+	fn := func(in Input) (any, error) {
+		got = in
+		return nil, nil
+	}
+	h := extractor.Handler(fn, params)
+
+	r := newRequest(t, http.MethodGet, "/?value=1", nil)
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	test.Equal(t, got.Value.Value, 1)
+	test.Equal(t, len(got.Computed), 0)
+}
+
 func TestHandler_ErrorNonStruct(t *testing.T) {
 	params := extractor.HandlerParams{ErrorSink: func(err error) {
 		test.IsError(t, err, param.ErrNonStructArg)
@@ -292,3 +521,330 @@ func TestHandler_ErrorNonStruct(t *testing.T) {
 	fn := func(int) (any, error) { return nil, nil }
 	extractor.Handler(fn, params)
 }
+
+type registeredType struct {
+	Value string
+}
+
+func TestRegisterExtractor(t *testing.T) {
+	extractor.RegisterExtractor(func(r *http.Request) (registeredType, error) {
+		return registeredType{Value: r.URL.Path}, nil
+	})
+	t.Cleanup(extractor.UnregisterExtractor[registeredType])
+
+	type Input struct{ Value registeredType }
+
+	var got string
+	fn := func(i Input) (any, error) {
+		got = i.Value.Value
+		return nil, nil
+	}
+
+	params := extractor.HandlerParams{
+		ErrorSink: func(err error) {
+			test.NoError(t, err)
+		},
+	}
+	h := extractor.Handler(fn, params)
+
+	want := "/path"
+	h.ServeHTTP(httptest.NewRecorder(), newRequest(t, http.MethodGet, want, nil))
+	test.Equal(t, got, want)
+}
+
+func TestRegisterExtractor_Overrides(t *testing.T) {
+	extractor.RegisterExtractor(func(*http.Request) (registeredType, error) {
+		return registeredType{Value: "first"}, nil
+	})
+	extractor.RegisterExtractor(func(*http.Request) (registeredType, error) {
+		return registeredType{Value: "second"}, nil
+	})
+	t.Cleanup(extractor.UnregisterExtractor[registeredType])
+
+	var got string
+	fn := func(i struct{ Value registeredType }) (any, error) {
+		got = i.Value.Value
+		return nil, nil
+	}
+
+	params := extractor.HandlerParams{
+		ErrorSink: func(err error) {
+			test.NoError(t, err)
+		},
+	}
+	h := extractor.Handler(fn, params)
+
+	h.ServeHTTP(httptest.NewRecorder(), newRequest(t, http.MethodGet, "/", nil))
+	test.Equal(t, got, "second")
+}
+
+func TestRegisteredTypes(t *testing.T) {
+	typ := reflect.TypeFor[registeredType]()
+	extractor.Register(func(*http.Request) (registeredType, error) {
+		return registeredType{}, nil
+	})
+
+	if !slices.Contains(extractor.RegisteredTypes(), typ) {
+		t.Errorf("expected %v to be registered", typ)
+	}
+
+	extractor.UnregisterExtractor[registeredType]()
+	if slices.Contains(extractor.RegisteredTypes(), typ) {
+		t.Errorf("expected %v to be unregistered", typ)
+	}
+
+	var want *extractor.UnknownFieldTypeError
+	params := extractor.HandlerParams{ErrorSink: expectErrSink(t, &want)}
+
+	fn := func(struct{ Value registeredType }) (any, error) { return nil, nil }
+	extractor.Handler(fn, params)
+}
+
+func TestRawBodyExtractor_ReadsBody(t *testing.T) {
+	want := `{"value": 1}`
+	r := newRequest(t, http.MethodPost, "/", strings.NewReader(want))
+
+	got := routey.RawBody{}
+	err := got.Extract(r, nil)
+	test.NoError(t, err)
+	test.Equal(t, string(got.V), want)
+
+	// the body can still be read after extracting
+	body, err := io.ReadAll(r.Body)
+	test.NoError(t, err)
+	test.Equal(t, string(body), want)
+}
+
+func TestHandler_RawBodyAfterJSON(t *testing.T) {
+	type Input struct {
+		Body routey.JSON[struct{ Value int }]
+		Raw  routey.RawBody
+	}
+
+	var got Input
+	fn := func(i Input) (any, error) {
+		got = i
+		return nil, nil
+	}
+
+	params := extractor.HandlerParams{
+		ErrorSink: func(err error) {
+			test.NoError(t, err)
+		},
+	}
+
+	want := `{"value": 1}`
+	r := newRequest(t, http.MethodPost, "/", strings.NewReader(want))
+	extractor.Handler(fn, params).ServeHTTP(httptest.NewRecorder(), r)
+
+	test.Equal(t, got.Body.V.Value, 1)
+	test.Equal(t, string(got.Raw.V), want)
+}
+
+func TestHandler_JSONWithoutRawBodyIsNotBuffered(t *testing.T) {
+	type Input struct {
+		Body routey.JSON[struct{ Value int }]
+		Req  *http.Request
+	}
+
+	var got Input
+	var rest []byte
+	fn := func(i Input) (any, error) {
+		got = i
+		rest, _ = io.ReadAll(i.Req.Body)
+		return nil, nil
+	}
+
+	params := extractor.HandlerParams{
+		ErrorSink: func(err error) {
+			test.NoError(t, err)
+		},
+	}
+
+	r := newRequest(t, http.MethodPost, "/", strings.NewReader(`{"value": 1}`))
+	extractor.Handler(fn, params).ServeHTTP(httptest.NewRecorder(), r)
+
+	test.Equal(t, got.Body.V.Value, 1)
+	test.Equal(t, len(rest), 0)
+}
+
+func TestExtractorRegistry_OverridesGlobal(t *testing.T) {
+	extractor.RegisterExtractor(func(*http.Request) (registeredType, error) {
+		return registeredType{Value: "global"}, nil
+	})
+	t.Cleanup(extractor.UnregisterExtractor[registeredType])
+
+	registry := extractor.NewExtractorRegistry()
+	extractor.RegisterExtractorIn(registry, func(*http.Request) (registeredType, error) {
+		return registeredType{Value: "registry"}, nil
+	})
+
+	var got string
+	fn := func(i struct{ Value registeredType }) (any, error) {
+		got = i.Value.Value
+		return nil, nil
+	}
+
+	params := extractor.HandlerParams{
+		ErrorSink: func(err error) {
+			test.NoError(t, err)
+		},
+		Extractors: registry,
+	}
+
+	req := newRequest(t, http.MethodGet, "/", nil)
+	extractor.Handler(fn, params).ServeHTTP(httptest.NewRecorder(), req)
+	test.Equal(t, got, "registry")
+
+	// the global registry is used when the type is not found
+	registry.Reset()
+	test.Equal(t, len(registry.Types()), 0)
+
+	extractor.Handler(fn, params).ServeHTTP(httptest.NewRecorder(), req)
+	test.Equal(t, got, "global")
+}
+
+type routeName struct {
+	Value string
+}
+
+func (n *routeName) Extract(_ *http.Request, info *route.Info) error {
+	n.Value = info.Name
+	return nil
+}
+
+func TestHandler_SameTypeUsesRouteInfo(t *testing.T) {
+	type Input struct {
+		Name routeName
+	}
+	fn := func(i Input) (string, error) {
+		return i.Name.Value, nil
+	}
+
+	newHandler := func(name string) http.HandlerFunc {
+		return extractor.Handler(fn, extractor.HandlerParams{
+			Response: func(w http.ResponseWriter, _ *http.Request, resp extractor.Response) {
+				_, _ = fmt.Fprintf(w, "%v", resp.Response)
+			},
+			RouteInfo: &route.Info{Name: name},
+		})
+	}
+
+	for _, name := range []string{"first", "second"} {
+		w := httptest.NewRecorder()
+		newHandler(name)(w, newRequest(t, http.MethodGet, "/", nil))
+		test.Equal(t, w.Body.String(), name)
+	}
+}
+
+func TestHandler_RegisterAfterHandlerCreated(t *testing.T) {
+	registry := extractor.NewExtractorRegistry()
+	extractor.RegisterExtractorIn(registry, func(*http.Request) (registeredType, error) {
+		return registeredType{Value: "first"}, nil
+	})
+
+	var got string
+	fn := func(i struct{ Value registeredType }) (any, error) {
+		got = i.Value.Value
+		return nil, nil
+	}
+
+	params := extractor.HandlerParams{
+		ErrorSink: func(err error) {
+			test.NoError(t, err)
+		},
+		Extractors: registry,
+	}
+	first := extractor.Handler(fn, params)
+
+	extractor.RegisterExtractorIn(registry, func(*http.Request) (registeredType, error) {
+		return registeredType{Value: "second"}, nil
+	})
+	second := extractor.Handler(fn, params)
+
+	req := newRequest(t, http.MethodGet, "/", nil)
+	first.ServeHTTP(httptest.NewRecorder(), req)
+	test.Equal(t, got, "first")
+
+	second.ServeHTTP(httptest.NewRecorder(), req)
+	test.Equal(t, got, "second")
+}
+
+func TestHandler_PoolArgsZeroed(t *testing.T) {
+	type Input struct {
+		Value routey.Query[int]
+	}
+
+	var got []int
+	fn := func(i Input) (any, error) {
+		got = append(got, i.Value.Value)
+		return nil, nil
+	}
+
+	h := extractor.Handler(fn, extractor.HandlerParams{
+		ErrorSink: func(err error) {
+			test.NoError(t, err)
+		},
+		Parser:   param.ParseInt,
+		PoolArgs: true,
+	})
+
+	for _, url := range []string{"/?Value=1", "/", "/?Value=2"} {
+		h(httptest.NewRecorder(), newRequest(t, http.MethodGet, url, nil))
+	}
+	test.MatchAsJSON(t, got, []int{1, 0, 2})
+}
+
+func TestHandler_SameTypeUsesEachParser(t *testing.T) {
+	type Input struct {
+		Value routey.Query[string] `name:"value"`
+	}
+
+	var got string
+	fn := func(i Input) (any, error) {
+		got = i.Value.Value
+		return nil, nil
+	}
+
+	newHandler := func(prefix string) http.HandlerFunc {
+		config := param.Config{TimeLayouts: []string{time.DateOnly}}
+		config.Parser = func(dst any, values []string) error {
+			*dst.(*string) = prefix + values[0]
+			return nil
+		}
+		return extractor.Handler(fn, extractor.HandlerParams{
+			ErrorSink: func(err error) {
+				test.NoError(t, err)
+			},
+			Parser: config.GetParser(),
+		})
+	}
+
+	for _, prefix := range []string{"first-", "second-"} {
+		newHandler(prefix)(httptest.NewRecorder(), newRequest(t, http.MethodGet, "/?value=1", nil))
+		test.Equal(t, got, prefix+"1")
+	}
+}
+
+func TestHandler_SameOptionsCached(t *testing.T) {
+	type Input struct {
+		Value routey.Query[string]
+	}
+	fn := func(Input) (any, error) { return nil, nil }
+
+	var named int
+	params := extractor.HandlerParams{
+		ErrorSink: func(err error) {
+			test.NoError(t, err)
+		},
+		Parser: param.NewParser(param.DefaultParsers()...),
+		Namer: func(name, _ string) string {
+			named++
+			return name
+		},
+	}
+
+	extractor.Handler(fn, params)
+	extractor.Handler(fn, params)
+	test.Equal(t, named, 1)
+}
@@ -0,0 +1,123 @@
+package extractor
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ByteRange is a range of bytes requested by a Range header,
+// from Start up to and including End.
+type ByteRange struct {
+	Start int64
+	End   int64
+}
+
+// Length returns the number of bytes in the range.
+func (b ByteRange) Length() int64 {
+	return b.End - b.Start + 1
+}
+
+var ErrInvalidRange = errors.New("invalid range header")
+
+// RangeNotSatisfiableError is returned when none of the requested
+// ranges overlap the content, which has the Size.
+type RangeNotSatisfiableError struct {
+	Size int64
+}
+
+func (e RangeNotSatisfiableError) Error() string {
+	return fmt.Sprintf("range not satisfiable for content of %d bytes", e.Size)
+}
+
+// StatusCode responds with a 416, as the range is outside the content.
+func (e RangeNotSatisfiableError) StatusCode() int {
+	return http.StatusRequestedRangeNotSatisfiable
+}
+
+// ParseRange parses the byte ranges of a Range header for content of the size.
+// Ranges ending past the content are shortened to its end, and suffix ranges
+// such as bytes=-500 select its last bytes. Malformed headers return
+// ErrInvalidRange, and a [RangeNotSatisfiableError] is returned when none of
+// the ranges overlap the content.
+func ParseRange(header string, size int64) ([]ByteRange, error) {
+	spec, ok := strings.CutPrefix(header, "bytes=")
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidRange, header)
+	}
+
+	var ranges []ByteRange
+	for part := range strings.SplitSeq(spec, ",") {
+		r, satisfiable, err := parseByteRange(strings.TrimSpace(part), size)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidRange, header)
+		}
+
+		if satisfiable {
+			ranges = append(ranges, r)
+		}
+	}
+
+	if len(ranges) == 0 {
+		return nil, RangeNotSatisfiableError{Size: size}
+	}
+	return ranges, nil
+}
+
+// parseByteRange parses a single range, returning
+// whether it overlaps content of the size.
+func parseByteRange(part string, size int64) (ByteRange, bool, error) {
+	first, last, ok := strings.Cut(part, "-")
+	if !ok {
+		return ByteRange{}, false, ErrInvalidRange
+	}
+	first, last = strings.TrimSpace(first), strings.TrimSpace(last)
+
+	if first == "" {
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || n < 0 {
+			return ByteRange{}, false, ErrInvalidRange
+		}
+		return ByteRange{Start: max(size-n, 0), End: size - 1}, n > 0 && size > 0, nil
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 {
+		return ByteRange{}, false, ErrInvalidRange
+	}
+
+	end := size - 1
+	if last != "" {
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || n < start {
+			return ByteRange{}, false, ErrInvalidRange
+		}
+		end = min(n, end)
+	}
+	return ByteRange{Start: start, End: end}, start < size, nil
+}
+
+// RangeContent is returned by handlers to respond with the bytes of Content
+// using [http.ServeContent], which serves the ranges requested by a Range
+// header with a 206 Partial Content and responds to unsatisfiable or invalid
+// ranges with a 416. Conditional requests are handled when ModTime is set.
+type RangeContent struct {
+	// Type is the Content-Type header of the response.
+	Type    string
+	Content io.ReadSeeker
+	// ModTime is the Last-Modified time of the content.
+	ModTime time.Time
+}
+
+func (c RangeContent) write(w http.ResponseWriter, r *http.Request) error {
+	if c.Type != "" {
+		w.Header().Set("Content-Type", c.Type)
+	}
+
+	http.ServeContent(w, r, "", c.ModTime, c.Content)
+	return nil
+}
@@ -0,0 +1,160 @@
+package extractor_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zhamlin/routey"
+	"github.com/zhamlin/routey/extractor"
+	"github.com/zhamlin/routey/internal/test"
+)
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		header string
+		want   []extractor.ByteRange
+	}{
+		{header: "bytes=0-4", want: []extractor.ByteRange{{Start: 0, End: 4}}},
+		{header: "bytes=5-", want: []extractor.ByteRange{{Start: 5, End: 9}}},
+		{header: "bytes=-3", want: []extractor.ByteRange{{Start: 7, End: 9}}},
+		{header: "bytes=8-20", want: []extractor.ByteRange{{Start: 8, End: 9}}},
+		{header: "bytes=0-1, 4-5", want: []extractor.ByteRange{{Start: 0, End: 1}, {Start: 4, End: 5}}},
+		// unsatisfiable ranges are skipped when others overlap the content
+		{header: "bytes=20-30,0-0", want: []extractor.ByteRange{{Start: 0, End: 0}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			got, err := extractor.ParseRange(tt.header, 10)
+			test.NoError(t, err)
+			test.MatchAsJSON(t, got, tt.want)
+		})
+	}
+}
+
+func TestParseRange_Errors(t *testing.T) {
+	for _, header := range []string{"items=0-1", "bytes=a-1", "bytes=5-1", "bytes=1"} {
+		_, err := extractor.ParseRange(header, 10)
+		test.IsError(t, err, extractor.ErrInvalidRange)
+	}
+
+	_, err := extractor.ParseRange("bytes=10-", 10)
+	var want extractor.RangeNotSatisfiableError
+	test.WantError(t, err, &want)
+	test.Equal(t, want.Size, 10)
+}
+
+func newRangeHandler(t *testing.T, gotErr *error) http.Handler {
+	t.Helper()
+
+	params := extractor.HandlerParams{
+		Response: func(w http.ResponseWriter, _ *http.Request, resp extractor.Response) {
+			*gotErr = resp.Error
+			w.WriteHeader(resp.Status)
+		},
+	}
+
+	fn := func(struct{}) (routey.RangeContent, error) {
+		return routey.RangeContent{
+			Type:    "text/plain",
+			Content: strings.NewReader("0123456789"),
+		}, nil
+	}
+	return extractor.Handler(fn, params)
+}
+
+func TestRangeContent_SingleRange(t *testing.T) {
+	var gotErr error
+	h := newRangeHandler(t, &gotErr)
+
+	req := newRequest(t, http.MethodGet, "/", nil)
+	req.Header.Set("Range", "bytes=2-5")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	test.NoError(t, gotErr)
+	test.Equal(t, w.Code, http.StatusPartialContent)
+	test.Equal(t, w.Header().Get("Content-Range"), "bytes 2-5/10")
+	test.Equal(t, w.Header().Get("Content-Length"), "4")
+	test.Equal(t, w.Header().Get("Content-Type"), "text/plain")
+	test.Equal(t, w.Body.String(), "2345")
+}
+
+func TestRangeContent_NoRange(t *testing.T) {
+	var gotErr error
+	h := newRangeHandler(t, &gotErr)
+
+	req := newRequest(t, http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	test.NoError(t, gotErr)
+	test.Equal(t, w.Code, http.StatusOK)
+	test.Equal(t, w.Header().Get("Accept-Ranges"), "bytes")
+	test.Equal(t, w.Header().Get("Content-Range"), "")
+	test.Equal(t, w.Header().Get("Content-Type"), "text/plain")
+	test.Equal(t, w.Body.String(), "0123456789")
+}
+
+func TestRangeContent_MultipleRanges(t *testing.T) {
+	var gotErr error
+	h := newRangeHandler(t, &gotErr)
+
+	req := newRequest(t, http.MethodGet, "/", nil)
+	req.Header.Set("Range", "bytes=0-1,4-5")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	test.NoError(t, gotErr)
+	test.Equal(t, w.Code, http.StatusPartialContent)
+	test.Equal(t, strings.HasPrefix(w.Header().Get("Content-Type"), "multipart/byteranges"), true)
+}
+
+func TestRangeContent_NotSatisfiable(t *testing.T) {
+	var gotErr error
+	h := newRangeHandler(t, &gotErr)
+
+	req := newRequest(t, http.MethodGet, "/", nil)
+	req.Header.Set("Range", "bytes=20-")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	test.NoError(t, gotErr)
+	test.Equal(t, w.Code, http.StatusRequestedRangeNotSatisfiable)
+	test.Equal(t, w.Header().Get("Content-Range"), "bytes */10")
+}
+
+func TestRangeContent_InvalidRange(t *testing.T) {
+	var gotErr error
+	h := newRangeHandler(t, &gotErr)
+
+	req := newRequest(t, http.MethodGet, "/", nil)
+	req.Header.Set("Range", "invalid")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	test.NoError(t, gotErr)
+	test.Equal(t, w.Code, http.StatusRequestedRangeNotSatisfiable)
+}
+
+func TestRangeContent_NotModified(t *testing.T) {
+	modTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	fn := func(struct{}) (routey.RangeContent, error) {
+		return routey.RangeContent{
+			Content: strings.NewReader("0123456789"),
+			ModTime: modTime,
+		}, nil
+	}
+	h := extractor.Handler(fn, extractor.HandlerParams{})
+
+	req := newRequest(t, http.MethodGet, "/", nil)
+	req.Header.Set("If-Modified-Since", modTime.Format(http.TimeFormat))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	test.Equal(t, w.Code, http.StatusNotModified)
+	test.Equal(t, w.Body.Len(), 0)
+}
@@ -0,0 +1,376 @@
+package extractor
+
+import (
+	"cmp"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"reflect"
+	"runtime/debug"
+	"strconv"
+	"time"
+)
+
+// StatusCoder is implemented by errors that know the http status code
+// to respond with, see [Response.Status].
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// statusCode returns the status code of the first error in the tree
+// of err implementing [StatusCoder], or zero if there are none.
+func statusCode(err error) int {
+	var s StatusCoder
+	if errors.As(err, &s) {
+		return s.StatusCode()
+	}
+	return 0
+}
+
+// statusCodeError adds a status code to an error.
+type statusCodeError struct {
+	err    error
+	status int
+}
+
+func (s statusCodeError) Error() string   { return s.err.Error() }
+func (s statusCodeError) Unwrap() error   { return s.err }
+func (s statusCodeError) StatusCode() int { return s.status }
+
+// WithStatusCode wraps err to respond with the status code, unless
+// err already contains an error implementing [StatusCoder].
+func WithStatusCode(err error, status int) error {
+	if err == nil || statusCode(err) != 0 {
+		return err
+	}
+	return statusCodeError{err: err, status: status}
+}
+
+var _ error = TooManyRequests{}
+
+// TooManyRequests can be returned from a handler, as either the response
+// or the error, to respond with a 429 and the Retry-After header set.
+type TooManyRequests struct {
+	// RetryAfter is how long the client should wait before retrying,
+	// rounded up to the nearest second. The header is not set when zero.
+	RetryAfter time.Duration
+}
+
+func (t TooManyRequests) Error() string {
+	if t.RetryAfter <= 0 {
+		return http.StatusText(http.StatusTooManyRequests)
+	}
+	return fmt.Sprintf("%s: retry after %s", http.StatusText(http.StatusTooManyRequests), t.RetryAfter)
+}
+
+// StatusCode implements the [StatusCoder] interface.
+func (TooManyRequests) StatusCode() int {
+	return http.StatusTooManyRequests
+}
+
+// ServeHTTP implements the [http.Handler] interface.
+func (t TooManyRequests) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	if t.RetryAfter > 0 {
+		seconds := int(math.Ceil(t.RetryAfter.Seconds()))
+		w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	}
+
+	code := http.StatusTooManyRequests
+	http.Error(w, http.StatusText(code), code)
+}
+
+var _ error = StatusError{}
+
+// StatusError can be returned from a handler to respond with its status code
+// and a json body containing the status, message and any details.
+type StatusError struct {
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+	// Details are any additional information about the error,
+	// such as the fields that failed validation.
+	Details any `json:"details,omitempty"`
+}
+
+// MarshalXML encodes the error as an error element containing the status
+// and message. Details are not included, as they may not be xml encodable.
+func (e StatusError) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	type statusError struct {
+		Status  int    `xml:"status"`
+		Message string `xml:"message"`
+	}
+
+	start.Name = xml.Name{Local: "error"}
+	return enc.EncodeElement(statusError{Status: e.Status, Message: e.Message}, start)
+}
+
+func newStatusError(status int, msg string) StatusError {
+	if msg == "" {
+		msg = http.StatusText(status)
+	}
+	return StatusError{Status: status, Message: msg}
+}
+
+// BadRequest returns a [StatusError] responding with a 400 and the details.
+func BadRequest(details any) StatusError {
+	err := newStatusError(http.StatusBadRequest, "")
+	err.Details = details
+	return err
+}
+
+// Unauthorized returns a [StatusError] responding with a 401.
+func Unauthorized(msg string) StatusError {
+	return newStatusError(http.StatusUnauthorized, msg)
+}
+
+// Forbidden returns a [StatusError] responding with a 403.
+func Forbidden(msg string) StatusError {
+	return newStatusError(http.StatusForbidden, msg)
+}
+
+// NotFound returns a [StatusError] responding with a 404.
+func NotFound(msg string) StatusError {
+	return newStatusError(http.StatusNotFound, msg)
+}
+
+// Conflict returns a [StatusError] responding with a 409.
+func Conflict(msg string) StatusError {
+	return newStatusError(http.StatusConflict, msg)
+}
+
+// RequestHeaderFieldsTooLarge returns a [StatusError] responding with a 431.
+func RequestHeaderFieldsTooLarge(msg string) StatusError {
+	return newStatusError(http.StatusRequestHeaderFieldsTooLarge, msg)
+}
+
+// InternalServerError returns a [StatusError] responding with a 500.
+func InternalServerError(msg string) StatusError {
+	return newStatusError(http.StatusInternalServerError, msg)
+}
+
+func (s StatusError) Error() string {
+	text := http.StatusText(s.Status)
+	if s.Message == "" || s.Message == text {
+		return text
+	}
+	return fmt.Sprintf("%s: %s", text, s.Message)
+}
+
+// StatusCode implements the [StatusCoder] interface.
+func (s StatusError) StatusCode() int {
+	return s.Status
+}
+
+// ServeHTTP implements the [http.Handler] interface.
+func (s StatusError) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(s.Status)
+	_ = json.NewEncoder(w).Encode(s)
+}
+
+var _ error = PanicError{}
+
+// PanicError is passed to the [ResponseHandler] when a handler panics
+// and recovering panics is enabled. It responds with a 500 when served.
+type PanicError struct {
+	// Value passed to panic.
+	Value any
+	// Stack trace of the goroutine that panicked.
+	Stack []byte
+}
+
+func (p PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", p.Value)
+}
+
+// Unwrap returns the panic value if it is an error.
+func (p PanicError) Unwrap() error {
+	if err, ok := p.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// StatusCode implements the [StatusCoder] interface.
+func (PanicError) StatusCode() int {
+	return http.StatusInternalServerError
+}
+
+// ServeHTTP implements the [http.Handler] interface.
+func (p PanicError) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	code := http.StatusInternalServerError
+	http.Error(w, http.StatusText(code), code)
+}
+
+// RecoverPanic converts a panic into a [PanicError] stored in err when
+// deferred. Panics with [http.ErrAbortHandler] are not recovered.
+func RecoverPanic(err *error) {
+	v := recover()
+	if v == nil {
+		return
+	}
+
+	if v == http.ErrAbortHandler {
+		panic(v)
+	}
+
+	*err = PanicError{
+		Value: v,
+		Stack: debug.Stack(),
+	}
+}
+
+// WithHTTPHandlers returns a [ResponseHandler] that serves any response or
+// error implementing [http.Handler], such as [TooManyRequests], calling
+// next for all other responses.
+func WithHTTPHandlers(next ResponseHandler) ResponseHandler {
+	return func(w http.ResponseWriter, r *http.Request, resp Response) {
+		var h http.Handler
+		if errors.As(resp.Error, &h) {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		if h, ok := resp.Response.(http.Handler); ok && resp.Error == nil {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		if next != nil {
+			next(w, r, resp)
+		}
+	}
+}
+
+// JSONResponseOptions configures the [ResponseHandler] returned by [JSONResponse].
+type JSONResponseOptions struct {
+	// Indent pretty prints the json using the indent for each level,
+	// such as during development. The json is compact when empty.
+	Indent string
+	// ErrorStatus returns the status code for errors without a [Response.Status].
+	// Errors respond with a 500 when nil or when it returns zero.
+	ErrorStatus func(error) int
+}
+
+// JSONResponse returns a [ResponseHandler] writing the response as json with
+// the status code from [Response.Status], or a 200 when zero. A 204 is written
+// for nil responses.
+//
+// Errors are written as a [StatusError]. The message of errors responding
+// with a 5xx is the status text, so internal details are not exposed. Errors
+// parsing or validating the request, such as invalid params, respond with a
+// 400 and their message.
+func JSONResponse(opts JSONResponseOptions) ResponseHandler {
+	return encodedResponse(opts, func(w http.ResponseWriter, status int, value any) {
+		writeJSON(w, status, value, opts.Indent)
+	})
+}
+
+// XMLResponseOptions configures the [ResponseHandler] returned by [XMLResponse].
+type XMLResponseOptions struct {
+	// Indent pretty prints the xml using the indent for each level,
+	// such as during development. The xml is compact when empty.
+	Indent string
+	// ErrorStatus returns the status code for errors without a [Response.Status].
+	// Errors respond with a 500 when nil or when it returns zero.
+	ErrorStatus func(error) int
+}
+
+// XMLResponse returns a [ResponseHandler] writing the response as xml, using
+// the same status codes and errors as [JSONResponse].
+func XMLResponse(opts XMLResponseOptions) ResponseHandler {
+	jsonOpts := JSONResponseOptions{ErrorStatus: opts.ErrorStatus}
+	return encodedResponse(jsonOpts, func(w http.ResponseWriter, status int, value any) {
+		writeXML(w, status, value, opts.Indent)
+	})
+}
+
+// encodedResponse returns a [ResponseHandler] calling write with the status
+// and value to encode for each response.
+func encodedResponse(opts JSONResponseOptions, write func(http.ResponseWriter, int, any)) ResponseHandler {
+	return func(w http.ResponseWriter, _ *http.Request, resp Response) {
+		if resp.Error != nil {
+			status := opts.errorStatus(resp)
+			write(w, status, jsonError(resp.Error, status))
+			return
+		}
+
+		if isNil(resp.Response) {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		write(w, cmp.Or(resp.Status, http.StatusOK), resp.Response)
+	}
+}
+
+func (o JSONResponseOptions) errorStatus(resp Response) int {
+	if resp.Status != 0 {
+		return resp.Status
+	}
+
+	if o.ErrorStatus != nil {
+		if status := o.ErrorStatus(resp.Error); status != 0 {
+			return status
+		}
+	}
+	return http.StatusInternalServerError
+}
+
+// jsonError returns the error as a [StatusError] with the status.
+func jsonError(err error, status int) StatusError {
+	var statusErr StatusError
+	if errors.As(err, &statusErr) && statusErr.Status == status {
+		return statusErr
+	}
+
+	if status >= http.StatusInternalServerError {
+		return newStatusError(status, "")
+	}
+	return newStatusError(status, err.Error())
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any, indent string) {
+	marshal := json.Marshal
+	if indent != "" {
+		marshal = func(v any) ([]byte, error) {
+			return json.MarshalIndent(v, "", indent)
+		}
+	}
+
+	b, err := marshal(value)
+	if err != nil {
+		code := http.StatusInternalServerError
+		http.Error(w, http.StatusText(code), code)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(b)
+}
+
+func writeXML(w http.ResponseWriter, status int, value any, indent string) {
+	b, err := xml.MarshalIndent(value, "", indent)
+	if err != nil {
+		code := http.StatusInternalServerError
+		http.Error(w, http.StatusText(code), code)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	_, _ = w.Write(b)
+}
+
+// isNil returns true if v is nil or a nil pointer.
+func isNil(v any) bool {
+	if v == nil {
+		return true
+	}
+
+	val := reflect.ValueOf(v)
+	return val.Kind() == reflect.Pointer && val.IsNil()
+}
@@ -0,0 +1,454 @@
+package extractor_test
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zhamlin/routey/extractor"
+	"github.com/zhamlin/routey/internal/test"
+	"github.com/zhamlin/routey/param"
+)
+
+func TestTooManyRequests_SetsRetryAfter(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := newRequest(t, http.MethodGet, "/", nil)
+
+	extractor.TooManyRequests{RetryAfter: 1500 * time.Millisecond}.ServeHTTP(w, r)
+
+	test.Equal(t, w.Code, http.StatusTooManyRequests)
+	test.Equal(t, w.Header().Get("Retry-After"), "2")
+}
+
+func TestWithHTTPHandlers_ServesError(t *testing.T) {
+	type input struct{}
+	h := func(input) (any, error) {
+		return nil, extractor.TooManyRequests{RetryAfter: time.Second}
+	}
+
+	called := false
+	handler := extractor.Handler(h, extractor.HandlerParams{
+		Response: extractor.WithHTTPHandlers(
+			func(http.ResponseWriter, *http.Request, extractor.Response) {
+				called = true
+			},
+		),
+	})
+
+	w := httptest.NewRecorder()
+	handler(w, newRequest(t, http.MethodGet, "/", nil))
+
+	test.Equal(t, w.Code, http.StatusTooManyRequests)
+	test.Equal(t, w.Header().Get("Retry-After"), "1")
+	test.Equal(t, called, false)
+}
+
+func TestWithHTTPHandlers_ServesResponse(t *testing.T) {
+	type input struct{}
+	h := func(input) (extractor.TooManyRequests, error) {
+		return extractor.TooManyRequests{}, nil
+	}
+
+	handler := extractor.Handler(h, extractor.HandlerParams{
+		Response: extractor.WithHTTPHandlers(nil),
+	})
+
+	w := httptest.NewRecorder()
+	handler(w, newRequest(t, http.MethodGet, "/", nil))
+
+	test.Equal(t, w.Code, http.StatusTooManyRequests)
+	test.Equal(t, w.Header().Get("Retry-After"), "")
+}
+
+func TestWithHTTPHandlers_CallsNext(t *testing.T) {
+	type input struct{}
+	h := func(input) (any, error) { return "ok", nil }
+
+	var got any
+	handler := extractor.Handler(h, extractor.HandlerParams{
+		Response: extractor.WithHTTPHandlers(
+			func(_ http.ResponseWriter, _ *http.Request, resp extractor.Response) {
+				got = resp.Response
+			},
+		),
+	})
+
+	handler(httptest.NewRecorder(), newRequest(t, http.MethodGet, "/", nil))
+	test.Equal(t, got, any("ok"))
+}
+
+func TestHandler_RecoverPanics(t *testing.T) {
+	type input struct{}
+	errPanic := errors.New("panic error")
+	h := func(input) (any, error) {
+		panic(errPanic)
+	}
+
+	var gotErr error
+	handler := extractor.Handler(h, extractor.HandlerParams{
+		RecoverPanics: true,
+		Response: extractor.WithHTTPHandlers(
+			func(_ http.ResponseWriter, _ *http.Request, resp extractor.Response) {
+				gotErr = resp.Error
+			},
+		),
+	})
+
+	w := httptest.NewRecorder()
+	handler(w, newRequest(t, http.MethodGet, "/", nil))
+
+	test.Equal(t, w.Code, http.StatusInternalServerError)
+	test.Equal(t, gotErr, nil)
+}
+
+func TestHandler_RecoverPanicsError(t *testing.T) {
+	type input struct{}
+	errPanic := errors.New("panic error")
+	h := func(input) (any, error) {
+		panic(errPanic)
+	}
+
+	var gotErr error
+	handler := extractor.Handler(h, extractor.HandlerParams{
+		RecoverPanics: true,
+		Response: func(_ http.ResponseWriter, _ *http.Request, resp extractor.Response) {
+			gotErr = resp.Error
+		},
+	})
+
+	handler(httptest.NewRecorder(), newRequest(t, http.MethodGet, "/", nil))
+
+	var panicErr extractor.PanicError
+	test.WantError(t, gotErr, &panicErr)
+	test.IsError(t, gotErr, errPanic)
+	test.Equal(t, len(panicErr.Stack) > 0, true)
+}
+
+func TestHandler_RecoverPanicsAbortHandler(t *testing.T) {
+	type input struct{}
+	h := func(input) (any, error) {
+		panic(http.ErrAbortHandler)
+	}
+
+	handler := extractor.Handler(h, extractor.HandlerParams{
+		RecoverPanics: true,
+	})
+
+	defer func() {
+		test.Equal(t, recover(), any(http.ErrAbortHandler))
+	}()
+	handler(httptest.NewRecorder(), newRequest(t, http.MethodGet, "/", nil))
+	t.Error("expected the handler to panic")
+}
+
+func TestStatusError_Responses(t *testing.T) {
+	tests := []struct {
+		err      extractor.StatusError
+		wantCode int
+		wantBody string
+	}{
+		{
+			err:      extractor.BadRequest(map[string]string{"name": "required"}),
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"status": 400, "message": "Bad Request", "details": {"name": "required"}}`,
+		},
+		{
+			err:      extractor.Unauthorized(""),
+			wantCode: http.StatusUnauthorized,
+			wantBody: `{"status": 401, "message": "Unauthorized"}`,
+		},
+		{
+			err:      extractor.Forbidden("admins only"),
+			wantCode: http.StatusForbidden,
+			wantBody: `{"status": 403, "message": "admins only"}`,
+		},
+		{
+			err:      extractor.NotFound("user not found"),
+			wantCode: http.StatusNotFound,
+			wantBody: `{"status": 404, "message": "user not found"}`,
+		},
+		{
+			err:      extractor.Conflict("user already exists"),
+			wantCode: http.StatusConflict,
+			wantBody: `{"status": 409, "message": "user already exists"}`,
+		},
+		{
+			err:      extractor.InternalServerError(""),
+			wantCode: http.StatusInternalServerError,
+			wantBody: `{"status": 500, "message": "Internal Server Error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.wantCode), func(t *testing.T) {
+			type input struct{}
+			h := func(input) (any, error) { return nil, tt.err }
+
+			handler := extractor.Handler(h, extractor.HandlerParams{
+				Response: extractor.WithHTTPHandlers(nil),
+			})
+
+			w := httptest.NewRecorder()
+			handler(w, newRequest(t, http.MethodGet, "/", nil))
+
+			test.Equal(t, w.Code, tt.wantCode)
+			test.Equal(t, w.Header().Get("Content-Type"), "application/json")
+			test.MatchAsJSON(t, w.Body.String(), tt.wantBody)
+		})
+	}
+}
+
+func TestStatusError_Error(t *testing.T) {
+	test.Equal(t, extractor.NotFound("").Error(), "Not Found")
+	test.Equal(t, extractor.NotFound("user 1").Error(), "Not Found: user 1")
+}
+
+func TestHandler_ResponseStatus(t *testing.T) {
+	type body struct{ Name string }
+
+	tests := []struct {
+		name   string
+		fn     func(struct{ Body extractor.JSON[body] }) (any, error)
+		body   string
+		status int
+	}{
+		{
+			name:   "json decode error",
+			fn:     func(struct{ Body extractor.JSON[body] }) (any, error) { return nil, nil },
+			body:   `{"name":`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "wrapped status error",
+			fn: func(struct{ Body extractor.JSON[body] }) (any, error) {
+				return nil, fmt.Errorf("finding user: %w", extractor.NotFound(""))
+			},
+			body:   `{}`,
+			status: http.StatusNotFound,
+		},
+		{
+			name: "error without status",
+			fn: func(struct{ Body extractor.JSON[body] }) (any, error) {
+				return nil, errors.New("error")
+			},
+			body:   `{}`,
+			status: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got extractor.Response
+			handler := extractor.Handler(tt.fn, extractor.HandlerParams{
+				Response: func(_ http.ResponseWriter, _ *http.Request, resp extractor.Response) {
+					got = resp
+				},
+			})
+
+			r := newRequest(t, http.MethodPost, "/", strings.NewReader(tt.body))
+			handler(httptest.NewRecorder(), r)
+
+			test.Equal(t, got.Status, tt.status)
+		})
+	}
+}
+
+func TestHandler_ResponseStatusJSONDecodeIs(t *testing.T) {
+	type input struct{ Body extractor.JSON[struct{}] }
+	h := func(input) (any, error) { return nil, nil }
+
+	var got error
+	handler := extractor.Handler(h, extractor.HandlerParams{
+		Response: func(_ http.ResponseWriter, _ *http.Request, resp extractor.Response) {
+			got = resp.Error
+		},
+	})
+
+	handler(httptest.NewRecorder(), newRequest(t, http.MethodPost, "/", strings.NewReader("{")))
+	test.IsError(t, got, extractor.ErrJSONDecode)
+}
+
+func TestJSONResponse(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+	}
+	var nilUser *user
+
+	tests := []struct {
+		name   string
+		opts   extractor.JSONResponseOptions
+		resp   extractor.Response
+		status int
+		body   string
+	}{
+		{
+			name:   "response",
+			resp:   extractor.Response{Response: user{Name: "a"}},
+			status: http.StatusOK,
+			body:   `{"name":"a"}`,
+		},
+		{
+			name:   "response status",
+			resp:   extractor.Response{Response: user{Name: "a"}, Status: http.StatusCreated},
+			status: http.StatusCreated,
+			body:   `{"name":"a"}`,
+		},
+		{
+			name:   "pretty print",
+			opts:   extractor.JSONResponseOptions{Indent: "  "},
+			resp:   extractor.Response{Response: user{Name: "a"}},
+			status: http.StatusOK,
+			body:   "{\n  \"name\": \"a\"\n}",
+		},
+		{
+			name:   "nil response",
+			resp:   extractor.Response{},
+			status: http.StatusNoContent,
+		},
+		{
+			name:   "nil pointer response",
+			resp:   extractor.Response{Response: nilUser},
+			status: http.StatusNoContent,
+		},
+		{
+			name: "status error",
+			resp: extractor.Response{
+				Error:  fmt.Errorf("finding user: %w", extractor.NotFound("no user")),
+				Status: http.StatusNotFound,
+			},
+			status: http.StatusNotFound,
+			body:   `{"status":404,"message":"no user"}`,
+		},
+		{
+			name:   "error without status",
+			resp:   extractor.Response{Error: errors.New("database password is wrong")},
+			status: http.StatusInternalServerError,
+			body:   `{"status":500,"message":"Internal Server Error"}`,
+		},
+		{
+			name: "error status mapping",
+			opts: extractor.JSONResponseOptions{
+				ErrorStatus: func(err error) int {
+					if errors.Is(err, errNotAllowed) {
+						return http.StatusForbidden
+					}
+					return 0
+				},
+			},
+			resp:   extractor.Response{Error: errNotAllowed},
+			status: http.StatusForbidden,
+			body:   `{"status":403,"message":"not allowed"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := newRequest(t, http.MethodGet, "/", nil)
+			extractor.JSONResponse(tt.opts)(w, r, tt.resp)
+
+			test.Equal(t, w.Code, tt.status)
+			test.Equal(t, w.Body.String(), tt.body)
+			if tt.body != "" {
+				test.Equal(t, w.Header().Get("Content-Type"), "application/json")
+			}
+		})
+	}
+}
+
+func TestJSONResponse_InvalidParam(t *testing.T) {
+	type input struct {
+		ID extractor.Query[int] `name:"id"`
+	}
+
+	handler := extractor.Handler(func(input) (any, error) { return nil, nil }, extractor.HandlerParams{
+		Response: extractor.JSONResponse(extractor.JSONResponseOptions{}),
+		Parser:   param.ParseInt,
+		ErrorSink: func(err error) {
+			test.NoError(t, err)
+		},
+	})
+
+	w := httptest.NewRecorder()
+	handler(w, newRequest(t, http.MethodGet, "/?id=abc", nil))
+
+	test.Equal(t, w.Code, http.StatusBadRequest)
+	var got extractor.StatusError
+	test.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	test.Equal(t, got.Status, http.StatusBadRequest)
+	if !strings.Contains(got.Message, extractor.ErrParamFailedToExtract.Error()) {
+		t.Errorf("wanted the param error as the message, got: %q", got.Message)
+	}
+}
+
+func TestXMLResponse(t *testing.T) {
+	type user struct {
+		XMLName xml.Name `xml:"user"`
+		ID      int      `xml:"id,attr"`
+		Name    string   `xml:"name"`
+	}
+
+	tests := []struct {
+		name   string
+		opts   extractor.XMLResponseOptions
+		resp   extractor.Response
+		status int
+		body   string
+	}{
+		{
+			name:   "response",
+			resp:   extractor.Response{Response: user{ID: 1, Name: "a"}},
+			status: http.StatusOK,
+			body:   `<user id="1"><name>a</name></user>`,
+		},
+		{
+			name:   "pretty print",
+			opts:   extractor.XMLResponseOptions{Indent: "  "},
+			resp:   extractor.Response{Response: user{ID: 1, Name: "a"}},
+			status: http.StatusOK,
+			body:   "<user id=\"1\">\n  <name>a</name>\n</user>",
+		},
+		{
+			name:   "nil response",
+			resp:   extractor.Response{},
+			status: http.StatusNoContent,
+		},
+		{
+			name: "status error",
+			resp: extractor.Response{
+				Error:  extractor.BadRequest(map[string]string{"name": "required"}),
+				Status: http.StatusBadRequest,
+			},
+			status: http.StatusBadRequest,
+			body:   `<error><status>400</status><message>Bad Request</message></error>`,
+		},
+		{
+			name:   "error without status",
+			resp:   extractor.Response{Error: errors.New("database password is wrong")},
+			status: http.StatusInternalServerError,
+			body:   `<error><status>500</status><message>Internal Server Error</message></error>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := newRequest(t, http.MethodGet, "/", nil)
+			extractor.XMLResponse(tt.opts)(w, r, tt.resp)
+
+			test.Equal(t, w.Code, tt.status)
+			test.Equal(t, w.Body.String(), tt.body)
+			if tt.body != "" {
+				test.Equal(t, w.Header().Get("Content-Type"), "application/xml")
+			}
+		})
+	}
+}
+
+var errNotAllowed = errors.New("not allowed")
@@ -0,0 +1,115 @@
+package extractor
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrInvalidEvent is returned when the ID or Event of an [Event]
+// contains a newline, which would start another field of the event.
+var ErrInvalidEvent = errors.New("event id and type cannot contain newlines")
+
+// Event is a server-sent event, see [SSE].
+type Event struct {
+	// ID sets the last event id of the client, sent back when reconnecting.
+	ID string
+	// Event is the type of the event, message when empty.
+	Event string
+	// Data of the event. Strings and byte slices are sent as is,
+	// any other value is encoded as json.
+	Data any
+	// Retry sets how long the client waits before reconnecting.
+	Retry time.Duration
+}
+
+func (e Event) marshal() ([]byte, error) {
+	if strings.ContainsAny(e.ID, "\r\n") {
+		return nil, fmt.Errorf("%w: id %q", ErrInvalidEvent, e.ID)
+	}
+	if strings.ContainsAny(e.Event, "\r\n") {
+		return nil, fmt.Errorf("%w: event %q", ErrInvalidEvent, e.Event)
+	}
+
+	var data string
+	switch v := e.Data.(type) {
+	case string:
+		data = v
+	case []byte:
+		data = string(v)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("error encoding event data: %w", err)
+		}
+		data = string(b)
+	}
+	// clients end lines at a CR as well, which must not start a new field
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+	data = strings.ReplaceAll(data, "\r", "\n")
+
+	var b bytes.Buffer
+	if e.ID != "" {
+		fmt.Fprintf(&b, "id: %s\n", e.ID)
+	}
+	if e.Event != "" {
+		fmt.Fprintf(&b, "event: %s\n", e.Event)
+	}
+	if e.Retry > 0 {
+		fmt.Fprintf(&b, "retry: %d\n", e.Retry.Milliseconds())
+	}
+	for line := range strings.SplitSeq(data, "\n") {
+		fmt.Fprintf(&b, "data: %s\n", line)
+	}
+	b.WriteString("\n")
+	return b.Bytes(), nil
+}
+
+// SSE is returned by handlers to stream the events received from Events as
+// server-sent events, instead of being passed to the [ResponseHandler]. Each
+// event is flushed once written. The stream ends when Events is closed, an
+// event cannot be encoded, or the client disconnects, so handlers sending
+// events should stop once the context of the request is done. Errors encoding
+// an event are passed to the ErrorSink of the [HandlerParams].
+type SSE struct {
+	Events <-chan Event
+}
+
+func (s SSE) write(w http.ResponseWriter, r *http.Request) error {
+	rc := http.NewResponseController(w)
+
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	// disables buffering by proxies such as nginx
+	h.Set("X-Accel-Buffering", "no")
+
+	w.WriteHeader(http.StatusOK)
+	_ = rc.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return nil
+		case event, ok := <-s.Events:
+			if !ok {
+				return nil
+			}
+
+			b, err := event.marshal()
+			if err != nil {
+				return writtenError{err}
+			}
+
+			if _, err := w.Write(b); err != nil {
+				return nil
+			}
+			_ = rc.Flush()
+		}
+	}
+}
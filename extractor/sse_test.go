@@ -0,0 +1,103 @@
+package extractor_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/zhamlin/routey"
+	"github.com/zhamlin/routey/extractor"
+	"github.com/zhamlin/routey/internal/test"
+)
+
+func TestSSE_Events(t *testing.T) {
+	params := extractor.HandlerParams{
+		Response: func(http.ResponseWriter, *http.Request, extractor.Response) {
+			t.Fatal("expected the events to be written without the response handler")
+		},
+	}
+
+	fn := func(struct{}) (routey.SSE, error) {
+		events := make(chan extractor.Event, 2)
+		events <- extractor.Event{ID: "1", Event: "greeting", Data: "hello\nworld"}
+		events <- extractor.Event{Data: map[string]int{"n": 2}, Retry: time.Second}
+		close(events)
+		return routey.SSE{Events: events}, nil
+	}
+	h := extractor.Handler(fn, params)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, newRequest(t, http.MethodGet, "/", nil))
+
+	test.Equal(t, w.Code, http.StatusOK)
+	test.Equal(t, w.Header().Get("Content-Type"), "text/event-stream")
+	test.Equal(t, w.Flushed, true)
+
+	want := "id: 1\nevent: greeting\ndata: hello\ndata: world\n\n" +
+		"retry: 1000\ndata: {\"n\":2}\n\n"
+	test.Equal(t, w.Body.String(), want)
+}
+
+func TestSSE_ClientDisconnect(t *testing.T) {
+	fn := func(struct{}) (routey.SSE, error) {
+		// never sends or closes, the stream ends when the client disconnects
+		return routey.SSE{Events: make(chan extractor.Event)}, nil
+	}
+	h := extractor.Handler(fn, extractor.HandlerParams{})
+
+	ctx, cancel := context.WithCancel(t.Context())
+	r := httptest.NewRequestWithContext(ctx, http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+
+	done := make(chan struct{})
+	go func() {
+		h.ServeHTTP(w, r)
+		close(done)
+	}()
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected the stream to end once the request was canceled")
+	}
+}
+
+func TestSSE_InvalidEvent(t *testing.T) {
+	var gotErr error
+	params := extractor.HandlerParams{
+		ErrorSink: func(err error) { gotErr = err },
+	}
+
+	fn := func(struct{}) (routey.SSE, error) {
+		events := make(chan extractor.Event, 2)
+		events <- extractor.Event{ID: "1\nevent: admin", Data: "hello"}
+		events <- extractor.Event{Data: "unreachable"}
+		close(events)
+		return routey.SSE{Events: events}, nil
+	}
+	h := extractor.Handler(fn, params)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, newRequest(t, http.MethodGet, "/", nil))
+
+	test.IsError(t, gotErr, extractor.ErrInvalidEvent)
+	test.Equal(t, w.Body.String(), "")
+}
+
+func TestSSE_DataCarriageReturns(t *testing.T) {
+	fn := func(struct{}) (routey.SSE, error) {
+		events := make(chan extractor.Event, 1)
+		events <- extractor.Event{Data: "a\rid: 2\r\nb"}
+		close(events)
+		return routey.SSE{Events: events}, nil
+	}
+	h := extractor.Handler(fn, extractor.HandlerParams{})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, newRequest(t, http.MethodGet, "/", nil))
+
+	test.Equal(t, w.Body.String(), "data: a\ndata: id: 2\ndata: b\n\n")
+}
@@ -0,0 +1,59 @@
+package routey
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/zhamlin/routey/extractor"
+)
+
+// HeaderLimits configures the requests rejected by [LimitHeaders].
+type HeaderLimits struct {
+	// MaxCount is the maximum number of header values, with each value
+	// of a repeated header counted. No limit when zero.
+	MaxCount int
+	// MaxBytes is the maximum total size of the header names and values.
+	// No limit when zero.
+	MaxBytes int
+}
+
+// check returns an error and false when the header exceeds the limits.
+func (l HeaderLimits) check(header http.Header) (extractor.StatusError, bool) {
+	count, size := 0, 0
+	for name, values := range header {
+		count += len(values)
+		for _, v := range values {
+			size += len(name) + len(v)
+		}
+	}
+
+	switch {
+	case l.MaxCount > 0 && count > l.MaxCount:
+		msg := fmt.Sprintf("%d headers exceeds the limit of %d", count, l.MaxCount)
+		return extractor.RequestHeaderFieldsTooLarge(msg), false
+	case l.MaxBytes > 0 && size > l.MaxBytes:
+		msg := fmt.Sprintf("%d header bytes exceeds the limit of %d", size, l.MaxBytes)
+		return extractor.RequestHeaderFieldsTooLarge(msg), false
+	}
+	return extractor.StatusError{}, true
+}
+
+// LimitHeaders returns a middleware rejecting requests with headers exceeding
+// the limits, passing an [extractor.StatusError] with a 431 to the Response
+// handler of r. The error is written directly when r has no Response handler.
+//
+// Limits lower than the [http.Server] MaxHeaderBytes can be set per router,
+// as the server rejects larger requests before they reach the router.
+func LimitHeaders(r *Router, limits HeaderLimits) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			err, ok := limits.check(req.Header)
+			if ok {
+				next.ServeHTTP(w, req)
+				return
+			}
+
+			respondError(r, w, req, err, err.StatusCode())
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package routey_test
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/zhamlin/routey"
+	"github.com/zhamlin/routey/extractor"
+	"github.com/zhamlin/routey/internal/test"
+)
+
+func TestLimitHeaders_TooMany(t *testing.T) {
+	r := newTestRouter(t)
+	r.Use(routey.LimitHeaders(r, routey.HeaderLimits{MaxCount: 2}))
+
+	var gotResp extractor.Response
+	r.Response = func(w http.ResponseWriter, _ *http.Request, resp extractor.Response) {
+		gotResp = resp
+		http.Error(w, "", resp.Status)
+	}
+	r.Get("/", func(http.ResponseWriter, *http.Request) {})
+
+	req := newRequest(t, http.MethodGet, "/", nil)
+	for i := range 3 {
+		req.Header.Add("X-Value", strconv.Itoa(i))
+	}
+	compareRespStatus(t, r, req, http.StatusRequestHeaderFieldsTooLarge)
+
+	var want extractor.StatusError
+	test.WantError(t, gotResp.Error, &want)
+	test.Equal(t, want.Message, "3 headers exceeds the limit of 2")
+	test.Equal(t, gotResp.Status, http.StatusRequestHeaderFieldsTooLarge)
+}
+
+func TestLimitHeaders_TooLarge(t *testing.T) {
+	r := newTestRouter(t)
+	r.Response = nil
+	r.Use(routey.LimitHeaders(r, routey.HeaderLimits{MaxBytes: 64}))
+	r.Get("/", func(http.ResponseWriter, *http.Request) {})
+
+	req := newRequest(t, http.MethodGet, "/", nil)
+	req.Header.Set("X-Value", strings.Repeat("a", 64))
+	compareRespStatus(t, r, req, http.StatusRequestHeaderFieldsTooLarge)
+}
+
+func TestLimitHeaders_WithinLimits(t *testing.T) {
+	r := newTestRouter(t)
+	r.Use(routey.LimitHeaders(r, routey.HeaderLimits{MaxCount: 2, MaxBytes: 64}))
+	r.Get("/", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := newRequest(t, http.MethodGet, "/", nil)
+	req.Header.Set("X-Value", "a")
+	compareRespStatus(t, r, req, http.StatusOK)
+}
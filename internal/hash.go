@@ -0,0 +1,33 @@
+package internal
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// HashJSON returns the hex encoded sha256 hash of the canonical json encoding
+// of v, which has sorted object keys and no insignificant whitespace, so equal
+// values hash equal regardless of how their marshallers order fields.
+func HashJSON(v any) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+
+	var decoded any
+	if err := d.Decode(&decoded); err != nil {
+		return "", err
+	}
+
+	if b, err = json.Marshal(decoded); err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -199,3 +199,19 @@ func writeFieldError(
 	fmt.Fprintf(sb, "%s%s|%s\n", spacing, colors.Error, colors.Reset)
 	fmt.Fprintf(sb, "%s%s%s%s\n", spacing, colors.Error, err.Error, colors.Reset)
 }
+
+// TagName returns the name of the field from the struct tag with the
+// provided key, using the fields name when the tag has no name.
+// An empty string is returned if the tag is "-".
+func TagName(f reflect.StructField, key string) string {
+	tag := f.Tag.Get(key)
+	if tag == "-" {
+		return ""
+	}
+
+	name := strings.Split(tag, ",")[0]
+	if name == "" {
+		name = f.Name
+	}
+	return name
+}
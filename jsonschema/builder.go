@@ -103,6 +103,49 @@ func (b Builder) ReadOnly(value bool) Builder {
 	return b
 }
 
+func refOrSpecs(schemas []Schema) []*openapi.RefOrSpec[openapi.Schema] {
+	specs := make([]*openapi.RefOrSpec[openapi.Schema], 0, len(schemas))
+	for _, schema := range schemas {
+		specs = append(specs, refOrSpec(schema))
+	}
+	return specs
+}
+
+// OneOf requires the value to be valid against exactly one of the schemas.
+func (b Builder) OneOf(schemas ...Schema) Builder {
+	b.Schema.OneOf = append(b.Schema.OneOf, refOrSpecs(schemas)...)
+	return b
+}
+
+// AnyOf requires the value to be valid against at least one of the schemas.
+func (b Builder) AnyOf(schemas ...Schema) Builder {
+	b.Schema.AnyOf = append(b.Schema.AnyOf, refOrSpecs(schemas)...)
+	return b
+}
+
+// AllOf requires the value to be valid against all of the schemas.
+func (b Builder) AllOf(schemas ...Schema) Builder {
+	b.Schema.AllOf = append(b.Schema.AllOf, refOrSpecs(schemas)...)
+	return b
+}
+
+// Not requires the value to not be valid against the schema.
+func (b Builder) Not(schema Schema) Builder {
+	b.Schema.Not = refOrSpec(schema)
+	return b
+}
+
+// Discriminator sets the property used to determine which schema in a oneOf
+// or anyOf the value matches. The mapping is from property values to schema
+// references, and can be nil.
+func (b Builder) Discriminator(propertyName string, mapping map[string]string) Builder {
+	b.Schema.Discriminator = &openapi.Discriminator{
+		PropertyName: propertyName,
+		Mapping:      mapping,
+	}
+	return b
+}
+
 // ObjectBuilder provides functions for object related options on the schema.
 type ObjectBuilder struct {
 	Schema *openapi.Schema
@@ -114,16 +157,20 @@ func (o ObjectBuilder) Build() Schema {
 	return schema
 }
 
+// refOrSpec returns a reference if the schema is one, otherwise the schema itself.
+func refOrSpec(schema Schema) *openapi.RefOrSpec[openapi.Schema] {
+	if schema.refPath != "" {
+		return openapi.NewRefOrSpec[openapi.Schema](schema.refPath)
+	}
+	return openapi.NewRefOrSpec[openapi.Schema](schema.Schema)
+}
+
 func (o ObjectBuilder) Property(name string, schema Schema) ObjectBuilder {
 	if o.Schema.Properties == nil {
 		o.Schema.Properties = map[string]*openapi.RefOrSpec[openapi.Schema]{}
 	}
 
-	if schema.refPath != "" {
-		o.Schema.Properties[name] = openapi.NewRefOrSpec[openapi.Schema](schema.refPath)
-	} else {
-		o.Schema.Properties[name] = openapi.NewRefOrSpec[openapi.Schema](schema.Schema)
-	}
+	o.Schema.Properties[name] = refOrSpec(schema)
 	return o
 }
 
@@ -132,6 +179,40 @@ func (o ObjectBuilder) Required(vals ...string) ObjectBuilder {
 	return o
 }
 
+// DependentRequired marks the required properties as required
+// when the property is present.
+func (o ObjectBuilder) DependentRequired(property string, required ...string) ObjectBuilder {
+	if o.Schema.DependentRequired == nil {
+		o.Schema.DependentRequired = map[string][]string{}
+	}
+
+	o.Schema.DependentRequired[property] = append(
+		o.Schema.DependentRequired[property], required...,
+	)
+	return o
+}
+
+// DependentSchemas applies the schema to the object when the property is present.
+func (o ObjectBuilder) DependentSchemas(property string, schema Schema) ObjectBuilder {
+	if o.Schema.DependentSchemas == nil {
+		o.Schema.DependentSchemas = map[string]*openapi.RefOrSpec[openapi.Schema]{}
+	}
+
+	o.Schema.DependentSchemas[property] = refOrSpec(schema)
+	return o
+}
+
+// MutuallyExclusive allows at most one of the properties to be present.
+func (o ObjectBuilder) MutuallyExclusive(properties ...string) ObjectBuilder {
+	for i, a := range properties {
+		for _, b := range properties[i+1:] {
+			both := NewBuilder().Required(a, b).Build()
+			newBuilderWithSchema(o.Schema).AllOf(NewBuilder().Not(both).Build())
+		}
+	}
+	return o
+}
+
 func (o ObjectBuilder) MaxProperties(n int) ObjectBuilder {
 	o.Schema.MaxProperties = &n
 	return o
@@ -72,6 +72,54 @@ func TestObjectBuilderValues(t *testing.T) {
 }`)
 }
 
+func TestObjectBuilderDependentValues(t *testing.T) {
+	s := jsonschema.NewBuilder().
+		ObjectBuilder.
+		DependentRequired("card", "billing_address").
+		DependentSchemas("card", jsonschema.NewBuilder().
+			ObjectBuilder.
+			Required("name").
+			Build(),
+		).
+		DependentSchemas("ref", jsonschema.NewBuilder().Reference("reference")).
+		Build()
+
+	test.MatchAsJSON(t, s, `
+{
+ "dependentRequired": {
+  "card": [
+   "billing_address"
+  ]
+ },
+ "dependentSchemas": {
+  "card": {
+   "required": [
+    "name"
+   ]
+  },
+  "ref": {
+   "$ref": "reference"
+  }
+ }
+}`)
+}
+
+func TestObjectBuilderMutuallyExclusive(t *testing.T) {
+	s := jsonschema.NewBuilder().
+		ObjectBuilder.
+		MutuallyExclusive("id", "name", "email").
+		Build()
+
+	test.MatchAsJSON(t, s, `
+{
+ "allOf": [
+  {"not": {"required": ["id", "name"]}},
+  {"not": {"required": ["id", "email"]}},
+  {"not": {"required": ["name", "email"]}}
+ ]
+}`)
+}
+
 func TestStringBuilderValues(t *testing.T) {
 	s := jsonschema.NewBuilder().
 		StringBuilder.
@@ -128,3 +176,48 @@ func TestArrayBuilderValues(t *testing.T) {
  "uniqueItems": true
 }`)
 }
+
+func TestBuilderComposition(t *testing.T) {
+	str := jsonschema.NewBuilder().Type("string").Build()
+	ref := jsonschema.NewBuilder().Reference("reference")
+
+	s := jsonschema.NewBuilder().
+		OneOf(str, ref).
+		AnyOf(str).
+		AllOf(ref).
+		Not(str).
+		Build()
+
+	test.MatchAsJSON(t, s, `
+{
+ "oneOf": [
+  {"type": "string"},
+  {"$ref": "reference"}
+ ],
+ "anyOf": [
+  {"type": "string"}
+ ],
+ "allOf": [
+  {"$ref": "reference"}
+ ],
+ "not": {"type": "string"}
+}`)
+}
+
+func TestBuilderDiscriminator(t *testing.T) {
+	s := jsonschema.NewBuilder().
+		OneOf(jsonschema.NewBuilder().Reference("#/cat")).
+		Discriminator("kind", map[string]string{"cat": "#/cat"}).
+		Build()
+
+	test.MatchAsJSON(t, s, `
+{
+ "oneOf": [
+  {"$ref": "#/cat"}
+ ],
+ "discriminator": {
+  "propertyName": "kind",
+  "mapping": {"cat": "#/cat"}
+ }
+}`)
+}
@@ -30,3 +30,7 @@ const (
 	FormatRelativeJsonPointer Format = openapi.RelativeJsonPointerFormat
 	FormatRegex               Format = openapi.RegexFormat
 )
+
+// FormatGoDuration is the format of durations parsed by [time.ParseDuration],
+// such as 1h30m. It differs from [FormatDuration], which is an ISO 8601 duration.
+const FormatGoDuration Format = "go-duration"
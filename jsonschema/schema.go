@@ -2,14 +2,19 @@ package jsonschema
 
 import (
 	"encoding/json"
+	"encoding/xml"
 	"errors"
 	"fmt"
 	"maps"
 	"reflect"
+	"regexp"
 	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/sv-tools/openapi"
+	"github.com/zhamlin/routey/internal"
+	"github.com/zhamlin/routey/internal/structs"
 )
 
 func getTypeName(typ reflect.Type) string {
@@ -46,6 +51,15 @@ func NewDateTimeSchema() Schema {
 		Build()
 }
 
+// NewGoDurationSchema returns a [Schema] representing strings
+// in the format parsed by [time.ParseDuration], such as 1h30m.
+func NewGoDurationSchema() Schema {
+	schema := createStringSchema()
+	schema.Format = string(FormatGoDuration)
+	schema.Pattern = `^[-+]?(0|(([0-9]+(\.[0-9]*)?|\.[0-9]+)(ns|us|µs|μs|ms|s|m|h))+)$`
+	return schema
+}
+
 // MarshalJSON implements the [json.Marshaler] interface.
 func (s Schema) MarshalJSON() ([]byte, error) {
 	if ref := s.refPath; ref != "" {
@@ -54,6 +68,12 @@ func (s Schema) MarshalJSON() ([]byte, error) {
 	return json.Marshal(s.Schema)
 }
 
+// Hash returns a stable hex encoded hash of the schema,
+// changing only when its json encoding does.
+func (s Schema) Hash() (string, error) {
+	return internal.HashJSON(s)
+}
+
 // Property returns a [Builder] for the property matching
 // the supplied name. The returned builder will modify the
 // schema directly.
@@ -96,8 +116,9 @@ func (s *Schema) GetType() []string {
 	return *s.Type
 }
 
+// hasType returns true if the schema has a type or is composed of other schemas.
 func (s *Schema) hasType() bool {
-	return len(s.GetType()) > 0
+	return len(s.GetType()) > 0 || len(s.OneOf) > 0 || len(s.AnyOf) > 0 || len(s.AllOf) > 0
 }
 
 // Schemer creates [Schema] from provided types.
@@ -119,9 +140,18 @@ type Schemer struct {
 	// This defaults to the name from reflect.Type Name.
 	GetTypeName func(reflect.Type) string
 
+	// TagKey is the struct tag used to get the property names
+	// of struct fields.
+	//
+	// Defaults to `json`.
+	TagKey string
+
 	types map[reflect.Type]Schema
 }
 
+// DefaultTagKey is the struct tag used for property names by default.
+const DefaultTagKey = "json"
+
 // NewSchemer returns a [Schemer] with the default values set.
 func NewSchemer() Schemer {
 	return Schemer{
@@ -129,6 +159,7 @@ func NewSchemer() Schemer {
 		RefPath:              "/schemas/",
 		GetTypeName:          getTypeName,
 		DefaultStructRequire: false,
+		TagKey:               DefaultTagKey,
 	}
 }
 
@@ -183,6 +214,46 @@ func (s Schemer) Set(obj any, schema Schema, options ...Option) Schema {
 	return schema
 }
 
+// SetOneOf updates the provided types schema, usually an interface type, to be
+// one of the types in mapping. The property is used as the discriminator, with
+// each key in mapping being the property value for the type.
+func (s Schemer) SetOneOf(
+	obj any,
+	property string,
+	mapping map[string]reflect.Type,
+	options ...Option,
+) (Schema, error) {
+	builder := NewBuilder()
+	refs := map[string]string{}
+	seen := map[reflect.Type]bool{}
+
+	for _, key := range slices.Sorted(maps.Keys(mapping)) {
+		member := mapping[key]
+		memberSchema, err := s.schemaFromType(member)
+		if err != nil {
+			return Schema{}, err
+		}
+
+		shouldUseRef := s.useRefs() && !memberSchema.noRef
+		spec := s.refOrSpec(member, memberSchema, shouldUseRef)
+		if spec.Ref != nil {
+			refs[key] = spec.Ref.Ref
+		}
+
+		if !seen[member] {
+			seen[member] = true
+			builder.Schema.OneOf = append(builder.Schema.OneOf, spec)
+		}
+	}
+
+	if len(refs) == 0 {
+		refs = nil
+	}
+	builder.Discriminator(property, refs)
+
+	return s.Set(obj, builder.Build(), options...), nil
+}
+
 // NewRef returns string with [Schemer].RefPath prefixed to it.
 func (s Schemer) NewRef(name string) string {
 	if name == "" {
@@ -218,6 +289,10 @@ func (s Schemer) schemaFromType(typ reflect.Type) (Schema, error) {
 		return s.handleCustomSchemer(v, typ), nil
 	}
 
+	if v, ok := reflect.New(typ).Interface().(oneOfer); ok {
+		return s.handleOneOf(v, typ)
+	}
+
 	schema, err := s.createSchemaByKind(typ)
 	if err != nil {
 		return schema, err
@@ -235,6 +310,25 @@ func (s Schemer) handleCustomSchemer(schemer schemer, typ reflect.Type) Schema {
 	return schema
 }
 
+// handleOneOf creates a schema that is one of the types
+// returned by JSONSchemaOneOf.
+func (s Schemer) handleOneOf(v oneOfer, typ reflect.Type) (Schema, error) {
+	schema := New()
+	for _, member := range v.JSONSchemaOneOf() {
+		memberSchema, err := s.schemaFromType(member)
+		if err != nil {
+			return schema, err
+		}
+
+		shouldUseRef := s.useRefs() && !memberSchema.noRef
+		schema.OneOf = append(schema.OneOf, s.refOrSpec(member, memberSchema, shouldUseRef))
+	}
+
+	schema.name = s.GetTypeName(typ)
+	s.types[typ] = schema
+	return schema, nil
+}
+
 //nolint:cyclop
 func (s Schemer) createSchemaByKind(typ reflect.Type) (Schema, error) {
 	kind := typ.Kind()
@@ -358,8 +452,15 @@ func (s Schemer) useRefs() bool {
 	return s.RefPath != ""
 }
 
+func (s Schemer) fieldName(field reflect.StructField) string {
+	if s.TagKey == "" {
+		return JSONFieldName(field)
+	}
+	return FieldName(field, s.TagKey)
+}
+
 func (s Schemer) addObjectRequired(field reflect.StructField, schema, fieldSchema Schema) Schema {
-	fieldName := JSONFieldName(field)
+	fieldName := s.fieldName(field)
 	if fieldName != "" {
 		shouldUseRef := s.useRefs() && !fieldSchema.noRef
 		specOrRef := s.refOrSpec(field.Type, fieldSchema, shouldUseRef)
@@ -386,7 +487,10 @@ func (s Schemer) schemaFromStruct(typ reflect.Type) (Schema, error) {
 		if err != nil {
 			return err
 		}
-		fieldSchema.Schema = loadSchemaOptions(field, fieldSchema.Schema)
+		fieldSchema, err = loadSchemaOptions(typ, field, fieldSchema)
+		if err != nil {
+			return err
+		}
 
 		if field.Anonymous {
 			if !hasFieldType {
@@ -410,6 +514,12 @@ func (s Schemer) schemaFromStruct(typ reflect.Type) (Schema, error) {
 
 	for i := range fieldCount {
 		field := typ.Field(i)
+		if field.Type == xmlNameType {
+			// the element name of the struct, not a property
+			schema.XML = xmlFromTag(field.Tag.Get("xml"))
+			continue
+		}
+
 		if err := updateSchema(field); err != nil {
 			return schema, err
 		}
@@ -444,12 +554,19 @@ type schemer interface {
 	JSONSchema() Schema
 }
 
+// oneOfer is implemented by types that can be any one of the returned types,
+// such as a struct wrapping an interface value.
+type oneOfer interface {
+	JSONSchemaOneOf() []reflect.Type
+}
+
 type schemerExtended interface {
 	JSONSchemaExtend(s *Schema)
 }
 
 var (
 	noReferType = reflect.TypeFor[noRefer]()
+	xmlNameType = reflect.TypeFor[xml.Name]()
 
 	errInvalidMapKey = errors.New("maps only support string keys")
 )
@@ -506,7 +623,71 @@ func createFloatSchema() Schema {
 	return schema
 }
 
-func loadSchemaOptions(field reflect.StructField, schema openapi.Schema) openapi.Schema {
+var ErrInvalidEnum = errors.New("invalid enum value")
+
+// ParseEnum parses the comma separated values using the schemas type.
+func ParseEnum(tag string, schema Schema) ([]any, error) {
+	types := schema.GetType()
+	values := strings.Split(tag, ",")
+	enum := make([]any, 0, len(values))
+
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+
+		var v any
+		var err error
+
+		switch {
+		case slices.Contains(types, openapi.IntegerType):
+			v, err = strconv.ParseInt(value, 10, 64)
+		case slices.Contains(types, openapi.NumberType):
+			v, err = strconv.ParseFloat(value, 64)
+		case slices.Contains(types, openapi.BooleanType):
+			v, err = strconv.ParseBool(value)
+		default:
+			v = value
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q: %w", ErrInvalidEnum, value, err)
+		}
+		enum = append(enum, v)
+	}
+
+	return enum, nil
+}
+
+// TagError is returned when a struct tag used to
+// create a fields schema has an invalid value.
+type TagError struct {
+	// Struct containing the field.
+	Struct reflect.Type
+	Field  reflect.StructField
+	Tag    string
+	Err    error
+}
+
+func (e *TagError) Error() string {
+	return fmt.Sprintf("field %s: failed to parse tag %q: %s", e.Field.Name, e.Tag, e.Err)
+}
+
+func (e *TagError) Unwrap() error {
+	return e.Err
+}
+
+func parseIntTag(value string) (*int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+func loadSchemaOptions(typ reflect.Type, field reflect.StructField, schema Schema) (Schema, error) {
+	tagErr := func(tag string, err error) error {
+		return &TagError{Struct: typ, Field: field, Tag: tag, Err: err}
+	}
+
 	if v := field.Tag.Get("default"); v != "" {
 		schema.Default = v
 	}
@@ -515,18 +696,123 @@ func loadSchemaOptions(field reflect.StructField, schema openapi.Schema) openapi
 		schema.Description = v
 	}
 
+	if v := field.Tag.Get("deprecated"); v != "" {
+		schema = setDeprecated(v, schema)
+	}
+
+	if v := field.Tag.Get("xml"); v != "" {
+		schema.XML = xmlFromTag(v)
+	}
+
+	if v := field.Tag.Get("enum"); v != "" {
+		var err error
+		if schema, err = setEnum(v, schema); err != nil {
+			return schema, tagErr("enum", err)
+		}
+	}
+
+	intTags := []struct {
+		name  string
+		value **int
+	}{
+		{"minimum", &schema.Minimum},
+		{"maximum", &schema.Maximum},
+		{"minLength", &schema.MinLength},
+		{"maxLength", &schema.MaxLength},
+	}
+
+	for _, tag := range intTags {
+		v := field.Tag.Get(tag.name)
+		if v == "" {
+			continue
+		}
+
+		n, err := parseIntTag(v)
+		if err != nil {
+			return schema, tagErr(tag.name, err)
+		}
+		*tag.value = n
+	}
+
+	if v := field.Tag.Get("pattern"); v != "" {
+		if _, err := regexp.Compile(v); err != nil {
+			return schema, tagErr("pattern", err)
+		}
+		schema.Pattern = v
+	}
+
+	return schema, nil
+}
+
+// setEnum sets the enum from the value of an enum tag, on the items
+// of an array so each value is checked, or else on the schema.
+func setEnum(value string, schema Schema) (Schema, error) {
+	items := schema.Items
+	if items == nil || items.Schema == nil || items.Schema.Spec == nil {
+		enum, err := ParseEnum(value, schema)
+		schema.Enum = enum
+		return schema, err
+	}
+
+	// copy the items, which may be shared with the schema of a type
+	itemSchema := *items.Schema.Spec
+	enum, err := ParseEnum(value, Schema{Schema: itemSchema})
+	if err != nil {
+		return schema, err
+	}
+
+	itemSchema.Enum = enum
+	schema.Items = openapi.NewBoolOrSchema(openapi.NewRefOrSpec[openapi.Schema](itemSchema))
+	return schema, nil
+}
+
+// setDeprecated marks the schema as deprecated from the value of a deprecated
+// tag. Any value that is not a bool marks the schema as deprecated and is used
+// as its description when it has none, allowing a replacement to be suggested.
+func setDeprecated(value string, schema Schema) Schema {
+	if b, err := strconv.ParseBool(value); err == nil {
+		schema.Deprecated = b
+		return schema
+	}
+
+	schema.Deprecated = true
+	if schema.Description == "" {
+		schema.Description = value
+	}
 	return schema
 }
 
-func JSONFieldName(f reflect.StructField) string {
-	jsonTag := f.Tag.Get("json")
-	if jsonTag == "-" {
-		return ""
+// xmlFromTag returns the xml annotation for an xml struct tag, setting the
+// name, namespace and whether or not it is an attribute. Nil is returned
+// for tags without a name or attribute.
+func xmlFromTag(tag string) *openapi.Extendable[openapi.XML] {
+	name, opts, _ := strings.Cut(tag, ",")
+	if name == "-" || strings.Contains(name, ">") {
+		return nil
 	}
 
-	name := strings.Split(jsonTag, ",")[0]
-	if name == "" {
-		name = f.Name
+	x := openapi.XML{}
+	if namespace, local, has := strings.Cut(name, " "); has {
+		x.Namespace = namespace
+		name = local
+	}
+
+	x.Name = name
+	x.Attribute = slices.Contains(strings.Split(opts, ","), "attr")
+
+	if x.Name == "" && !x.Attribute {
+		return nil
 	}
-	return name
+	return openapi.NewExtendable(&x)
+}
+
+// JSONFieldName returns the name of the field from its json tag.
+func JSONFieldName(f reflect.StructField) string {
+	return FieldName(f, DefaultTagKey)
+}
+
+// FieldName returns the name of the field from the struct tag with the
+// provided key, or an empty string if the field is ignored.
+func FieldName(f reflect.StructField, key string) string {
+	return structs.TagName(f, key)
 }
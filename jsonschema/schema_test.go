@@ -1,6 +1,8 @@
 package jsonschema_test
 
 import (
+	"encoding/xml"
+	"reflect"
 	"testing"
 	"time"
 
@@ -479,6 +481,100 @@ func TestSchemaRef(t *testing.T) {
                 }
             }`,
 		},
+		{
+			name: "deprecated fields",
+			obj: struct {
+				Name     string `json:"name" deprecated:"true"`
+				Username string `json:"username" deprecated:"use name instead"`
+				Email    string `json:"email" deprecated:"false"`
+			}{},
+			want: `{
+                "type": "object",
+                "properties": {
+                    "name": {
+                        "type": "string",
+                        "deprecated": true
+                    },
+                    "username": {
+                        "type": "string",
+                        "deprecated": true,
+                        "description": "use name instead"
+                    },
+                    "email": {
+                        "type": "string"
+                    }
+                }
+            }`,
+		},
+	}
+
+	schemer := jsonschema.NewSchemer()
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			matchJSON(t, schemer, test.obj, test.want)
+		})
+	}
+}
+
+type circle struct {
+	Radius int `json:"radius"`
+}
+
+type square struct {
+	Side int `json:"side"`
+}
+
+type shape struct {
+	Value any
+}
+
+func (shape) JSONSchemaOneOf() []reflect.Type {
+	return []reflect.Type{
+		reflect.TypeFor[circle](),
+		reflect.TypeFor[square](),
+	}
+}
+
+func TestSchemaOneOf(t *testing.T) {
+	tests := []struct {
+		name string
+		obj  any
+		want string
+	}{
+		{
+			name: "members are referenced",
+			obj: struct {
+				Shape shape `json:"shape"`
+			}{},
+			want: `{
+                "type": "object",
+                "properties": {
+                    "shape": {
+                        "$ref": "/schemas/shape"
+                    }
+                }
+            }`,
+		},
+		{
+			obj: shape{},
+			want: `{
+                "oneOf": [
+                    {"$ref": "/schemas/circle"},
+                    {"$ref": "/schemas/square"}
+                ]
+            }`,
+		},
+		{
+			name: "array items use the one of schema",
+			obj:  []shape{},
+			want: `{
+                "type": "array",
+                "items": {
+                    "$ref": "/schemas/shape"
+                }
+            }`,
+		},
 	}
 
 	schemer := jsonschema.NewSchemer()
@@ -490,6 +586,60 @@ func TestSchemaRef(t *testing.T) {
 	}
 }
 
+func TestSchemaOneOfNoRefs(t *testing.T) {
+	schemer := jsonschema.NewSchemer()
+	schemer.RefPath = ""
+
+	matchJSON(t, schemer, shape{}, `{
+        "oneOf": [
+            {
+                "type": "object",
+                "properties": {"radius": {"type": "integer"}}
+            },
+            {
+                "type": "object",
+                "properties": {"side": {"type": "integer"}}
+            }
+        ]
+    }`)
+}
+
+type drawing interface {
+	Draw()
+}
+
+func TestSchemaSetOneOf(t *testing.T) {
+	schemer := jsonschema.NewSchemer()
+	_, err := schemer.SetOneOf(reflect.TypeFor[drawing](), "kind", map[string]reflect.Type{
+		"square": reflect.TypeFor[square](),
+		"circle": reflect.TypeFor[circle](),
+	})
+	test.NoError(t, err)
+
+	matchJSON(t, schemer, struct {
+		Drawing drawing `json:"drawing"`
+	}{}, `{
+        "type": "object",
+        "properties": {
+            "drawing": {"$ref": "/schemas/drawing"}
+        }
+    }`)
+
+	matchJSON(t, schemer, reflect.TypeFor[drawing](), `{
+        "oneOf": [
+            {"$ref": "/schemas/circle"},
+            {"$ref": "/schemas/square"}
+        ],
+        "discriminator": {
+            "propertyName": "kind",
+            "mapping": {
+                "circle": "/schemas/circle",
+                "square": "/schemas/square"
+            }
+        }
+    }`)
+}
+
 func TestSchemaCustomTypes(t *testing.T) {
 	tests := []struct {
 		name string
@@ -556,6 +706,67 @@ func TestSchemaModifiers(t *testing.T) {
                 }
             }`,
 		},
+		{
+			name: "enum values use the field type",
+			obj: struct {
+				Status string `json:"status" enum:"active, inactive,banned"`
+				Level  int    `json:"level" enum:"1,2,3"`
+			}{},
+			want: `{
+                "type": "object",
+                "properties": {
+                    "status": {
+                        "type": "string",
+                        "enum": ["active", "inactive", "banned"]
+                    },
+                    "level": {
+                        "type": "integer",
+                        "enum": [1, 2, 3]
+                    }
+                }
+            }`,
+		},
+		{
+			name: "enum values of slices are on the items",
+			obj: struct {
+				Levels []int `json:"levels" enum:"1,2,3"`
+			}{},
+			want: `{
+                "type": "object",
+                "properties": {
+                    "levels": {
+                        "type": "array",
+                        "items": {
+                            "type": "integer",
+                            "enum": [1, 2, 3]
+                        }
+                    }
+                }
+            }`,
+		},
+		{
+			name: "number and string constraints",
+			obj: struct {
+				Name  string `json:"name" minLength:"3" maxLength:"20" pattern:"^[a-z]+$"`
+				Count int    `json:"count" minimum:"1" maximum:"10"`
+			}{},
+			want: `{
+                "type": "object",
+                "properties": {
+                    "name": {
+                        "type": "string",
+                        "minLength": 3,
+                        "maxLength": 20,
+                        "pattern": "^[a-z]+$"
+                    },
+                    "count": {
+                        "type": "integer",
+                        "minimum": 1,
+                        "maximum": 10
+                    }
+                }
+            }`,
+		},
 	}
 
 	schemer := jsonschema.NewSchemer()
@@ -567,6 +778,96 @@ func TestSchemaModifiers(t *testing.T) {
 	}
 }
 
+func TestSchemaInvalidTag(t *testing.T) {
+	tests := []struct {
+		name string
+		obj  any
+		tag  string
+	}{
+		{
+			obj: struct {
+				Name string `maxLength:"ten"`
+			}{},
+			tag: "maxLength",
+		},
+		{
+			obj: struct {
+				Name string `pattern:"[a-z"`
+			}{},
+			tag: "pattern",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			_, err := jsonschema.NewSchemer().Get(tt.obj)
+
+			var want *jsonschema.TagError
+			test.WantError(t, err, &want)
+			test.Equal(t, want.Tag, tt.tag)
+		})
+	}
+}
+
+func TestSchemaInvalidEnum(t *testing.T) {
+	obj := struct {
+		Level int `enum:"1,two"`
+	}{}
+
+	_, err := jsonschema.NewSchemer().Get(obj)
+	test.IsError(t, err, jsonschema.ErrInvalidEnum)
+}
+
+func TestSchemaTagKey(t *testing.T) {
+	obj := struct {
+		Name    string `form:"user_name" json:"name"`
+		Ignored string `form:"-"`
+	}{}
+
+	schemer := jsonschema.NewSchemer()
+	schemer.TagKey = "form"
+
+	matchJSON(t, schemer, obj, `{
+        "type": "object",
+        "properties": {
+            "user_name": {
+                "type": "string"
+            }
+        }
+    }`)
+}
+
+func TestSchemaXML(t *testing.T) {
+	obj := struct {
+		XMLName xml.Name `xml:"user"`
+		ID      int      `json:"id" xml:"id,attr"`
+		Name    string   `json:"name" xml:"https://example.com full_name"`
+		Email   string   `json:"email" xml:",omitempty"`
+		Ignored string   `json:"ignored" xml:"-"`
+	}{}
+
+	matchJSON(t, jsonschema.NewSchemer(), obj, `{
+        "type": "object",
+        "xml": {"name": "user"},
+        "properties": {
+            "id": {
+                "type": "integer",
+                "xml": {"name": "id", "attribute": true}
+            },
+            "name": {
+                "type": "string",
+                "xml": {"name": "full_name", "namespace": "https://example.com"}
+            },
+            "email": {
+                "type": "string"
+            },
+            "ignored": {
+                "type": "string"
+            }
+        }
+    }`)
+}
+
 func TestSchemaStructFieldsRequired(t *testing.T) {
 	tests := []struct {
 		name string
@@ -608,3 +909,27 @@ func TestSchemaStructFieldsRequired(t *testing.T) {
 		})
 	}
 }
+
+func TestSchemaHash(t *testing.T) {
+	hash := func(s jsonschema.Schema) string {
+		got, err := s.Hash()
+		test.NoError(t, err)
+		return got
+	}
+
+	build := func() jsonschema.Schema {
+		return jsonschema.NewBuilder().
+			Type("object").
+			Property("b", jsonschema.NewBuilder().Type("string").Build()).
+			Property("a", jsonschema.NewBuilder().Type("integer").Build()).
+			Build()
+	}
+
+	test.Equal(t, hash(build()), hash(build()))
+
+	modified := build()
+	modified.Description = "changed"
+	if hash(build()) == hash(modified) {
+		t.Error("expected the hash of a modified schema to differ")
+	}
+}
@@ -52,6 +52,12 @@ func (c *Validator) Add(name, schema string) error {
 
 var ErrSchemaNotFound = errors.New("schema not found in validator")
 
+// Has returns true if a schema was added with the name.
+func (c *Validator) Has(name string) bool {
+	_, has := c.schemas[name]
+	return has
+}
+
 // Validate validates the input against the compiled schema matching
 // the name given.
 func (c *Validator) Validate(name string, input []byte) error {
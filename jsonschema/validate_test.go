@@ -215,3 +215,27 @@ func BenchmarkValidate(b *testing.B) {
 		}
 	})
 }
+
+func TestValidation_DependentSchemas(t *testing.T) {
+	s := jsonschema.NewBuilder().
+		Type("object").
+		Property("card", jsonschema.New()).
+		DependentSchemas("card", jsonschema.NewBuilder().
+			ObjectBuilder.
+			Required("billing_address").
+			Build(),
+		).
+		Build()
+
+	v := validatorFromSchema(t, s)
+
+	err := v.Validate("schema.json", []byte(`{"name": "test"}`))
+	test.NoError(t, err)
+
+	err = v.Validate("schema.json", []byte(`{"card": "1", "billing_address": "a"}`))
+	test.NoError(t, err)
+
+	err = v.Validate("schema.json", []byte(`{"card": "1"}`))
+	var want jsonschema.ValidationError
+	test.WantError(t, err, &want)
+}
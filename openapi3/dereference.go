@@ -0,0 +1,312 @@
+package openapi3
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/sv-tools/openapi"
+)
+
+// Dereference returns a copy of the spec with each component ref replaced by
+// the component it references, for consumers unable to resolve refs. Refs to a
+// component from within itself are kept, as cyclic components cannot be inlined,
+// along with the components they reference. Components used by a discriminator
+// mapping are kept, as the mapping references them. Security schemes are always
+// kept, as they are referenced by name. Literal values, such as examples, enums
+// and defaults, are kept as is.
+func (o OpenAPI) Dereference() (*OpenAPI, error) {
+	b, err := json.Marshal(o.OpenAPI)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc map[string]any
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+
+	components, _ := doc["components"].(map[string]any)
+	d := dereferencer{components: components}
+
+	var pending []string
+	for key, value := range doc {
+		if key != "components" && specField(key, value) != literalField {
+			doc[key] = d.inline(value, nil)
+			pending = append(pending, collectRefs(doc[key])...)
+		}
+	}
+
+	// the components of refs that were kept, inlining everything but the cycle
+	kept := map[string]map[string]any{}
+	for len(pending) > 0 {
+		ref := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+
+		kind, name, has := d.split(ref)
+		if !has || kept[kind][name] != nil {
+			continue
+		}
+
+		if kept[kind] == nil {
+			kept[kind] = map[string]any{}
+		}
+
+		component := d.inline(components[kind].(map[string]any)[name], []string{ref})
+		kept[kind][name] = component
+		pending = append(pending, collectRefs(component)...)
+	}
+
+	if components != nil {
+		result := map[string]any{}
+		for kind, items := range kept {
+			result[kind] = items
+		}
+
+		for key, value := range components {
+			if key == "securitySchemes" || strings.HasPrefix(key, "x-") {
+				result[key] = value
+			}
+		}
+		doc["components"] = result
+	}
+
+	b, err = json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var spec openapi.OpenAPI
+	if err := json.Unmarshal(b, &spec); err != nil {
+		return nil, err
+	}
+
+	dereferenced := o
+	dereferenced.OpenAPI = &spec
+	restoreOperationExtensions(o, dereferenced)
+	return &dereferenced, nil
+}
+
+// restoreOperationExtensions copies the extensions of the operations in from
+// missing from the matching operations in to, as decoding drops extensions
+// without the x- prefix.
+func restoreOperationExtensions(from, to OpenAPI) {
+	if from.Paths == nil || to.Paths == nil {
+		return
+	}
+
+	for name, path := range from.Paths.Spec.Paths {
+		dereferenced := to.Paths.Spec.Paths[name]
+		if path.Spec == nil || dereferenced == nil || dereferenced.Spec == nil {
+			continue
+		}
+
+		item := PathItem{PathItem: dereferenced.Spec.Spec}
+		for _, op := range (PathItem{PathItem: path.Spec.Spec}).GetOperations() {
+			to, has := item.GetOperation(op.Method)
+			if !has {
+				continue
+			}
+
+			for key, value := range op.Operation.Extensions {
+				if !strings.HasPrefix(key, openapi.ExtensionPrefix) {
+					to.AddExt(key, value)
+				}
+			}
+		}
+	}
+}
+
+// fieldKind is how the value of a field of a decoded spec object is walked.
+type fieldKind int
+
+const (
+	// objectField holds an object of the spec, or a list of them.
+	objectField fieldKind = iota
+	// namedField holds a map of user defined names to objects of the spec.
+	namedField
+	// literalField holds a value, such as an example, which is not walked.
+	literalField
+)
+
+// namedFields map names to objects, where a name such as "default"
+// could otherwise be mistaken for a field holding a literal value.
+var namedFields = []string{
+	"$defs",
+	"callbacks",
+	"content",
+	"dependentSchemas",
+	"encoding",
+	"headers",
+	"links",
+	"paths",
+	"patternProperties",
+	"properties",
+	"responses",
+	"schemas",
+	"webhooks",
+}
+
+var literalFields = []string{"const", "default", "enum", "example", "value"}
+
+func specField(key string, value any) fieldKind {
+	switch {
+	case strings.HasPrefix(key, openapi.ExtensionPrefix), slices.Contains(literalFields, key):
+		return literalField
+	case key == "examples":
+		// the examples of a schema are values, unlike the
+		// named example objects of media types and parameters
+		if _, isList := value.([]any); isList {
+			return literalField
+		}
+		return namedField
+	case slices.Contains(namedFields, key):
+		return namedField
+	}
+	return objectField
+}
+
+// mapFields returns a copy of the decoded spec object with fn applied
+// to each object it contains, keeping literal values as is.
+func mapFields(object map[string]any, fn func(any) any) map[string]any {
+	result := make(map[string]any, len(object))
+	for key, value := range object {
+		named, isNamed := value.(map[string]any)
+
+		switch kind := specField(key, value); {
+		case kind == literalField:
+			result[key] = value
+		case kind == namedField && isNamed:
+			items := make(map[string]any, len(named))
+			for name, item := range named {
+				items[name] = fn(item)
+			}
+			result[key] = items
+		default:
+			result[key] = fn(value)
+		}
+	}
+	return result
+}
+
+// objectFields returns the values of the decoded spec object containing
+// objects, in the order of their keys, skipping literal values.
+func objectFields(object map[string]any) []any {
+	var values []any
+	for _, key := range slices.Sorted(maps.Keys(object)) {
+		value := object[key]
+		named, isNamed := value.(map[string]any)
+
+		switch kind := specField(key, value); {
+		case kind == literalField:
+		case kind == namedField && isNamed:
+			for _, name := range slices.Sorted(maps.Keys(named)) {
+				values = append(values, named[name])
+			}
+		default:
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+type dereferencer struct {
+	components map[string]any
+}
+
+// split returns the kind and name of the component the ref points to,
+// and whether it exists.
+func (d dereferencer) split(ref string) (string, string, bool) {
+	ref, isComponent := strings.CutPrefix(ref, componentsRefPrefix)
+	if !isComponent {
+		return "", "", false
+	}
+
+	kind, name, _ := strings.Cut(ref, "/")
+	items, _ := d.components[kind].(map[string]any)
+	_, has := items[name]
+	return kind, name, has
+}
+
+// inline returns a copy of value with each ref replaced by a copy of its
+// component. Refs in stack are being inlined, and are kept to stop cycles.
+func (d dereferencer) inline(value any, stack []string) any {
+	switch v := value.(type) {
+	case map[string]any:
+		ref, isRef := v["$ref"].(string)
+		kind, name, has := d.split(ref)
+
+		if !isRef || !has || slices.Contains(stack, ref) {
+			return mapFields(v, func(value any) any {
+				return d.inline(value, stack)
+			})
+		}
+
+		stack = append(stack[:len(stack):len(stack)], ref)
+		component := d.inline(d.components[kind].(map[string]any)[name], stack)
+
+		// fields next to the ref override the ones of the component
+		if fields, ok := component.(map[string]any); ok && len(v) > 1 {
+			siblings := mapFields(v, func(value any) any {
+				return d.inline(value, stack)
+			})
+			for key, value := range siblings {
+				if key != "$ref" {
+					fields[key] = value
+				}
+			}
+		}
+		return component
+	case []any:
+		result := make([]any, len(v))
+		for i, value := range v {
+			result[i] = d.inline(value, stack)
+		}
+		return result
+	}
+	return value
+}
+
+// collectRefs returns the component refs contained in the decoded json value,
+// including the schemas of discriminator mappings.
+func collectRefs(value any) []string {
+	var refs []string
+	switch v := value.(type) {
+	case map[string]any:
+		if ref, ok := v["$ref"].(string); ok {
+			refs = append(refs, ref)
+		}
+
+		if discriminator, ok := v["discriminator"].(map[string]any); ok {
+			refs = append(refs, mappingRefs(discriminator)...)
+		}
+
+		for _, value := range objectFields(v) {
+			refs = append(refs, collectRefs(value)...)
+		}
+	case []any:
+		for _, value := range v {
+			refs = append(refs, collectRefs(value)...)
+		}
+	}
+	return refs
+}
+
+// mappingRefs returns the schema refs of the decoded discriminator mapping,
+// where a value that is not a ref is the name of a component schema.
+func mappingRefs(discriminator map[string]any) []string {
+	mapping, _ := discriminator["mapping"].(map[string]any)
+
+	var refs []string
+	for _, key := range slices.Sorted(maps.Keys(mapping)) {
+		ref, _ := mapping[key].(string)
+		if ref != "" && !strings.HasPrefix(ref, "#") {
+			ref = componentsRefPrefix + "schemas/" + ref
+		}
+		if ref != "" {
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
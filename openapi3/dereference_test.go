@@ -0,0 +1,213 @@
+package openapi3_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/sv-tools/openapi"
+	"github.com/zhamlin/routey"
+	"github.com/zhamlin/routey/internal/test"
+	"github.com/zhamlin/routey/jsonschema"
+	"github.com/zhamlin/routey/openapi3"
+	"github.com/zhamlin/routey/openapi3/option"
+)
+
+func TestOpenAPI_Dereference(t *testing.T) {
+	type Address struct {
+		City string `json:"city"`
+	}
+	type User struct {
+		Name    string  `json:"name"`
+		Address Address `json:"address"`
+	}
+
+	h := func(struct{}) (any, error) { return nil, nil }
+	r, spec := newTestRouter(t)
+
+	routey.Get(r, "/users", h,
+		option.ID("getUser"),
+		option.Response[User](http.StatusOK, "ok"),
+	)
+
+	got, err := spec.Dereference()
+	test.NoError(t, err)
+
+	test.MatchAsJSON(t, got.Paths, `
+	{
+	  "/users": {
+		"get": {
+		  "operationId": "getUser",
+		  "responses": {
+			"200": {
+			  "description": "ok",
+			  "content": {
+				"application/json": {
+				  "schema": {
+					"type": "object",
+					"properties": {
+					  "name": {"type": "string"},
+					  "address": {
+						"type": "object",
+						"properties": {
+						  "city": {"type": "string"}
+						}
+					  }
+					}
+				  }
+				}
+			  }
+			}
+		  }
+		}
+	  }
+	}
+	`)
+	test.MatchAsJSON(t, got.Components, `{}`)
+
+	// the original spec is unchanged
+	schema := spec.Paths.Spec.Paths["/users"].Spec.Spec.Get.Spec.Responses.Spec.Response["200"].
+		Spec.Spec.Content["application/json"].Spec.Schema
+	test.Equal(t, schema.Ref.Ref, "#/components/schemas/User")
+}
+
+func TestOpenAPI_DereferenceCycle(t *testing.T) {
+	h := func(struct{}) (any, error) { return nil, nil }
+	r, spec := newTestRouter(t)
+
+	var node jsonschema.Schema
+	err := json.Unmarshal([]byte(`{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string"},
+			"children": {
+				"type": "array",
+				"items": {"$ref": "#/components/schemas/Node"}
+			}
+		}
+	}`), &node.Schema)
+	test.NoError(t, err)
+	test.NoError(t, spec.GetComponents().AddSchema("Node", node))
+
+	routey.Get(r, "/tree", h,
+		option.ID("getTree"),
+		option.New(func(_ *option.Context, o *openapi3.Operation) error {
+			mt := openapi3.NewMediaType()
+			mt.Schema = openapi.NewRefOrSpec[openapi.Schema]("#/components/schemas/Node")
+
+			resp := openapi3.Response{}
+			resp.Description = "ok"
+			resp.SetContent(openapi3.JSONContentType, mt)
+			o.AddResponse(http.StatusOK, resp)
+			return nil
+		}),
+	)
+
+	got, err := spec.Dereference()
+	test.NoError(t, err)
+
+	schema := got.Paths.Spec.Paths["/tree"].Spec.Spec.Get.Spec.Responses.Spec.Response["200"].
+		Spec.Spec.Content["application/json"].Spec.Schema
+
+	test.MatchAsJSON(t, schema, `
+	{
+	  "type": "object",
+	  "properties": {
+		"name": {"type": "string"},
+		"children": {
+		  "type": "array",
+		  "items": {"$ref": "#/components/schemas/Node"}
+		}
+	  }
+	}
+	`)
+
+	test.MatchAsJSON(t, got.Components, `
+	{
+	  "schemas": {
+		"Node": {
+		  "type": "object",
+		  "properties": {
+			"name": {"type": "string"},
+			"children": {
+			  "type": "array",
+			  "items": {"$ref": "#/components/schemas/Node"}
+			}
+		  }
+		}
+	  }
+	}
+	`)
+}
+
+func TestOpenAPI_DereferenceDiscriminatorMapping(t *testing.T) {
+	type input struct {
+		Body openapi3.JSON[pet]
+	}
+	h := func(input) (any, error) { return nil, nil }
+	r, spec := newTestRouter(t)
+
+	err := openapi3.RegisterInterface[pet](spec, "kind", map[string]reflect.Type{
+		"cat": reflect.TypeFor[cat](),
+		"dog": reflect.TypeFor[dog](),
+	})
+	test.NoError(t, err)
+	routey.Post(r, "/pets", h, option.ID("createPet"))
+
+	got, err := spec.Dereference()
+	test.NoError(t, err)
+
+	schemas := got.Components.Spec.Schemas
+	test.Equal(t, len(schemas), 2)
+	test.Equal(t, schemas["cat"] != nil, true)
+	test.Equal(t, schemas["dog"] != nil, true)
+}
+
+func TestOpenAPI_DereferenceKeepsLiterals(t *testing.T) {
+	type User struct {
+		Name string `json:"name"`
+	}
+	h := func(struct{}) (any, error) { return nil, nil }
+	r, spec := newTestRouter(t)
+
+	routey.Get(r, "/users", h,
+		option.ID("getUser"),
+		option.Response[User](http.StatusOK, "ok"),
+		option.New(func(_ *option.Context, o *openapi3.Operation) error {
+			content := o.Responses.Spec.Response["200"].Spec.Spec.Content
+			content[openapi3.JSONContentType].Spec.Example = map[string]any{
+				"$ref": "#/components/schemas/User",
+			}
+			o.SetNoSecurity()
+			return nil
+		}),
+	)
+
+	got, err := spec.Dereference()
+	test.NoError(t, err)
+
+	op := got.Paths.Spec.Paths["/users"].Spec.Spec.Get
+	test.MatchAsJSON(t, op, `
+	{
+	  "operationId": "getUser",
+	  "security": [{}],
+	  "responses": {
+		"200": {
+		  "description": "ok",
+		  "content": {
+			"application/json": {
+			  "schema": {
+				"type": "object",
+				"properties": {
+				  "name": {"type": "string"}
+				}
+			  },
+			  "example": {"$ref": "#/components/schemas/User"}
+			}
+		  }
+		}
+	  }
+	}
+	`)
+}
@@ -0,0 +1,435 @@
+package openapi3
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/sv-tools/openapi"
+)
+
+// Change is a difference between two specs found by [Diff].
+type Change struct {
+	// Operation the change belongs to, formatted as "METHOD /path".
+	Operation string
+	// Location of the change within the operation, such as "parameters.query.id"
+	// or "responses.200.application/json.name". Empty when the operation itself
+	// was added or removed.
+	Location string
+	Message  string
+	// Breaking is true when clients built against the before spec
+	// may no longer work with the after spec.
+	Breaking bool
+}
+
+func (c Change) String() string {
+	kind := "non-breaking"
+	if c.Breaking {
+		kind = "breaking"
+	}
+
+	name := c.Operation
+	if c.Location != "" {
+		name += " " + c.Location
+	}
+	return fmt.Sprintf("[%s] %s: %s", kind, name, c.Message)
+}
+
+// Diff returns the changes to the operations from the before spec to the after spec,
+// classifying each as breaking or non-breaking for existing clients.
+// Schemas are compared as requests for parameters and request bodies, where
+// accepting less is breaking, and as responses, where returning more is breaking.
+func Diff(before, after *OpenAPI) ([]Change, error) {
+	d := differ{
+		before: before.Components,
+		after:  after.Components,
+	}
+
+	oldPaths, err := specPaths(before)
+	if err != nil {
+		return nil, err
+	}
+
+	newPaths, err := specPaths(after)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range unionKeys(oldPaths, newPaths) {
+		oldOps := pathOperations(oldPaths[path])
+		newOps := pathOperations(newPaths[path])
+
+		for _, method := range unionKeys(oldOps, newOps) {
+			d.operation = method + " " + path
+			oldOp, inOld := oldOps[method]
+			newOp, inNew := newOps[method]
+
+			switch {
+			case !inNew:
+				d.add("", true, "operation removed")
+			case !inOld:
+				d.add("", false, "operation added")
+			default:
+				if err := d.diffOperation(oldOp, newOp); err != nil {
+					return nil, fmt.Errorf("%s: %w", d.operation, err)
+				}
+			}
+		}
+	}
+
+	return d.changes, nil
+}
+
+func unionKeys[T any](a, b map[string]T) []string {
+	keys := slices.Collect(maps.Keys(a))
+	for key := range b {
+		if _, has := a[key]; !has {
+			keys = append(keys, key)
+		}
+	}
+
+	slices.Sort(keys)
+	return keys
+}
+
+func specPaths(o *OpenAPI) (map[string]PathItem, error) {
+	paths := map[string]PathItem{}
+	if o.Paths == nil {
+		return paths, nil
+	}
+
+	for name, path := range o.Paths.Spec.Paths {
+		item, err := path.GetSpec(o.Components)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		paths[name] = PathItem{PathItem: item.Spec}
+	}
+	return paths, nil
+}
+
+func pathOperations(p PathItem) map[string]Operation {
+	ops := map[string]Operation{}
+	if p.PathItem == nil {
+		return ops
+	}
+
+	for _, op := range p.GetOperations() {
+		if op.Operation.Operation != nil {
+			ops[op.Method] = op.Operation
+		}
+	}
+	return ops
+}
+
+type differ struct {
+	before, after *openapi.Extendable[openapi.Components]
+	operation     string
+	changes       []Change
+	// schemas being compared, used to stop at recursive references
+	comparing map[[2]*openapi.Schema]bool
+}
+
+func (d *differ) add(location string, breaking bool, format string, args ...any) {
+	d.changes = append(d.changes, Change{
+		Operation: d.operation,
+		Location:  location,
+		Message:   fmt.Sprintf(format, args...),
+		Breaking:  breaking,
+	})
+}
+
+func (d *differ) diffOperation(before, after Operation) error {
+	if err := d.diffParameters(before.Parameters, after.Parameters); err != nil {
+		return err
+	}
+
+	if err := d.diffRequestBody(before.RequestBody, after.RequestBody); err != nil {
+		return err
+	}
+
+	return d.diffResponses(before.Responses, after.Responses)
+}
+
+func operationParameters(
+	params []*openapi.RefOrSpec[openapi.Extendable[openapi.Parameter]],
+	c *openapi.Extendable[openapi.Components],
+) (map[string]*openapi.Parameter, error) {
+	found := map[string]*openapi.Parameter{}
+	for _, p := range params {
+		spec, err := p.GetSpec(c)
+		if err != nil {
+			return nil, err
+		}
+		found["parameters."+spec.Spec.In+"."+spec.Spec.Name] = spec.Spec
+	}
+	return found, nil
+}
+
+func (d *differ) diffParameters(
+	before, after []*openapi.RefOrSpec[openapi.Extendable[openapi.Parameter]],
+) error {
+	oldParams, err := operationParameters(before, d.before)
+	if err != nil {
+		return err
+	}
+
+	newParams, err := operationParameters(after, d.after)
+	if err != nil {
+		return err
+	}
+
+	for _, location := range unionKeys(oldParams, newParams) {
+		oldParam, newParam := oldParams[location], newParams[location]
+
+		switch {
+		case newParam == nil && oldParam.Required:
+			d.add(location, true, "required parameter removed")
+		case newParam == nil:
+			d.add(location, false, "parameter removed")
+		case oldParam == nil && newParam.Required:
+			d.add(location, true, "required parameter added")
+		case oldParam == nil:
+			d.add(location, false, "parameter added")
+		default:
+			if !oldParam.Required && newParam.Required {
+				d.add(location, true, "parameter became required")
+			} else if oldParam.Required && !newParam.Required {
+				d.add(location, false, "parameter became optional")
+			}
+
+			if err := d.diffSchema(location, oldParam.Schema, newParam.Schema, true); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (d *differ) diffRequestBody(before, after *openapi.RefOrSpec[openapi.Extendable[openapi.RequestBody]]) error {
+	const location = "requestBody"
+
+	switch {
+	case before == nil && after == nil:
+		return nil
+	case before == nil:
+		body, err := after.GetSpec(d.after)
+		if err != nil {
+			return err
+		}
+		d.add(location, body.Spec.Required, "request body added")
+		return nil
+	case after == nil:
+		d.add(location, true, "request body removed")
+		return nil
+	}
+
+	oldBody, err := before.GetSpec(d.before)
+	if err != nil {
+		return err
+	}
+
+	newBody, err := after.GetSpec(d.after)
+	if err != nil {
+		return err
+	}
+
+	if !oldBody.Spec.Required && newBody.Spec.Required {
+		d.add(location, true, "request body became required")
+	}
+
+	return d.diffContent(location, oldBody.Spec.Content, newBody.Spec.Content, true)
+}
+
+func operationResponses(
+	r *openapi.Extendable[openapi.Responses],
+	c *openapi.Extendable[openapi.Components],
+) (map[string]*openapi.Response, error) {
+	found := map[string]*openapi.Response{}
+	if r == nil || r.Spec == nil {
+		return found, nil
+	}
+
+	responses := maps.Clone(r.Spec.Response)
+	if r.Spec.Default != nil {
+		if responses == nil {
+			responses = map[string]*openapi.RefOrSpec[openapi.Extendable[openapi.Response]]{}
+		}
+		responses["default"] = r.Spec.Default
+	}
+
+	for code, resp := range responses {
+		spec, err := resp.GetSpec(c)
+		if err != nil {
+			return nil, err
+		}
+		found["responses."+code] = spec.Spec
+	}
+	return found, nil
+}
+
+func (d *differ) diffResponses(before, after *openapi.Extendable[openapi.Responses]) error {
+	oldResps, err := operationResponses(before, d.before)
+	if err != nil {
+		return err
+	}
+
+	newResps, err := operationResponses(after, d.after)
+	if err != nil {
+		return err
+	}
+
+	for _, location := range unionKeys(oldResps, newResps) {
+		oldResp, newResp := oldResps[location], newResps[location]
+
+		switch {
+		case newResp == nil:
+			d.add(location, true, "response removed")
+		case oldResp == nil:
+			d.add(location, false, "response added")
+		default:
+			if err := d.diffContent(location, oldResp.Content, newResp.Content, false); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (d *differ) diffContent(
+	location string,
+	before, after map[string]*openapi.Extendable[openapi.MediaType],
+	isRequest bool,
+) error {
+	for _, contentType := range unionKeys(before, after) {
+		oldMedia, newMedia := before[contentType], after[contentType]
+		mediaLocation := location + "." + contentType
+
+		switch {
+		case newMedia == nil:
+			d.add(mediaLocation, true, "content type removed")
+		case oldMedia == nil:
+			// clients of a response only handle the content types they know of
+			d.add(mediaLocation, !isRequest, "content type added")
+		default:
+			err := d.diffSchema(mediaLocation, oldMedia.Spec.Schema, newMedia.Spec.Schema, isRequest)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func schemaTypes(s *openapi.Schema) string {
+	if s.Type == nil {
+		return ""
+	}
+
+	types := slices.Clone(*s.Type)
+	slices.Sort(types)
+	return strings.Join(types, ",")
+}
+
+func (d *differ) diffSchema(location string, before, after *openapi.RefOrSpec[openapi.Schema], isRequest bool) error {
+	if before == nil || after == nil {
+		return nil
+	}
+
+	oldSchema, err := before.GetSpec(d.before)
+	if err != nil {
+		return err
+	}
+
+	newSchema, err := after.GetSpec(d.after)
+	if err != nil {
+		return err
+	}
+
+	key := [2]*openapi.Schema{oldSchema, newSchema}
+	if d.comparing[key] {
+		return nil
+	}
+
+	if d.comparing == nil {
+		d.comparing = map[[2]*openapi.Schema]bool{}
+	}
+	d.comparing[key] = true
+	defer delete(d.comparing, key)
+
+	oldType, newType := schemaTypes(oldSchema), schemaTypes(newSchema)
+	if oldType != newType {
+		d.add(location, true, "type changed from %q to %q", oldType, newType)
+		return nil
+	}
+
+	d.diffEnum(location, oldSchema.Enum, newSchema.Enum, isRequest)
+	d.diffRequired(location, oldSchema, newSchema, isRequest)
+
+	for _, name := range unionKeys(oldSchema.Properties, newSchema.Properties) {
+		oldProp, newProp := oldSchema.Properties[name], newSchema.Properties[name]
+		propLocation := location + "." + name
+
+		switch {
+		case newProp == nil:
+			// clients may depend on a property in a response
+			d.add(propLocation, !isRequest, "property removed")
+		case oldProp == nil:
+			required := slices.Contains(newSchema.Required, name)
+			d.add(propLocation, isRequest && required, "property added")
+		default:
+			if err := d.diffSchema(propLocation, oldProp, newProp, isRequest); err != nil {
+				return err
+			}
+		}
+	}
+
+	if oldSchema.Items != nil && newSchema.Items != nil {
+		return d.diffSchema(location+"[]", oldSchema.Items.Schema, newSchema.Items.Schema, isRequest)
+	}
+	return nil
+}
+
+func (d *differ) diffEnum(location string, before, after []any, isRequest bool) {
+	switch {
+	case len(before) == 0 && len(after) == 0:
+		return
+	case len(before) == 0:
+		d.add(location, isRequest, "enum added")
+		return
+	case len(after) == 0:
+		d.add(location, !isRequest, "enum removed")
+		return
+	}
+
+	for _, value := range before {
+		if !slices.Contains(after, value) {
+			d.add(location, isRequest, "enum value %v removed", value)
+		}
+	}
+
+	for _, value := range after {
+		if !slices.Contains(before, value) {
+			d.add(location, !isRequest, "enum value %v added", value)
+		}
+	}
+}
+
+// diffRequired reports properties that existed in both schemas
+// and changed from being optional to required, or the reverse.
+func (d *differ) diffRequired(location string, before, after *openapi.Schema, isRequest bool) {
+	for _, name := range after.Required {
+		_, existed := before.Properties[name]
+		if existed && !slices.Contains(before.Required, name) {
+			d.add(location+"."+name, isRequest, "property became required")
+		}
+	}
+
+	for _, name := range before.Required {
+		_, exists := after.Properties[name]
+		if exists && !slices.Contains(after.Required, name) {
+			d.add(location+"."+name, !isRequest, "property became optional")
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package openapi3_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/zhamlin/routey"
+	"github.com/zhamlin/routey/internal/test"
+	"github.com/zhamlin/routey/openapi3"
+	"github.com/zhamlin/routey/openapi3/option"
+)
+
+func TestDiff_RemovedRequiredParam(t *testing.T) {
+	type before struct {
+		ID   routey.Query[int] `required:"true"`
+		Page routey.Query[int]
+	}
+	type after struct {
+		Page routey.Query[int]
+	}
+	h := func(struct{}) (any, error) { return nil, nil }
+
+	oldRouter, oldSpec := newTestRouter(t)
+	routey.Get(oldRouter, "/users", func(before) (any, error) { return nil, nil }, option.ID("users"))
+	routey.Get(oldRouter, "/posts", h, option.ID("posts"))
+
+	newRouter, newSpec := newTestRouter(t)
+	routey.Get(newRouter, "/users", func(after) (any, error) { return nil, nil }, option.ID("users"))
+
+	changes, err := openapi3.Diff(oldSpec, newSpec)
+	test.NoError(t, err)
+
+	test.MatchAsJSON(t, changes, []openapi3.Change{
+		{
+			Operation: "GET /posts",
+			Message:   "operation removed",
+			Breaking:  true,
+		},
+		{
+			Operation: "GET /users",
+			Location:  "parameters.query.id",
+			Message:   "required parameter removed",
+			Breaking:  true,
+		},
+	})
+}
+
+func TestDiff_Schemas(t *testing.T) {
+	type BodyV1 struct {
+		Name   string `json:"name"`
+		Status string `json:"status" enum:"active,inactive"`
+	}
+	type BodyV2 struct {
+		Name   string `json:"name"`
+		Status string `json:"status" enum:"active"`
+		Age    int    `json:"age"`
+	}
+	type UserV1 struct {
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	}
+	type UserV2 struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+
+	oldRouter, oldSpec := newTestRouter(t)
+	routey.Post(oldRouter, "/users",
+		func(struct{ Body openapi3.JSON[BodyV1] }) (any, error) { return nil, nil },
+		option.ID("createUser"),
+		option.Response[UserV1](http.StatusOK, "ok"),
+	)
+
+	newRouter, newSpec := newTestRouter(t)
+	routey.Post(newRouter, "/users",
+		func(struct{ Body openapi3.JSON[BodyV2] }) (any, error) { return nil, nil },
+		option.ID("createUser"),
+		option.Response[UserV2](http.StatusOK, "ok"),
+		option.Response[UserV2](http.StatusCreated, "created"),
+	)
+
+	changes, err := openapi3.Diff(oldSpec, newSpec)
+	test.NoError(t, err)
+
+	got := make([]string, 0, len(changes))
+	for _, c := range changes {
+		got = append(got, c.String())
+	}
+
+	test.MatchAsJSON(t, got, []string{
+		"[non-breaking] POST /users requestBody.application/json.age: property added",
+		"[breaking] POST /users requestBody.application/json.status: enum value inactive removed",
+		"[non-breaking] POST /users responses.200.application/json.age: property added",
+		"[breaking] POST /users responses.200.application/json.email: property removed",
+		"[non-breaking] POST /users responses.201: response added",
+	})
+}
@@ -7,7 +7,11 @@ import (
 	"net/http"
 	"net/url"
 	"reflect"
+	"slices"
 	"strings"
+	"sync"
+	"time"
+	"unsafe"
 
 	"github.com/zhamlin/routey"
 	"github.com/zhamlin/routey/extractor"
@@ -40,7 +44,13 @@ func opFromCtx(ctx Context, info *route.Info) (Operation, error) {
 	return op, nil
 }
 
+// parseable returns true if the value, a pointer, can be parsed by the parser
+// or parses its own params. Values are never given to a [param.FieldParser],
+// only checked for it.
 func parseable(parser param.Parser, value any) bool {
+	if param.IsFieldParser(reflect.TypeOf(value).Elem()) {
+		return true
+	}
 	return !errors.Is(parser(value, []string{""}), param.ErrInvalidParamType)
 }
 
@@ -50,8 +60,11 @@ func getDefaultValue(f reflect.StructField, schema jsonschema.Schema) string {
 	}
 
 	fieldName := jsonschema.JSONFieldName(f)
-	if prop, ok := schema.Properties[fieldName]; ok {
+	if prop, ok := schema.Properties[fieldName]; ok && prop.Spec != nil {
 		def := prop.Spec.Default
+		if def == nil {
+			return ""
+		}
 		if s, ok := def.(string); ok {
 			return s
 		}
@@ -61,7 +74,56 @@ func getDefaultValue(f reflect.StructField, schema jsonschema.Schema) string {
 	return ""
 }
 
-func validDeepObjectType(parser param.Parser, typ reflect.Type) error {
+// isNestedObject returns true if values of the field are
+// parsed from the params of its own fields.
+func isNestedObject(parser param.Parser, f reflect.StructField) bool {
+	return f.Type.Kind() == reflect.Struct && !parseable(parser, reflect.New(f.Type).Interface())
+}
+
+// nestedFieldsKey is the key of the nested fields
+// of a struct type parsed with a parser.
+type nestedFieldsKey struct {
+	typ    reflect.Type
+	parser unsafe.Pointer
+}
+
+type cachedNestedFields struct {
+	nested []bool
+	// keeps the parser alive so its address is not reused
+	parser param.Parser
+}
+
+// nestedFieldsCache holds the nested fields of the struct types checked by
+// CanParse, so requests do not have to check them again.
+var nestedFieldsCache sync.Map
+
+func funcAddr[F any](f F) unsafe.Pointer {
+	return *(*unsafe.Pointer)(unsafe.Pointer(&f))
+}
+
+// nestedFields reports for each field of the struct type whether it is a
+// nested object, see [isNestedObject].
+func nestedFields(parser param.Parser, typ reflect.Type) []bool {
+	key := nestedFieldsKey{typ: typ, parser: funcAddr(parser)}
+	if cached, has := nestedFieldsCache.Load(key); has {
+		return cached.(cachedNestedFields).nested
+	}
+
+	nested := make([]bool, typ.NumField())
+	for i := range nested {
+		nested[i] = isNestedObject(parser, typ.Field(i))
+	}
+
+	cached, _ := nestedFieldsCache.LoadOrStore(key, cachedNestedFields{
+		nested: nested,
+		parser: parser,
+	})
+	return cached.(cachedNestedFields).nested
+}
+
+// validDeepObjectType returns an error if a field of the struct cannot be
+// parsed. Fields that are structs are checked the same way when nested is true.
+func validDeepObjectType(parser param.Parser, typ reflect.Type, nested bool) error {
 	if typ.Kind() == reflect.Pointer {
 		typ = typ.Elem()
 	}
@@ -75,8 +137,7 @@ func validDeepObjectType(parser param.Parser, typ reflect.Type) error {
 		return fmt.Errorf("error getting schema for deepObject(%s): %w", typ.String(), err)
 	}
 
-	n := typ.NumField()
-	for i := range n {
+	for i, isNested := range nestedFields(parser, typ) {
 		f := typ.Field(i)
 		if !f.IsExported() {
 			return &param.InvalidParamError{
@@ -87,6 +148,13 @@ func validDeepObjectType(parser param.Parser, typ reflect.Type) error {
 			}
 		}
 
+		if nested && isNested {
+			if err := validDeepObjectType(parser, f.Type, nested); err != nil {
+				return err
+			}
+			continue
+		}
+
 		value := reflect.New(f.Type).Interface()
 
 		if !parseable(parser, value) {
@@ -98,7 +166,7 @@ func validDeepObjectType(parser param.Parser, typ reflect.Type) error {
 
 		defaultValue := getDefaultValue(f, schema)
 		if defaultValue != "" {
-			if err := parser(value, []string{defaultValue}); err != nil {
+			if err := param.Parse(parser, value, []string{defaultValue}); err != nil {
 				return &param.InvalidParamError{
 					Struct:  typ,
 					Field:   f,
@@ -157,7 +225,7 @@ func validateJSONBodySchema(ctx Context, op Operation, value any) error {
 
 	if errors.As(err, &want) {
 		want.Location = loc
-		return want
+		return extractor.WithStatusCode(want, http.StatusBadRequest)
 	}
 
 	return err
@@ -176,6 +244,11 @@ func (q *Query[T]) CanParse(
 		return nil
 	}
 
+	// content is decoded instead of parsed
+	if openAPIParam.GetContentFromTag(source.Tag) != "" {
+		return nil
+	}
+
 	style, err := openAPIParam.GetStyleFromTag(source.Tag)
 	if err != nil {
 		// Return nil here to allow for better error reporting
@@ -190,7 +263,7 @@ func (q *Query[T]) CanParse(
 	}
 
 	typ := reflect.TypeFor[T]()
-	if err := validDeepObjectType(parser, typ); err != nil {
+	if err := validDeepObjectType(parser, typ, true); err != nil {
 		return err
 	}
 
@@ -219,7 +292,63 @@ func (q *Query[T]) Extract(r *http.Request, info *route.Info, opts param.Opts) e
 	}
 
 	values := extractor.GetAndSetQueryValues(r)
-	return q.parse(values, opts, param, ctx)
+	if param.Deprecated && ctx.OnDeprecatedParam != nil && hasQueryParam(values, param) {
+		ctx.OnDeprecatedParam(r, param)
+	}
+
+	if err := validateMutuallyExclusive(values, op, param); err != nil {
+		return err
+	}
+
+	return q.parse(values, opts, param, ctx, fieldValidatorFromCtx(info.Context))
+}
+
+// validateMutuallyExclusive returns an error if the values contain the param
+// and any other param it is mutually exclusive with.
+func validateMutuallyExclusive(values url.Values, op Operation, p openAPIParam.Parameter) error {
+	if !hasQueryParam(values, p) {
+		return nil
+	}
+
+	for _, names := range op.MutuallyExclusive() {
+		if !slices.Contains(names, p.Name) {
+			continue
+		}
+
+		for _, name := range names {
+			other, has := op.GetParameter(name, p.In)
+			if !has {
+				other = openAPIParam.New()
+				other.Name = name
+			}
+
+			if name != p.Name && hasQueryParam(values, other) {
+				err := jsonschema.ValidationError{
+					Location: "#/parameters/query",
+					Message:  fmt.Sprintf("%q and %q are mutually exclusive", p.Name, name),
+				}
+				return extractor.WithStatusCode(err, http.StatusBadRequest)
+			}
+		}
+	}
+	return nil
+}
+
+// hasQueryParam returns true if the values contain the param,
+// including any deepObject properties of it.
+func hasQueryParam(values url.Values, p openAPIParam.Parameter) bool {
+	if values.Has(p.Name) {
+		return true
+	}
+
+	if openAPIParam.Style(p.Style) == openAPIParam.StyleDeepObject {
+		for name := range values {
+			if strings.HasPrefix(name, p.Name+"[") {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 func (q *Query[T]) parse(
@@ -227,18 +356,23 @@ func (q *Query[T]) parse(
 	opts param.Opts,
 	p openAPIParam.Parameter,
 	ctx Context,
+	fieldValidator *jsonschema.Validator,
 ) error {
 	var err error
 
 	// TODO: handle required
-	switch openAPIParam.Style(p.Style) {
-	case openAPIParam.StyleForm:
+	switch style := openAPIParam.Style(p.Style); {
+	case len(p.Content) > 0:
+		err = q.parseContent(values, opts, p)
+	case style == openAPIParam.StyleForm:
 		err = q.parseForm(values, opts, p)
-	case openAPIParam.StyleDeepObject:
-		err = q.parseDeepObject(values, opts, p, ctx.OpenAPI)
+	case style == openAPIParam.StyleSpaceDelimited:
+		err = q.parseSpaceDelimited(values, opts, p)
+	case style == openAPIParam.StylePipeDelimited:
+		err = q.parsePipeDelimited(values, opts, p)
+	case style == openAPIParam.StyleDeepObject:
+		err = q.parseDeepObject(values, opts, p, ctx.OpenAPI, fieldValidator)
 	default:
-		// case openAPIParam.StyleSpaceDelimited:
-		// case openAPIParam.StylePipeDelimited:
 		return nil
 	}
 
@@ -246,22 +380,78 @@ func (q *Query[T]) parse(
 		return err
 	}
 
-	return validateSchema(p.Name, ctx.Validator, &q.Value)
+	return validateSchema(p.Name, p.In, ctx.Validator, &q.Value)
 }
 
 func (q *Query[T]) parseForm(values url.Values, opts param.Opts, p openAPIParam.Parameter) error {
+	return q.parseDelimited(values, opts, p, ",")
+}
+
+func (q *Query[T]) parseSpaceDelimited(values url.Values, opts param.Opts, p openAPIParam.Parameter) error {
+	return q.parseDelimited(values, opts, p, " ")
+}
+
+func (q *Query[T]) parsePipeDelimited(values url.Values, opts param.Opts, p openAPIParam.Parameter) error {
+	return q.parseDelimited(values, opts, p, "|")
+}
+
+// parseDelimited parses the values of the param, splitting them
+// on the separator when the param is not exploded.
+func (q *Query[T]) parseDelimited(
+	values url.Values,
+	opts param.Opts,
+	p openAPIParam.Parameter,
+	separator string,
+) error {
 	params := values[opts.Name]
 
-	// params are separated by ,
+	if len(params) == 0 && opts.Required && opts.Default == "" {
+		return extractor.ParamError(&param.MissingRequiredError{
+			Name:   opts.Name,
+			Source: q.Source(),
+		})
+	}
+
 	if !p.Explode && len(params) > 0 {
-		params = strings.Split(params[0], ",")
+		params = strings.Split(params[0], separator)
 	}
 
 	err := opts.Parse(&q.Value, params)
 	if err != nil {
-		return fmt.Errorf("%w: %w", extractor.ErrParamFailedToExtract, err)
+		return extractor.ParamError(err)
+	}
+
+	return nil
+}
+
+// parseContent decodes the value of the param using the media type of its
+// content. Only json is supported.
+func (q *Query[T]) parseContent(values url.Values, opts param.Opts, p openAPIParam.Parameter) error {
+	params := values[opts.Name]
+	if len(params) == 0 && opts.Default != "" {
+		params = []string{opts.Default}
 	}
 
+	if len(params) == 0 {
+		if opts.Required {
+			return extractor.ParamError(&param.MissingRequiredError{
+				Name:   opts.Name,
+				Source: q.Source(),
+			})
+		}
+		return nil
+	}
+
+	for contentType := range p.Content {
+		if !strings.Contains(contentType, "json") {
+			return fmt.Errorf("%w: %w: %q", extractor.ErrParamFailedToExtract,
+				extractor.ErrUnsupportedContentType, contentType)
+		}
+	}
+
+	if err := json.Unmarshal([]byte(params[0]), &q.Value); err != nil {
+		return extractor.ParamError(err)
+	}
 	return nil
 }
 
@@ -270,37 +460,322 @@ func (q *Query[T]) parseDeepObject(
 	opts param.Opts,
 	p openAPIParam.Parameter,
 	spec *OpenAPI,
+	fieldValidator *jsonschema.Validator,
 ) error {
-	val := reflect.ValueOf(&q.Value)
-	typ := val.Elem().Type()
-	s, err := spec.getSchemaSource(p.Schema)
+	return parseFields(&q.Value, opts, p, spec, fieldValidator, func(fields ...string) (string, []string) {
+		name := fmt.Sprintf("%s[%s]", p.Name, strings.Join(fields, "]["))
+		return name, values[name]
+	})
+}
 
+// parseFields parses each field of the struct pointed to by ptr, using get to
+// return the name and the params of the field from the json names of it and
+// the fields containing it. Fields that are structs have their fields parsed
+// when the param allows nesting them. Values of fields with an enum tag must
+// be one of its values, as with other params, and the values given are
+// validated by the field validator against the schema of their property.
+func parseFields(
+	ptr any,
+	opts param.Opts,
+	p openAPIParam.Parameter,
+	spec *OpenAPI,
+	fieldValidator *jsonschema.Validator,
+	get func(fields ...string) (string, []string),
+) error {
+	s, err := spec.getSchemaSource(p.Schema)
 	if err != nil {
 		return err
 	}
-	schema := s.JSONSchema()
 
-	n := typ.NumField()
-	for i := range n {
+	val := reflect.ValueOf(ptr).Elem()
+	return parseStructFields(val, opts, p, s.JSONSchema(), spec, fieldValidator, nil, get)
+}
+
+func parseStructFields(
+	val reflect.Value,
+	opts param.Opts,
+	p openAPIParam.Parameter,
+	schema jsonschema.Schema,
+	spec *OpenAPI,
+	fieldValidator *jsonschema.Validator,
+	parents []string,
+	get func(fields ...string) (string, []string),
+) error {
+	typ := val.Type()
+
+	for i, nested := range nestedFields(opts.Parser, typ) {
 		fType := typ.Field(i)
-		f := val.Elem().Field(i)
+		f := val.Field(i)
+		jsonName := jsonschema.JSONFieldName(fType)
+		fields := append(parents[:len(parents):len(parents)], jsonName)
+
+		if nested {
+			prop, err := spec.getSchemaSource(schema.Properties[jsonName])
+			if err != nil {
+				return err
+			}
+
+			err = parseStructFields(f, opts, p, prop.JSONSchema(), spec, fieldValidator, fields, get)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		name, params := get(fields...)
+		fieldOpts := opts
+		fieldOpts.Name = name
+		fieldOpts.Parser = param.ParserForField(fType, opts.Parser)
+		fieldOpts.Default = getDefaultValue(fType, schema)
+		fieldOpts.Enum = param.EnumFromField(fType)
+
+		value := f.Addr().Interface()
+		if err := fieldOpts.Parse(value, params); err != nil {
+			return extractor.ParamError(fmt.Errorf("opts.Parse(%s, %v): %w", name, params, err))
+		}
+
+		if len(params) > 0 {
+			if err := validateField(p, fields, fieldValidator, value); err != nil {
+				return extractor.ParamError(fmt.Errorf("%s: %w", name, err))
+			}
+		}
+	}
+
+	return nil
+}
 
-		fieldName := jsonschema.JSONFieldName(fType)
-		name := fmt.Sprintf("%s[%s]", p.Name, fieldName)
-		params := values[name]
+// ErrInvalidStyleValue is returned when a path value
+// is not encoded in the style of its param.
+var ErrInvalidStyleValue = errors.New("value does not match the param style")
 
-		opts.Default = getDefaultValue(fType, schema)
-		if err := opts.Parse(f.Addr().Interface(), params); err != nil {
-			return fmt.Errorf("opts.Parse(%s, %v): %w", name, params, err)
+// Path extends [routey.Path] by decoding the value
+// using the label and matrix param styles.
+type Path[T any] struct {
+	routey.Path[T]
+}
+
+var _ extractor.ParamExtractor = &Path[string]{}
+
+func (p *Path[T]) CanParse(
+	parser param.Parser,
+	source reflect.StructField,
+	value any,
+) error {
+	if parseable(parser, value) {
+		return nil
+	}
+
+	style, err := openAPIParam.GetStyleFromTag(source.Tag)
+	if err != nil {
+		// Return nil here to allow for better error reporting
+		// when openAPIParam.FromInfo is called.
+		//
+		//nolint:nilerr
+		return nil
+	}
+
+	if style != openAPIParam.StyleLabel && style != openAPIParam.StyleMatrix {
+		return param.ErrInvalidParamType
+	}
+
+	return validDeepObjectType(parser, reflect.TypeFor[T](), false)
+}
+
+func (p *Path[T]) Extract(r *http.Request, info *route.Info, opts param.Opts) error {
+	ctx, err := ContextFromCtx(info.Context)
+	if err != nil {
+		return fmt.Errorf("no context: %w", err)
+	}
+
+	op, err := opFromCtx(ctx, info)
+	if err != nil {
+		return err
+	}
+
+	param, has := op.GetParameter(opts.Name, p.Source())
+	if !has {
+		return fmt.Errorf(
+			"no param found: %s %s: %w",
+			info.Method,
+			info.FullPattern,
+			extractor.ErrParamFailedToExtract,
+		)
+	}
+
+	return p.parse(opts.PathValue(opts.Name, r), opts, param, ctx, fieldValidatorFromCtx(info.Context))
+}
+
+func (p *Path[T]) parse(
+	value string,
+	opts param.Opts,
+	pathParam openAPIParam.Parameter,
+	ctx Context,
+	fieldValidator *jsonschema.Validator,
+) error {
+	var err error
+
+	switch style := openAPIParam.Style(pathParam.Style); style {
+	case openAPIParam.StyleLabel, openAPIParam.StyleMatrix:
+		err = p.parseStyled(value, opts, pathParam, ctx.OpenAPI, fieldValidator)
+	default:
+		if err = opts.Parse(&p.Value, []string{value}); err != nil {
+			err = extractor.ParamError(err)
 		}
 	}
 
+	if err != nil || ctx.Validator == nil {
+		return err
+	}
+
+	return validateSchema(pathParam.Name, pathParam.In, ctx.Validator, &p.Value)
+}
+
+func (p *Path[T]) parseStyled(
+	value string,
+	opts param.Opts,
+	pathParam openAPIParam.Parameter,
+	spec *OpenAPI,
+	fieldValidator *jsonschema.Validator,
+) error {
+	kind := valueKindOf(opts.Parser, &p.Value)
+	params, err := styledPathValues(openAPIParam.Style(pathParam.Style), value, pathParam, kind)
+	if err != nil {
+		return extractor.ParamError(err)
+	}
+
+	if kind == kindObject {
+		if len(params)%2 != 0 {
+			err := fmt.Errorf("%w: %q has a key without a value", ErrInvalidStyleValue, value)
+			return extractor.ParamError(err)
+		}
+
+		fields := map[string][]string{}
+		for i := 0; i < len(params); i += 2 {
+			fields[params[i]] = append(fields[params[i]], params[i+1])
+		}
+
+		return parseFields(&p.Value, opts, pathParam, spec, fieldValidator, func(names ...string) (string, []string) {
+			field := names[len(names)-1]
+			return pathParam.Name + "." + field, fields[field]
+		})
+	}
+
+	if err := opts.Parse(&p.Value, params); err != nil {
+		return extractor.ParamError(err)
+	}
 	return nil
 }
 
-func validateSchema(name string, validator *jsonschema.Validator, value any) error {
-	loc := "#/parameters/query/" + name
-	name = "param." + name
+type valueKind int
+
+const (
+	kindPrimitive valueKind = iota
+	kindArray
+	kindObject
+)
+
+// valueKindOf returns how the value pointed to by ptr is encoded in a param.
+func valueKindOf(parser param.Parser, ptr any) valueKind {
+	switch reflect.TypeOf(ptr).Elem().Kind() {
+	case reflect.Slice, reflect.Array:
+		return kindArray
+	case reflect.Struct:
+		if !parseable(parser, ptr) {
+			return kindObject
+		}
+	}
+	return kindPrimitive
+}
+
+// styledPathValues returns the values of a label or matrix style path value.
+// Objects are returned as alternating keys and values.
+//
+// https://spec.openapis.org/oas/v3.1.0#style-examples
+func styledPathValues(
+	style openAPIParam.Style,
+	value string,
+	p openAPIParam.Parameter,
+	kind valueKind,
+) ([]string, error) {
+	if style == openAPIParam.StyleLabel {
+		label, ok := strings.CutPrefix(value, ".")
+		switch {
+		case !ok:
+			return nil, fmt.Errorf("%w: %q missing . prefix", ErrInvalidStyleValue, value)
+		case kind == kindPrimitive:
+			return []string{label}, nil
+		case !p.Explode:
+			return strings.Split(label, ","), nil
+		case kind == kindObject:
+			return splitPairs(strings.Split(label, "."))
+		}
+		return strings.Split(label, "."), nil
+	}
+
+	matrix, ok := strings.CutPrefix(value, ";")
+	if !ok {
+		return nil, fmt.Errorf("%w: %q missing ; prefix", ErrInvalidStyleValue, value)
+	}
+
+	items := strings.Split(matrix, ";")
+	if p.Explode && kind == kindObject {
+		return splitPairs(items)
+	}
+
+	var values []string
+	for _, item := range items {
+		name, v, _ := strings.Cut(item, "=")
+		switch {
+		case name != p.Name:
+			return nil, fmt.Errorf("%w: %q is not named %s", ErrInvalidStyleValue, item, p.Name)
+		case kind == kindPrimitive:
+			return []string{v}, nil
+		case p.Explode:
+			values = append(values, v)
+		default:
+			values = append(values, strings.Split(v, ",")...)
+		}
+	}
+	return values, nil
+}
+
+// splitPairs splits each key=value item into its key and value.
+func splitPairs(items []string) ([]string, error) {
+	pairs := make([]string, 0, len(items)*2)
+	for _, item := range items {
+		key, value, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: %q is not key=value", ErrInvalidStyleValue, item)
+		}
+		pairs = append(pairs, key, value)
+	}
+	return pairs, nil
+}
+
+// validateField validates the value of the field against the schema compiled
+// for it by [compileFieldSchemas], reporting errors at the param.
+func validateField(p openAPIParam.Parameter, fields []string, validator *jsonschema.Validator, value any) error {
+	name := fieldSchemaName(p, fields)
+	if validator == nil || !validator.Has(name) {
+		return nil
+	}
+
+	loc := "#/parameters/" + p.In + "/" + p.Name
+	return validateValue(name, loc, validator, value)
+}
+
+func validateSchema(name, in string, validator *jsonschema.Validator, value any) error {
+	return validateValue("param."+name, "#/parameters/"+in+"/"+name, validator, value)
+}
+
+// validateValue validates value against the schema added to the validator
+// with the name, setting loc as the location of validation errors.
+func validateValue(name, loc string, validator *jsonschema.Validator, value any) error {
+	if d, ok := value.(*time.Duration); ok {
+		// validated as the string it was parsed from, not as nanoseconds
+		value = d.String()
+	}
+
 	b, err := json.Marshal(value)
 
 	if err != nil {
@@ -312,7 +787,7 @@ func validateSchema(name string, validator *jsonschema.Validator, value any) err
 
 	if errors.As(err, &want) {
 		want.Location = loc
-		return want
+		return extractor.WithStatusCode(want, http.StatusBadRequest)
 	}
 
 	return err
@@ -81,6 +81,33 @@ func TestQuery_DeepObject(t *testing.T) {
 	}
 }
 
+func TestQuery_DeepObjectNested(t *testing.T) {
+	type Range struct {
+		Min int `json:"min"`
+		Max int `json:"max"`
+	}
+	type Object struct {
+		Name  string `json:"name"`
+		Range Range  `json:"range"`
+	}
+
+	values := url.Values{}
+	values.Add("obj[name]", "a")
+	values.Add("obj[range][min]", "1")
+	values.Add("obj[range][max]", "5")
+
+	p := openapi3.NewParameter()
+	p.Name = "obj"
+	p.Style = string(openapiParam.StyleDeepObject)
+	p.In = string(openapiParam.LocationQuery)
+
+	parse := newParamTester(t, p, values)
+	q := openapi3.Query[Object]{}
+	parse(&q, param.Opts{})
+
+	test.Equal(t, q.Value, Object{Name: "a", Range: Range{Min: 1, Max: 5}})
+}
+
 func TestQuery_FormSlice(t *testing.T) {
 	name := "obj"
 	want := []string{"a", "b"}
@@ -100,6 +127,44 @@ func TestQuery_FormSlice(t *testing.T) {
 	test.MatchAsJSON(t, got, want)
 }
 
+func TestQuery_SpaceDelimitedSlice(t *testing.T) {
+	name := "obj"
+	want := []string{"a", "b"}
+	values := url.Values{}
+	values.Add(name, strings.Join(want, " "))
+
+	p := openapi3.NewParameter()
+	p.Name = name
+	p.Style = string(openapiParam.StyleSpaceDelimited)
+	p.In = string(openapiParam.LocationQuery)
+
+	parse := newParamTester(t, p, values)
+	q := openapi3.Query[[]string]{}
+	parse(&q, param.Opts{})
+
+	got := q.Value
+	test.MatchAsJSON(t, got, want)
+}
+
+func TestQuery_PipeDelimitedSlice(t *testing.T) {
+	name := "obj"
+	want := []string{"a", "b"}
+	values := url.Values{}
+	values.Add(name, strings.Join(want, "|"))
+
+	p := openapi3.NewParameter()
+	p.Name = name
+	p.Style = string(openapiParam.StylePipeDelimited)
+	p.In = string(openapiParam.LocationQuery)
+
+	parse := newParamTester(t, p, values)
+	q := openapi3.Query[[]string]{}
+	parse(&q, param.Opts{})
+
+	got := q.Value
+	test.MatchAsJSON(t, got, want)
+}
+
 func TestQuery_FormExplodeSlice(t *testing.T) {
 	name := "obj"
 	want := []string{"a", "b"}
@@ -203,3 +268,144 @@ func TestQuery_UnparsableDeepObjectDefaultValue(t *testing.T) {
 		t.Error("expected error, got none")
 	}
 }
+
+type pathValue string
+
+func (p pathValue) Param(string, *http.Request) string { return string(p) }
+
+func TestPath_StyledValues(t *testing.T) {
+	type Color struct {
+		R int `json:"r"`
+		G int `json:"g"`
+	}
+
+	tests := []struct {
+		name    string
+		style   openapiParam.Style
+		explode bool
+		value   string
+		parse   func(func(extractor.ParamExtractor, param.Opts), param.Opts) any
+		want    string
+	}{
+		{
+			name:  "label primitive",
+			style: openapiParam.StyleLabel,
+			value: ".5",
+			parse: parsePath[int],
+			want:  `5`,
+		},
+		{
+			name:  "label array",
+			style: openapiParam.StyleLabel,
+			value: ".red,green",
+			parse: parsePath[[]string],
+			want:  `["red", "green"]`,
+		},
+		{
+			name:    "label exploded array",
+			style:   openapiParam.StyleLabel,
+			explode: true,
+			value:   ".red.green",
+			parse:   parsePath[[]string],
+			want:    `["red", "green"]`,
+		},
+		{
+			name:  "label object",
+			style: openapiParam.StyleLabel,
+			value: ".r,100,g,200",
+			parse: parsePath[Color],
+			want:  `{"r": 100, "g": 200}`,
+		},
+		{
+			name:    "label exploded object",
+			style:   openapiParam.StyleLabel,
+			explode: true,
+			value:   ".r=100.g=200",
+			parse:   parsePath[Color],
+			want:    `{"r": 100, "g": 200}`,
+		},
+		{
+			name:  "matrix primitive",
+			style: openapiParam.StyleMatrix,
+			value: ";color=5",
+			parse: parsePath[int],
+			want:  `5`,
+		},
+		{
+			name:  "matrix array",
+			style: openapiParam.StyleMatrix,
+			value: ";color=red,green",
+			parse: parsePath[[]string],
+			want:  `["red", "green"]`,
+		},
+		{
+			name:    "matrix exploded array",
+			style:   openapiParam.StyleMatrix,
+			explode: true,
+			value:   ";color=red;color=green",
+			parse:   parsePath[[]string],
+			want:    `["red", "green"]`,
+		},
+		{
+			name:  "matrix object",
+			style: openapiParam.StyleMatrix,
+			value: ";color=r,100,g,200",
+			parse: parsePath[Color],
+			want:  `{"r": 100, "g": 200}`,
+		},
+		{
+			name:    "matrix exploded object",
+			style:   openapiParam.StyleMatrix,
+			explode: true,
+			value:   ";r=100;g=200",
+			parse:   parsePath[Color],
+			want:    `{"r": 100, "g": 200}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := openapi3.NewParameter()
+			p.Name = "color"
+			p.Style = string(tt.style)
+			p.Explode = tt.explode
+			p.In = string(openapiParam.LocationPath)
+
+			parse := newParamTester(t, p, url.Values{})
+			got := tt.parse(parse, param.Opts{Pather: pathValue(tt.value)})
+			test.MatchAsJSON(t, got, tt.want)
+		})
+	}
+}
+
+func parsePath[T any](parse func(extractor.ParamExtractor, param.Opts), opts param.Opts) any {
+	p := openapi3.Path[T]{}
+	parse(&p, opts)
+	return p.Value
+}
+
+func TestPath_InvalidStyledValue(t *testing.T) {
+	p := openapi3.NewParameter()
+	p.Name = "color"
+	p.Style = string(openapiParam.StyleMatrix)
+	p.In = string(openapiParam.LocationPath)
+
+	r, spec := openapi3.NewRouter()
+	op := openapi3.NewOperation()
+	op.AddParameter(p)
+
+	pathItem := openapi3.NewPathItem()
+	pathItem.SetOperation(http.MethodGet, op)
+	spec.SetPath("/", pathItem)
+
+	info := route.Info{FullPattern: "/", Method: http.MethodGet, Context: r.Context}
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/", nil)
+
+	path := openapi3.Path[int]{}
+	err := path.Extract(req, &info, param.Opts{
+		Name:   p.Name,
+		Parser: r.Params.Parser,
+		Pather: pathValue(";other=5"),
+	})
+	test.IsError(t, err, openapi3.ErrInvalidStyleValue)
+}
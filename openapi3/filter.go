@@ -0,0 +1,188 @@
+package openapi3
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/sv-tools/openapi"
+	"github.com/zhamlin/routey"
+)
+
+// SpecForPrefix returns a copy of the spec added to the router by [AddSpecToRouter],
+// containing only the paths equal to or nested under prefix.
+func SpecForPrefix(r *routey.Router, prefix string) (*OpenAPI, error) {
+	ctx, err := ContextFromCtx(r.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix = strings.TrimRight(prefix, "/")
+	return ctx.OpenAPI.FilterPaths(func(path string) bool {
+		return path == prefix || strings.HasPrefix(path, prefix+"/")
+	})
+}
+
+// FilterPaths returns a copy of the spec containing only the paths keep returns
+// true for. Components are limited to the ones referenced by the kept paths,
+// except for security schemes which are referenced by name.
+func (o OpenAPI) FilterPaths(keep func(path string) bool) (*OpenAPI, error) {
+	spec := *o.OpenAPI
+	filtered := o
+	filtered.OpenAPI = &spec
+
+	if o.Paths != nil {
+		paths := *o.Paths.Spec
+		paths.Paths = map[string]*openapi.RefOrSpec[openapi.Extendable[openapi.PathItem]]{}
+
+		for name, path := range o.Paths.Spec.Paths {
+			if keep(name) {
+				paths.Paths[name] = path
+			}
+		}
+
+		spec.Paths = openapi.NewExtendable(&paths)
+		spec.Paths.Extensions = o.Paths.Extensions
+	}
+
+	if o.Components == nil {
+		return &filtered, nil
+	}
+
+	refs, err := referencedComponents(spec.Paths, o.Components.Spec)
+	if err != nil {
+		return nil, err
+	}
+
+	c := o.Components.Spec
+	components := openapi.Components{
+		Schemas:         filterComponents(c.Schemas, "schemas", refs),
+		Responses:       filterComponents(c.Responses, "responses", refs),
+		Parameters:      filterComponents(c.Parameters, "parameters", refs),
+		Examples:        filterComponents(c.Examples, "examples", refs),
+		RequestBodies:   filterComponents(c.RequestBodies, "requestBodies", refs),
+		Headers:         filterComponents(c.Headers, "headers", refs),
+		SecuritySchemes: c.SecuritySchemes,
+		Links:           filterComponents(c.Links, "links", refs),
+		Callbacks:       filterComponents(c.Callbacks, "callbacks", refs),
+		Paths:           filterComponents(c.Paths, "paths", refs),
+	}
+
+	spec.Components = openapi.NewExtendable(&components)
+	spec.Components.Extensions = o.Components.Extensions
+	return &filtered, nil
+}
+
+const componentsRefPrefix = "#/components/"
+
+func filterComponents[T any](items map[string]T, kind string, refs map[string]bool) map[string]T {
+	if items == nil {
+		return nil
+	}
+
+	filtered := map[string]T{}
+	for name, item := range items {
+		if refs[componentsRefPrefix+kind+"/"+name] {
+			filtered[name] = item
+		}
+	}
+	return filtered
+}
+
+func lookupComponent[T any](items map[string]T, name string) (any, bool) {
+	item, has := items[name]
+	return item, has
+}
+
+func componentFromRef(c *openapi.Components, ref string) (any, bool) {
+	ref, isComponent := strings.CutPrefix(ref, componentsRefPrefix)
+	if !isComponent {
+		return nil, false
+	}
+
+	kind, name, _ := strings.Cut(ref, "/")
+	switch kind {
+	case "schemas":
+		return lookupComponent(c.Schemas, name)
+	case "responses":
+		return lookupComponent(c.Responses, name)
+	case "parameters":
+		return lookupComponent(c.Parameters, name)
+	case "examples":
+		return lookupComponent(c.Examples, name)
+	case "requestBodies":
+		return lookupComponent(c.RequestBodies, name)
+	case "headers":
+		return lookupComponent(c.Headers, name)
+	case "links":
+		return lookupComponent(c.Links, name)
+	case "callbacks":
+		return lookupComponent(c.Callbacks, name)
+	case "paths":
+		return lookupComponent(c.Paths, name)
+	}
+	return nil, false
+}
+
+// referencedComponents returns all component refs reachable from value,
+// including refs used by the referenced components.
+func referencedComponents(value any, c *openapi.Components) (map[string]bool, error) {
+	refs := map[string]bool{}
+	pending := []any{value}
+
+	for len(pending) > 0 {
+		v := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+
+		found, err := findRefs(v)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, ref := range found {
+			if refs[ref] {
+				continue
+			}
+			refs[ref] = true
+
+			if component, has := componentFromRef(c, ref); has {
+				pending = append(pending, component)
+			}
+		}
+	}
+
+	return refs, nil
+}
+
+func findRefs(value any) ([]string, error) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var decoded any
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		return nil, err
+	}
+
+	var refs []string
+	var walk func(any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			for key, value := range v {
+				if ref, ok := value.(string); ok && key == "$ref" {
+					refs = append(refs, ref)
+					continue
+				}
+				walk(value)
+			}
+		case []any:
+			for _, value := range v {
+				walk(value)
+			}
+		}
+	}
+
+	walk(decoded)
+	return refs, nil
+}
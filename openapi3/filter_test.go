@@ -0,0 +1,104 @@
+package openapi3_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/zhamlin/routey"
+	"github.com/zhamlin/routey/internal/test"
+	"github.com/zhamlin/routey/openapi3"
+	"github.com/zhamlin/routey/openapi3/option"
+)
+
+func TestSpecForPrefix(t *testing.T) {
+	type Address struct {
+		City string `json:"city"`
+	}
+	type UserV1 struct {
+		Name string `json:"name"`
+	}
+	type UserV2 struct {
+		Name    string  `json:"name"`
+		Address Address `json:"address"`
+	}
+
+	h := func(struct{}) (any, error) { return nil, nil }
+	r, spec := newTestRouter(t)
+
+	routey.Get(r, "/v1/users", h,
+		option.ID("usersV1"),
+		option.Response[UserV1](http.StatusOK, "ok"),
+	)
+	routey.Get(r, "/v2/users", h,
+		option.ID("usersV2"),
+		option.Response[UserV2](http.StatusOK, "ok"),
+	)
+
+	v1, err := openapi3.SpecForPrefix(r, "/v1")
+	test.NoError(t, err)
+
+	v2, err := openapi3.SpecForPrefix(r, "/v2/")
+	test.NoError(t, err)
+
+	test.MatchAsJSON(t, v1.Paths, `
+	{
+	  "/v1/users": {
+		"get": {
+		  "operationId": "usersV1",
+		  "responses": {
+			"200": {
+			  "description": "ok",
+			  "content": {
+				"application/json": {
+				  "schema": {"$ref": "#/components/schemas/UserV1"}
+				}
+			  }
+			}
+		  }
+		}
+	  }
+	}
+	`)
+
+	test.MatchAsJSON(t, v1.Components, `
+	{
+	  "schemas": {
+		"UserV1": {
+		  "type": "object",
+		  "properties": {
+			"name": {"type": "string"}
+		  }
+		}
+	  }
+	}
+	`)
+
+	test.MatchAsJSON(t, v2.Components, `
+	{
+	  "schemas": {
+		"Address": {
+		  "type": "object",
+		  "properties": {
+			"city": {"type": "string"}
+		  }
+		},
+		"UserV2": {
+		  "type": "object",
+		  "properties": {
+			"address": {"$ref": "#/components/schemas/Address"},
+			"name": {"type": "string"}
+		  }
+		}
+	  }
+	}
+	`)
+
+	// the original spec is unchanged
+	test.Equal(t, len(spec.Paths.Spec.Paths), 2)
+	test.Equal(t, len(spec.Components.Spec.Schemas), 3)
+}
+
+func TestSpecForPrefix_NoSpec(t *testing.T) {
+	_, err := openapi3.SpecForPrefix(routey.New(), "/v1")
+	test.IsError(t, err, openapi3.ErrNoContext)
+}
@@ -0,0 +1,239 @@
+package openapi3
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/sv-tools/openapi"
+)
+
+// LintFinding is an issue with the style of a spec found by [OpenAPI.Lint].
+type LintFinding struct {
+	// Rule is the name of the rule reporting the finding.
+	Rule string
+	// Operation the finding belongs to, formatted as "METHOD /path".
+	Operation string
+	// Location of the finding within the operation, such as
+	// "parameters.query.id". Empty when it is the operation itself.
+	Location string
+	Message  string
+}
+
+func (f LintFinding) String() string {
+	name := f.Operation
+	if f.Location != "" {
+		name += " " + f.Location
+	}
+	return fmt.Sprintf("[%s] %s: %s", f.Rule, name, f.Message)
+}
+
+// LintOperation is an operation of the spec checked by a [LintRule].
+type LintOperation struct {
+	Operation
+
+	Method string
+	Path   string
+}
+
+func (o LintOperation) finding(location, format string, args ...any) LintFinding {
+	return LintFinding{
+		Operation: o.Method + " " + o.Path,
+		Location:  location,
+		Message:   fmt.Sprintf(format, args...),
+	}
+}
+
+// LintRule checks the operations of a spec for issues.
+type LintRule struct {
+	Name string
+	// Check returns the findings for the operations, which are sorted by path.
+	// The Rule of each finding is set to the name of the rule.
+	Check func(ops []LintOperation) []LintFinding
+}
+
+// OperationLintRule returns a [LintRule] checking each operation on its own.
+func OperationLintRule(name string, check func(LintOperation) []LintFinding) LintRule {
+	return LintRule{
+		Name: name,
+		Check: func(ops []LintOperation) []LintFinding {
+			var findings []LintFinding
+			for _, op := range ops {
+				findings = append(findings, check(op)...)
+			}
+			return findings
+		},
+	}
+}
+
+// DefaultLintRules returns the rules used by [OpenAPI.Lint] when none are provided.
+func DefaultLintRules() []LintRule {
+	return []LintRule{
+		LintDescriptions,
+		LintResponses,
+		LintTags,
+		LintParamNaming,
+	}
+}
+
+// LintDescriptions reports operations without a summary or description,
+// and parameters without a description on either the parameter or its schema.
+var LintDescriptions = OperationLintRule("descriptions", func(op LintOperation) []LintFinding {
+	var findings []LintFinding
+	if op.Summary == "" && op.Description == "" {
+		findings = append(findings, op.finding("", "missing summary or description"))
+	}
+
+	for _, p := range op.Parameters {
+		if p.Spec == nil || hasParamDescription(p.Spec.Spec) {
+			continue
+		}
+
+		location := "parameters." + p.Spec.Spec.In + "." + p.Spec.Spec.Name
+		findings = append(findings, op.finding(location, "missing description"))
+	}
+	return findings
+})
+
+func hasParamDescription(p *openapi.Parameter) bool {
+	if p.Description != "" {
+		return true
+	}
+	return p.Schema != nil && p.Schema.Spec != nil && p.Schema.Spec.Description != ""
+}
+
+// LintResponses reports operations without any responses.
+var LintResponses = OperationLintRule("responses", func(op LintOperation) []LintFinding {
+	if r := op.Responses; r != nil && r.Spec != nil {
+		if len(r.Spec.Response) > 0 || r.Spec.Default != nil {
+			return nil
+		}
+	}
+	return []LintFinding{op.finding("", "no responses")}
+})
+
+// LintTags reports operations without any tags.
+var LintTags = OperationLintRule("tags", func(op LintOperation) []LintFinding {
+	if len(op.Tags) > 0 {
+		return nil
+	}
+	return []LintFinding{op.finding("", "no tags")}
+})
+
+type nameCase string
+
+const (
+	caseNone  nameCase = ""
+	caseSnake nameCase = "snake_case"
+	caseCamel nameCase = "camelCase"
+	caseKebab nameCase = "kebab-case"
+)
+
+// paramNameCase returns the case of the name, or caseNone when
+// the name is a single word that matches any case.
+func paramNameCase(name string) nameCase {
+	switch {
+	case strings.Contains(name, "_"):
+		return caseSnake
+	case strings.Contains(name, "-"):
+		return caseKebab
+	case strings.IndexFunc(name, unicode.IsUpper) > 0:
+		return caseCamel
+	}
+	return caseNone
+}
+
+// LintParamNaming reports path and query parameters using a different case,
+// such as snake_case or camelCase, than the majority of the parameters in the spec.
+var LintParamNaming = LintRule{
+	Name: "param-naming",
+	Check: func(ops []LintOperation) []LintFinding {
+		type namedParam struct {
+			op       LintOperation
+			location string
+			nameCase nameCase
+		}
+
+		var params []namedParam
+		counts := map[nameCase]int{}
+
+		for _, op := range ops {
+			for _, p := range op.Parameters {
+				if p.Spec == nil {
+					continue
+				}
+
+				spec := p.Spec.Spec
+				c := paramNameCase(spec.Name)
+				if c == caseNone || (spec.In != "path" && spec.In != "query") {
+					continue
+				}
+
+				counts[c]++
+				params = append(params, namedParam{
+					op:       op,
+					location: "parameters." + spec.In + "." + spec.Name,
+					nameCase: c,
+				})
+			}
+		}
+
+		if len(counts) < 2 {
+			return nil
+		}
+
+		// ties are broken by the case used first
+		var majority nameCase
+		for _, p := range params {
+			if counts[p.nameCase] > counts[majority] {
+				majority = p.nameCase
+			}
+		}
+
+		var findings []LintFinding
+		for _, p := range params {
+			if p.nameCase != majority {
+				findings = append(findings, p.op.finding(p.location, "uses %s instead of %s", p.nameCase, majority))
+			}
+		}
+		return findings
+	},
+}
+
+// Lint checks the operations of the spec with the rules, returning the
+// findings of each rule in order. [DefaultLintRules] are used if no rules
+// are provided.
+func (o OpenAPI) Lint(rules ...LintRule) []LintFinding {
+	if len(rules) == 0 {
+		rules = DefaultLintRules()
+	}
+
+	var ops []LintOperation
+	if o.Paths != nil {
+		for _, path := range slices.Sorted(maps.Keys(o.Paths.Spec.Paths)) {
+			item, has := o.GetPath(path)
+			if !has {
+				continue
+			}
+
+			for _, op := range item.GetOperations() {
+				ops = append(ops, LintOperation{
+					Operation: op.Operation,
+					Method:    op.Method,
+					Path:      path,
+				})
+			}
+		}
+	}
+
+	var findings []LintFinding
+	for _, rule := range rules {
+		for _, f := range rule.Check(ops) {
+			f.Rule = rule.Name
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
@@ -0,0 +1,113 @@
+package openapi3_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/zhamlin/routey"
+	"github.com/zhamlin/routey/internal/test"
+	"github.com/zhamlin/routey/openapi3"
+	"github.com/zhamlin/routey/openapi3/option"
+	"github.com/zhamlin/routey/route"
+)
+
+func tags(names ...string) route.Option {
+	return option.New(func(_ *option.Context, o *openapi3.Operation) error {
+		o.Tags = names
+		return nil
+	})
+}
+
+func TestLint_DefaultRules(t *testing.T) {
+	type listInput struct {
+		PageSize routey.Query[int] `description:"number of users"`
+	}
+	type getInput struct {
+		UserID routey.Path[int] `name:"userId" description:"id of the user"`
+	}
+	h := func(struct{}) (any, error) { return nil, nil }
+
+	r, spec := newTestRouter(t)
+	routey.Get(r, "/users", func(listInput) (any, error) { return nil, nil },
+		option.ID("listUsers"),
+		option.Summary("List users"),
+		option.Response[None](http.StatusOK, "users"),
+		tags("users"),
+	)
+	routey.Get(r, "/users/{userId}", func(getInput) (any, error) { return nil, nil },
+		option.ID("getUser"),
+		option.Summary("Get a user"),
+		option.Response[None](http.StatusOK, "user"),
+		tags("users"),
+	)
+	routey.Get(r, "/health", h, option.ID("health"))
+
+	test.MatchAsJSON(t, spec.Lint(), []openapi3.LintFinding{
+		{
+			Rule:      "descriptions",
+			Operation: "GET /health",
+			Message:   "missing summary or description",
+		},
+		{
+			Rule:      "responses",
+			Operation: "GET /health",
+			Message:   "no responses",
+		},
+		{
+			Rule:      "tags",
+			Operation: "GET /health",
+			Message:   "no tags",
+		},
+		{
+			Rule:      "param-naming",
+			Operation: "GET /users/{userId}",
+			Location:  "parameters.path.userId",
+			Message:   "uses camelCase instead of snake_case",
+		},
+	})
+}
+
+func TestLint_ParamDescription(t *testing.T) {
+	type input struct {
+		Page routey.Query[int]
+	}
+
+	r, spec := newTestRouter(t)
+	routey.Get(r, "/users", func(input) (any, error) { return nil, nil },
+		option.ID("listUsers"),
+		option.Summary("List users"),
+	)
+
+	test.MatchAsJSON(t, spec.Lint(openapi3.LintDescriptions), []openapi3.LintFinding{
+		{
+			Rule:      "descriptions",
+			Operation: "GET /users",
+			Location:  "parameters.query.page",
+			Message:   "missing description",
+		},
+	})
+}
+
+func TestLint_CustomRule(t *testing.T) {
+	h := func(struct{}) (any, error) { return nil, nil }
+
+	r, spec := newTestRouter(t)
+	routey.Get(r, "/users", h, option.ID("listUsers"))
+	routey.Get(r, "/posts", h, option.ID("posts"))
+
+	verbPrefix := openapi3.OperationLintRule("operation-id", func(op openapi3.LintOperation) []openapi3.LintFinding {
+		if op.OperationID == "posts" {
+			return []openapi3.LintFinding{{Message: "operation id should start with a verb"}}
+		}
+		return nil
+	})
+
+	test.MatchAsJSON(t, spec.Lint(verbPrefix), []openapi3.LintFinding{
+		{
+			Rule:    "operation-id",
+			Message: "operation id should start with a verb",
+		},
+	})
+}
+
+type None = option.None
@@ -1,13 +1,18 @@
 package openapi3
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"net/http"
 	"reflect"
+	"slices"
 	"strconv"
 
 	"github.com/sv-tools/openapi"
+	"github.com/zhamlin/routey/internal"
 	"github.com/zhamlin/routey/jsonschema"
 	"github.com/zhamlin/routey/openapi3/param"
 )
@@ -21,12 +26,76 @@ func RegisterType[T any](spec *OpenAPI, schema jsonschema.Schema, opts ...jsonsc
 	return err
 }
 
-func SetDefaultResponse[T any](spec *OpenAPI, code int, contentType ...string) {
+// RegisterInterface sets the schema of T to be one of the types in mapping,
+// using the property as the discriminator. Each key in mapping is the value
+// of the property for the type.
+//
+// The property is required when validating requests: a payload missing it
+// fails validation instead of being checked against each type.
+func RegisterInterface[T any](spec *OpenAPI, property string, mapping map[string]reflect.Type) error {
+	typ := reflect.TypeFor[T]()
+	if _, err := spec.Schemer.SetOneOf(typ, property, mapping); err != nil {
+		return err
+	}
+
+	_, err := spec.GetSchemaOrRef(typ, SchemaRefOptions{})
+	return err
+}
+
+// ErrorResponse is the response documented for an error type,
+// see [RegisterErrorResponse].
+type ErrorResponse struct {
+	Code     int
+	Response Response
+}
+
+// RegisterErrorResponse registers the response documented for the error type T
+// by operations using option.MayReturn. The response body is the schema of T
+// for each content type, or the default content type when there are none.
+func RegisterErrorResponse[T any](spec *OpenAPI, code int, desc string, contentType ...string) error {
 	if len(contentType) == 0 {
 		contentType = []string{spec.DefaultContentType}
 	}
 
 	typ := reflect.TypeFor[T]()
+	v, err := spec.GetSchemaOrRef(typ, SchemaRefOptions{
+		IgnoreAddSchemaErrors: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	mt := NewMediaType()
+	mt.Schema = v
+
+	resp := Response{}
+	resp.Description = desc
+	for _, ct := range contentType {
+		resp.SetContent(ct, mt)
+	}
+
+	if spec.errorResponses == nil {
+		spec.errorResponses = map[reflect.Type]ErrorResponse{}
+	}
+	spec.errorResponses[typ] = ErrorResponse{Code: code, Response: resp}
+	return nil
+}
+
+// GetErrorResponse returns the response registered for the error type.
+func (o OpenAPI) GetErrorResponse(typ reflect.Type) (ErrorResponse, bool) {
+	resp, has := o.errorResponses[typ]
+	return resp, has
+}
+
+func SetDefaultResponse[T any](spec *OpenAPI, code int, contentType ...string) {
+	setDefaultResponse(spec, reflect.TypeFor[T](), code, contentType...)
+}
+
+func setDefaultResponse(spec *OpenAPI, typ reflect.Type, code int, contentType ...string) {
+	if len(contentType) == 0 {
+		contentType = []string{spec.DefaultContentType}
+	}
+
 	v, err := spec.GetSchemaOrRef(typ, SchemaRefOptions{
 		IgnoreAddSchemaErrors: true,
 	})
@@ -91,7 +160,11 @@ func NewTag() Tag {
 	return Tag{tag}
 }
 
-const JSONContentType = "application/json"
+const (
+	JSONContentType = "application/json"
+	XMLContentType  = "application/xml"
+	FormContentType = "application/x-www-form-urlencoded"
+)
 
 type OpenAPI struct {
 	*openapi.OpenAPI
@@ -99,6 +172,14 @@ type OpenAPI struct {
 	Schemer            jsonschema.Schemer `json:"-"`
 	DefaultContentType string             `json:"-"`
 	Strict             bool               `json:"-"`
+	// DocumentAutoHead adds HEAD operations for routes created
+	// by [routey.Router.AutoHead].
+	DocumentAutoHead bool `json:"-"`
+
+	// context added to routers by [AddSpecToRouter]
+	routerCtx *Context
+	// responses registered by [RegisterErrorResponse]
+	errorResponses map[reflect.Type]ErrorResponse
 }
 
 func (o OpenAPI) GetComponents() Components {
@@ -128,6 +209,80 @@ func (o OpenAPI) GetDefaultResponse(code int) (Response, bool) {
 	return o.GetComponents().GetResponse(name)
 }
 
+// MarshalOptions configures how [OpenAPI.MarshalWith] marshals the spec.
+type MarshalOptions struct {
+	// Indent is used for each level of indentation.
+	// The output is compact when empty.
+	Indent string
+	// OmitEmpty removes top level fields, and fields of the components,
+	// that are empty objects or arrays.
+	OmitEmpty bool
+}
+
+func isEmptyJSON(raw json.RawMessage) bool {
+	switch string(bytes.TrimSpace(raw)) {
+	case "{}", "[]", "null":
+		return true
+	}
+	return false
+}
+
+// omitEmptyFields removes any fields from the json object that are empty.
+// Fields matching nested will have their empty fields removed first.
+func omitEmptyFields(b []byte, nested ...string) ([]byte, error) {
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+
+	for name, value := range fields {
+		if slices.Contains(nested, name) {
+			value, err := omitEmptyFields(value)
+			if err != nil {
+				return nil, err
+			}
+			fields[name] = value
+		}
+
+		if isEmptyJSON(fields[name]) {
+			delete(fields, name)
+		}
+	}
+
+	return json.Marshal(fields)
+}
+
+// MarshalWith returns the json encoding of the spec using the provided options.
+func (o OpenAPI) MarshalWith(opts MarshalOptions) ([]byte, error) {
+	b, err := json.Marshal(o.OpenAPI)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.OmitEmpty {
+		if b, err = omitEmptyFields(b, "components"); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.Indent != "" {
+		buf := &bytes.Buffer{}
+		if err := json.Indent(buf, b, "", opts.Indent); err != nil {
+			return nil, err
+		}
+		b = buf.Bytes()
+	}
+
+	return b, nil
+}
+
+// Hash returns a stable hex encoded hash of the spec, changing only when
+// its json encoding does. It can be used as the ETag of the spec, or to
+// detect changes to it.
+func (o OpenAPI) Hash() (string, error) {
+	return internal.HashJSON(o.OpenAPI)
+}
+
 type Schema struct {
 	*openapi.Schema
 }
@@ -203,7 +358,7 @@ func (p PathItem) GetOperation(method string) (Operation, bool) {
 
 	var op Operation
 	if o != nil {
-		op = Operation{Operation: o.Spec}
+		op = Operation{Operation: o.Spec, Extensions: o.Extensions}
 	}
 
 	return op, op.Operation != nil
@@ -211,6 +366,7 @@ func (p PathItem) GetOperation(method string) (Operation, bool) {
 
 func (p PathItem) SetOperation(method string, operation Operation) {
 	op := NewExtendable(operation.Operation)
+	op.Extensions = operation.Extensions
 
 	switch method {
 	case http.MethodGet:
@@ -245,21 +401,36 @@ type SchemaRefOptions struct {
 	IgnoreAddSchemaErrors bool
 }
 
-// getRefSchemas iterates through all of the properties on a schema, and recursively
-// finds all schemas that are references.
+// getRefSchemas iterates through all of the properties and composed schemas
+// on a schema, and recursively finds all schemas that are references.
 func getRefSchemas(schema jsonschema.Schema, schemer jsonschema.Schemer) []jsonschema.Schema {
+	return findRefSchemas(schema, schemer, map[string]bool{})
+}
+
+func findRefSchemas(
+	schema jsonschema.Schema,
+	schemer jsonschema.Schemer,
+	seen map[string]bool,
+) []jsonschema.Schema {
 	found := []jsonschema.Schema{}
 
-	for _, prop := range schema.Properties {
-		if ref := prop.Ref; ref != nil {
+	children := slices.Collect(maps.Values(schema.Properties))
+	children = append(children, schema.OneOf...)
+	children = append(children, schema.AnyOf...)
+	children = append(children, schema.AllOf...)
+
+	for _, child := range children {
+		if ref := child.Ref; ref != nil && !seen[ref.Ref] {
 			if schema, ok := schemer.GetSchemaByRef(ref.Ref); ok {
+				seen[ref.Ref] = true
 				found = append(found, schema)
+				found = append(found, findRefSchemas(schema, schemer, seen)...)
 			}
 		}
 
-		if spec := prop.Spec; spec != nil {
+		if spec := child.Spec; spec != nil {
 			schema := jsonschema.Schema{Schema: *spec}
-			found = append(found, getRefSchemas(schema, schemer)...)
+			found = append(found, findRefSchemas(schema, schemer, seen)...)
 		}
 	}
 
@@ -284,10 +455,12 @@ func (o OpenAPI) GetSchemaOrRef(
 		typ = reflect.TypeOf(obj)
 	}
 
-	schema.Extensions = map[string]any{
-		// This _should_ not show up in the schema, as every
-		// schema will have a type specified.
-		"type": reflect.New(typ).Elem().Interface(),
+	// This will not show up in the schema as the type field overrides it,
+	// so skip composition schemas which have no type specified.
+	if schema.Type != nil {
+		schema.Extensions = map[string]any{
+			"type": reflect.New(typ).Elem().Interface(),
+		}
 	}
 
 	c := o.GetComponents()
@@ -341,6 +514,54 @@ func (o OpenAPI) SetPath(name string, pathItem PathItem) {
 	o.Paths.Spec.Paths[name] = openapi.NewRefOrSpec[openapi.Extendable[openapi.PathItem]](item)
 }
 
+// HoistSharedParams moves the params shared by every operation of a path to
+// the path item, documenting them once. Params are shared when they are equal
+// in each operation, and paths with a single operation are left as is.
+func (o OpenAPI) HoistSharedParams() {
+	if o.Paths == nil {
+		return
+	}
+
+	for name := range o.Paths.Spec.Paths {
+		path, has := o.GetPath(name)
+		if !has {
+			continue
+		}
+
+		ops := path.GetOperations()
+		if len(ops) < 2 {
+			continue
+		}
+
+		for _, p := range ops[0].Operation.Parameters {
+			isParam := func(other *openapi.RefOrSpec[openapi.Extendable[openapi.Parameter]]) bool {
+				return reflect.DeepEqual(p, other)
+			}
+			hasParam := func(op PathOperation) bool {
+				return slices.ContainsFunc(op.Operation.Parameters, isParam)
+			}
+
+			if !all(ops[1:], hasParam) {
+				continue
+			}
+
+			path.Parameters = append(path.Parameters, p)
+			for _, op := range ops {
+				op.Operation.Parameters = slices.DeleteFunc(slices.Clone(op.Operation.Parameters), isParam)
+			}
+		}
+	}
+}
+
+func all[T any](items []T, fn func(T) bool) bool {
+	for _, item := range items {
+		if !fn(item) {
+			return false
+		}
+	}
+	return true
+}
+
 func (o OpenAPI) getSchemaSource(src *openapi.RefOrSpec[openapi.Schema]) (Schema, error) {
 	if src == nil {
 		return Schema{}, nil
@@ -394,6 +615,14 @@ func (r *Response) SetContent(typ string, mediaType MediaType) {
 	r.Content[typ] = openapi.NewExtendable(&mediaType.MediaType)
 }
 
+// SetHeader documents a header sent with the response.
+func (r *Response) SetHeader(name string, header openapi.Header) {
+	if r.Headers == nil {
+		r.Headers = map[string]*openapi.RefOrSpec[openapi.Extendable[openapi.Header]]{}
+	}
+	r.Headers[name] = openapi.NewRefOrSpec[openapi.Extendable[openapi.Header]](NewExtendable(&header))
+}
+
 type Parameter = param.Parameter
 
 func NewParameter() param.Parameter {
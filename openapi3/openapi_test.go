@@ -2,9 +2,11 @@ package openapi3_test
 
 import (
 	"net/http"
+	"reflect"
 	"testing"
 	"time"
 
+	"github.com/zhamlin/routey"
 	"github.com/zhamlin/routey/internal/test"
 	"github.com/zhamlin/routey/jsonschema"
 	"github.com/zhamlin/routey/openapi3"
@@ -171,6 +173,35 @@ func TestOpenAPI_RegisterTypeCustomName(t *testing.T) {
 	`)
 }
 
+type Drawing interface {
+	Draw()
+}
+
+func TestOpenAPI_RegisterInterface(t *testing.T) {
+	spec := openapi3.New()
+	err := openapi3.RegisterInterface[Drawing](spec, "kind", map[string]reflect.Type{
+		"circle": reflect.TypeFor[Circle](),
+		"square": reflect.TypeFor[Square](),
+	})
+	test.NoError(t, err)
+
+	test.MatchAsJSON(t, spec.Components.Spec.Schemas["Drawing"], `
+	{
+		"oneOf": [
+			{"$ref": "#/components/schemas/Circle"},
+			{"$ref": "#/components/schemas/Square"}
+		],
+		"discriminator": {
+			"propertyName": "kind",
+			"mapping": {
+				"circle": "#/components/schemas/Circle",
+				"square": "#/components/schemas/Square"
+			}
+		}
+	}
+	`)
+}
+
 func TestOpenAPI_RegisterTypeNoRef(t *testing.T) {
 	spec := openapi3.New()
 	openapi3.RegisterType[time.Time](spec,
@@ -262,3 +293,152 @@ func TestGetSchemaOrRef_NoRefTypes(t *testing.T) {
 	}
 	`)
 }
+
+type Circle struct {
+	Radius int `json:"radius"`
+}
+
+type Square struct {
+	Side int `json:"side"`
+}
+
+type Shape struct {
+	Value any
+}
+
+func (Shape) JSONSchemaOneOf() []reflect.Type {
+	return []reflect.Type{
+		reflect.TypeFor[Circle](),
+		reflect.TypeFor[Square](),
+	}
+}
+
+func TestGetSchemaOrRef_OneOfMembers(t *testing.T) {
+	type Foo struct {
+		Shape Shape `json:"shape"`
+	}
+	spec := openapi3.New()
+	_, err := spec.GetSchemaOrRef(Foo{}, openapi3.SchemaRefOptions{})
+	test.NoError(t, err)
+
+	test.MatchAsJSON(t, spec.Components, `
+	{
+	  "schemas": {
+		"Circle": {
+		  "properties": {
+			"radius": {
+			  "type": "integer"
+			}
+		  },
+		  "type": "object"
+		},
+		"Square": {
+		  "properties": {
+			"side": {
+			  "type": "integer"
+			}
+		  },
+		  "type": "object"
+		},
+		"Shape": {
+		  "oneOf": [
+			{"$ref": "#/components/schemas/Circle"},
+			{"$ref": "#/components/schemas/Square"}
+		  ]
+		},
+		"Foo": {
+		  "properties": {
+			"shape": {
+			  "$ref": "#/components/schemas/Shape"
+			}
+		  },
+		  "type": "object"
+		}
+	  }
+	}
+	`)
+}
+
+func TestOpenAPI_MarshalWithOmitEmpty(t *testing.T) {
+	spec := openapi3.New()
+	spec.GetComponents()
+
+	got, err := spec.MarshalWith(openapi3.MarshalOptions{OmitEmpty: true})
+	test.NoError(t, err)
+
+	test.MatchAsJSON(t, got, `
+	{
+	  "info": {
+		"title": "",
+		"version": ""
+	  },
+	  "openapi": "3.1.1"
+	}
+	`)
+}
+
+func TestOpenAPI_MarshalWithKeepsEmpty(t *testing.T) {
+	spec := openapi3.New()
+	spec.GetComponents()
+
+	got, err := spec.MarshalWith(openapi3.MarshalOptions{})
+	test.NoError(t, err)
+
+	test.MatchAsJSON(t, got, `
+	{
+	  "components": {},
+	  "info": {
+		"title": "",
+		"version": ""
+	  },
+	  "openapi": "3.1.1"
+	}
+	`)
+}
+
+func TestOpenAPI_MarshalWithIndent(t *testing.T) {
+	spec := openapi3.New()
+
+	got, err := spec.MarshalWith(openapi3.MarshalOptions{
+		Indent:    "  ",
+		OmitEmpty: true,
+	})
+	test.NoError(t, err)
+
+	want := `{
+  "info": {
+    "title": "",
+    "version": ""
+  },
+  "openapi": "3.1.1"
+}`
+	test.Equal(t, string(got), want)
+}
+
+func TestOpenAPI_Hash(t *testing.T) {
+	type input struct {
+		ID   openapi3.Path[int]
+		Sort openapi3.Query[string] `enum:"asc,desc"`
+	}
+	h := func(input) (any, error) { return nil, nil }
+
+	build := func() *openapi3.OpenAPI {
+		r, spec := newTestRouter(t)
+		routey.Get(r, "/items/{id}", h)
+		return spec
+	}
+
+	hash := func(spec *openapi3.OpenAPI) string {
+		got, err := spec.Hash()
+		test.NoError(t, err)
+		return got
+	}
+
+	first, second := build(), build()
+	test.Equal(t, hash(first), hash(second))
+
+	second.Info.Spec.Title = "changed"
+	if hash(first) == hash(second) {
+		t.Error("expected the hash of a modified spec to differ")
+	}
+}
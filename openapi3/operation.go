@@ -2,6 +2,7 @@ package openapi3
 
 import (
 	"errors"
+	"fmt"
 	"strconv"
 
 	"github.com/sv-tools/openapi"
@@ -13,6 +14,8 @@ type Operation struct {
 	*openapi.Operation
 
 	Ignore bool `json:"-"`
+	// Extensions are the specification extensions added to the operation.
+	Extensions map[string]any `json:"-"`
 }
 
 func NewOperation() Operation {
@@ -21,6 +24,54 @@ func NewOperation() Operation {
 	}
 }
 
+// AddExt adds the specification extension to the operation.
+func (o *Operation) AddExt(name string, value any) {
+	if o.Extensions == nil {
+		o.Extensions = map[string]any{}
+	}
+	o.Extensions[name] = value
+}
+
+// SetNoSecurity marks the operation as public, overriding the security of
+// the spec with a single empty security requirement, which is satisfied
+// without authentication. An empty list can not be used, as it is omitted
+// when marshalling.
+func (o *Operation) SetNoSecurity() {
+	o.Security = []openapi.SecurityRequirement{{}}
+}
+
+const mutuallyExclusiveExt = "x-mutually-exclusive"
+
+// AddMutuallyExclusive marks the query parameters as mutually exclusive,
+// allowing requests to provide at most one of them. Each group is documented
+// with the x-mutually-exclusive extension.
+func (o *Operation) AddMutuallyExclusive(names ...string) {
+	o.AddExt(mutuallyExclusiveExt, append(o.MutuallyExclusive(), names))
+}
+
+// MutuallyExclusive returns the groups of mutually exclusive query parameters.
+func (o *Operation) MutuallyExclusive() [][]string {
+	groups, _ := o.Extensions[mutuallyExclusiveExt].([][]string)
+	return groups
+}
+
+// ErrUnknownMutuallyExclusiveParam is returned when a mutually exclusive
+// group names a query parameter the operation does not have.
+var ErrUnknownMutuallyExclusiveParam = errors.New("mutually exclusive param not found")
+
+// validateMutuallyExclusiveNames returns an error if any of the mutually
+// exclusive groups name a query parameter the operation does not have.
+func (o *Operation) validateMutuallyExclusiveNames() error {
+	for _, names := range o.MutuallyExclusive() {
+		for _, name := range names {
+			if _, has := o.GetParameter(name, string(param.LocationQuery)); !has {
+				return fmt.Errorf("%w: query %q", ErrUnknownMutuallyExclusiveParam, name)
+			}
+		}
+	}
+	return nil
+}
+
 func (o *Operation) SetDefaultResponse(resp Response) {
 	if o.Responses == nil {
 		o.Responses = openapi.NewExtendable(&openapi.Responses{})
@@ -93,7 +144,7 @@ func SchemaFromOp(op Operation, contentType string) (jsonschema.Schema, error) {
 		Description("Contains the request body and all parameters").
 		ObjectBuilder
 
-	addParamsToSchema(schema, op.Parameters)
+	addParamsToSchema(schema, op.Parameters, op.MutuallyExclusive())
 
 	if err := addBodyToSchema(schema, op.RequestBody, contentType); err != nil {
 		return jsonschema.New(), err
@@ -139,6 +190,7 @@ func addBodyToSchema(
 func addParamsToSchema(
 	schema jsonschema.ObjectBuilder,
 	parameters []*openapi.RefOrSpec[openapi.Extendable[openapi.Parameter]],
+	mutuallyExclusive [][]string,
 ) {
 	if len(parameters) == 0 {
 		return
@@ -168,6 +220,12 @@ func addParamsToSchema(
 			}
 		}
 
+		if loc == string(param.LocationQuery) {
+			for _, names := range mutuallyExclusive {
+				locSchema = locSchema.MutuallyExclusive(names...)
+			}
+		}
+
 		paramsSchemas = paramsSchemas.Property(loc, locSchema.Build())
 	}
 
@@ -146,3 +146,45 @@ func TestSchemaFromOpWithRef(t *testing.T) {
     }`
 	compareOpSchema(t, *got, want)
 }
+
+func TestSchemaFromOp_MutuallyExclusive(t *testing.T) {
+	r, ctx := newOptionsCtx()
+
+	err := option.Params[struct {
+		ID   routey.Query[int]
+		Name routey.Query[string]
+	}]()(ctx.Info)
+	test.NoError(t, err)
+
+	err = option.MutuallyExclusive("id", "name")(ctx.Info)
+	test.NoError(t, err)
+
+	err = r.OnRouteAdd(ctx.Info)
+	test.NoError(t, err)
+
+	got := openapi3.OperationFromCtx(ctx.Info.Context)
+	want := `{
+        "description": "Contains the request body and all parameters",
+        "properties": {
+            "parameters": {
+                "description": "Contains the parameters",
+                "properties": {
+                    "query": {
+                        "allOf": [
+                            {"not": {"required": ["id", "name"]}}
+                        ],
+                        "properties": {
+                            "id": {"type": "integer"},
+                            "name": {"type": "string"}
+                        },
+                        "type": "object"
+                    }
+                },
+                "type": "object"
+            }
+        },
+        "required": ["parameters"],
+        "type": "object"
+    }`
+	compareOpSchema(t, *got, want)
+}
@@ -1,9 +1,20 @@
 package option
 
 import (
+	"errors"
 	"fmt"
-
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sv-tools/openapi"
+	"github.com/zhamlin/routey"
+	"github.com/zhamlin/routey/extractor"
 	"github.com/zhamlin/routey/internal/stringz"
+	"github.com/zhamlin/routey/jsonschema"
 	"github.com/zhamlin/routey/openapi3"
 	"github.com/zhamlin/routey/param"
 	"github.com/zhamlin/routey/route"
@@ -49,9 +60,12 @@ func (ctx Context) newMediaType(obj any) (openapi3.MediaType, error) {
 // adding them to the operation.
 func Params[T any]() route.Option {
 	return New(func(ctx *Context, _ *openapi3.Operation) error {
-		params, err := param.InfoFromStruct[T](
-			ctx.Namer, ctx.Parser,
-		)
+		params, err := param.InfoFromType(reflect.TypeFor[T](), param.Config{
+			Parser:    ctx.Parser,
+			Namer:     ctx.Namer,
+			JSONNames: ctx.JSONNames,
+			Joiner:    ctx.Joiner,
+		})
 		if err != nil {
 			return err
 		}
@@ -61,6 +75,10 @@ func Params[T any]() route.Option {
 	})
 }
 
+// Body sets the request body of the operation to T. If the schema of T is
+// binary and declares a maxLength, requests with a larger Content-Length
+// receive a 413. Bodies with a json content type are not limited, as the
+// maxLength of their schema counts characters of the decoded value.
 func Body[T any](desc string, required bool, contentType ...string) route.Option {
 	return New(func(ctx *Context, o *openapi3.Operation) error {
 		var obj T
@@ -78,8 +96,29 @@ func Body[T any](desc string, required bool, contentType ...string) route.Option
 		}
 
 		o.SetRequestBody(body)
-		return nil
+		return setMaxBodySize(ctx, obj, ctx.getContentType(contentType))
+	})
+}
+
+// setMaxBodySize limits the size of the request body to the maxLength
+// declared on the bodies schema, if any, when the body is binary.
+func setMaxBodySize(ctx *Context, obj any, contentTypes []string) error {
+	schema, err := ctx.OpenAPI.Schemer.Get(obj)
+	if err != nil {
+		return fmt.Errorf("failed getting schema: %w", err)
+	}
+
+	isJSON := slices.ContainsFunc(contentTypes, func(typ string) bool {
+		return strings.Contains(typ, "json")
 	})
+	if schema.Format != "binary" || isJSON {
+		return nil
+	}
+
+	if n := schema.MaxLength; n != nil {
+		ctx.Info.MaxBodySize = int64(*n)
+	}
+	return nil
 }
 
 // ContentType sets the content type for the provided responses.
@@ -141,6 +180,8 @@ func Response[T any](code int, desc string, contentType ...string) route.Option
 		switch any(&obj).(type) {
 		case *any:
 		case *None:
+		case *extractor.TooManyRequests:
+			resp.SetHeader("Retry-After", retryAfterHeader())
 		default:
 			mediaType, err := ctx.newMediaType(obj)
 			if err != nil {
@@ -157,6 +198,108 @@ func Response[T any](code int, desc string, contentType ...string) route.Option
 	})
 }
 
+// ContentResponse documents the response for the http status code of a handler
+// returning a [routey.Content], with a media type for each content type the
+// handler responds with. The media types have no schema, as any value can be
+// encoded by the registered encoder.
+func ContentResponse(code int, desc string, contentTypes ...string) route.Option {
+	return New(func(ctx *Context, o *openapi3.Operation) error {
+		resp := openapi3.Response{}
+		resp.Description = stringz.TrimLinesSpace(desc)
+
+		for _, typ := range ctx.getContentType(contentTypes) {
+			resp.SetContent(typ, openapi3.NewMediaType())
+		}
+
+		o.AddResponse(code, resp)
+		return nil
+	})
+}
+
+var ErrNoResponse = errors.New("no response for the status code")
+
+// ResponseExample adds a named example to each content type of the response for
+// the http status code, which must be added first. Examples are validated against
+// the schema of the response when the spec is strict, see [openapi3.OpenAPI.Strict].
+func ResponseExample(code int, name string, value any) route.Option {
+	return New(func(_ *Context, o *openapi3.Operation) error {
+		var resp *openapi.RefOrSpec[openapi.Extendable[openapi.Response]]
+		if o.Responses != nil {
+			resp = o.Responses.Spec.Response[strconv.Itoa(code)]
+		}
+
+		if resp == nil || resp.Spec == nil {
+			return fmt.Errorf("%w: %d", ErrNoResponse, code)
+		}
+
+		for _, mt := range resp.Spec.Spec.Content {
+			if mt.Spec.Examples == nil {
+				mt.Spec.Examples = map[string]*openapi.RefOrSpec[openapi.Extendable[openapi.Example]]{}
+			}
+
+			example := openapi3.NewExtendable(&openapi.Example{Value: value})
+			mt.Spec.Examples[name] = openapi.NewRefOrSpec[openapi.Extendable[openapi.Example]](example)
+		}
+		return nil
+	})
+}
+
+func retryAfterHeader() openapi.Header {
+	schema := jsonschema.NewBuilder().Type(jsonschema.TypeInteger).Build()
+	return openapi.Header{
+		Description: "Seconds to wait before making another request",
+		Schema:      openapi.NewRefOrSpec[openapi.Schema](schema.Schema),
+	}
+}
+
+// ErrorResponses sets the response body for the http status code
+// to a oneOf containing the schema of each type.
+func ErrorResponses(code int, types ...any) route.Option {
+	return New(func(ctx *Context, o *openapi3.Operation) error {
+		schema := openapi.Schema{}
+		for _, typ := range types {
+			s, err := ctx.OpenAPI.GetSchemaOrRef(typ, openapi3.SchemaRefOptions{
+				ForceNoRef:            ctx.noRef,
+				IgnoreAddSchemaErrors: true,
+			})
+			if err != nil {
+				return fmt.Errorf("failed getting schema: %w", err)
+			}
+			schema.OneOf = append(schema.OneOf, s)
+		}
+
+		mediaType := openapi3.NewMediaType()
+		mediaType.Schema = openapi.NewRefOrSpec[openapi.Schema](&schema)
+
+		resp := openapi3.Response{}
+		resp.Description = http.StatusText(code)
+
+		for _, typ := range ctx.getContentType(nil) {
+			resp.SetContent(typ, mediaType)
+		}
+
+		o.AddResponse(code, resp)
+		return nil
+	})
+}
+
+var ErrNoErrorResponse = errors.New("no response registered for the error type")
+
+// MayReturn adds the response registered for the error type T
+// by [openapi3.RegisterErrorResponse].
+func MayReturn[T any]() route.Option {
+	return New(func(ctx *Context, o *openapi3.Operation) error {
+		typ := reflect.TypeFor[T]()
+		resp, has := ctx.OpenAPI.GetErrorResponse(typ)
+		if !has {
+			return fmt.Errorf("%w: %s", ErrNoErrorResponse, typ)
+		}
+
+		o.AddResponse(resp.Code, resp.Response)
+		return nil
+	})
+}
+
 // ID sets the operations id.
 func ID(id string) route.Option {
 	return New(func(_ *Context, o *openapi3.Operation) error {
@@ -181,6 +324,15 @@ func Deprecated() route.Option {
 	})
 }
 
+// NoSecurity marks the operation as requiring no authentication,
+// overriding any security requirements of the spec.
+func NoSecurity() route.Option {
+	return New(func(_ *Context, o *openapi3.Operation) error {
+		o.SetNoSecurity()
+		return nil
+	})
+}
+
 // Summary sets the summary on the operation.
 func Summary(summary string) route.Option {
 	return New(func(_ *Context, o *openapi3.Operation) error {
@@ -189,6 +341,62 @@ func Summary(summary string) route.Option {
 	})
 }
 
+// Callback adds a callback to the operation for the requests sent by the API to
+// the url of the runtime expression, documented by the routes fn adds to the
+// router. See [openapi3.OpenAPI.NewCallback].
+func Callback(name, expression string, fn func(*routey.Router)) route.Option {
+	return New(func(ctx *Context, o *openapi3.Operation) error {
+		callback, err := ctx.OpenAPI.NewCallback(expression, fn)
+		if err != nil {
+			return fmt.Errorf("callback %s: %w", name, err)
+		}
+
+		if o.Callbacks == nil {
+			o.Callbacks = map[string]*openapi.RefOrSpec[openapi.Extendable[openapi.Callback]]{}
+		}
+		o.Callbacks[name] = callback
+		return nil
+	})
+}
+
+var ErrMutuallyExclusiveParams = errors.New("mutually exclusive requires at least two params")
+
+// MutuallyExclusive allows requests to provide at most one of the named query
+// parameters, which must be params of the operation. Requests providing more
+// than one get a [jsonschema.ValidationError] with a 400 from the
+// [openapi3.Query] extractors of the params, with or without ValidateRequests.
+func MutuallyExclusive(names ...string) route.Option {
+	return New(func(_ *Context, o *openapi3.Operation) error {
+		if len(names) < 2 {
+			return ErrMutuallyExclusiveParams
+		}
+
+		o.AddMutuallyExclusive(names...)
+		return nil
+	})
+}
+
+// Timeout sets the maximum duration the routes handler can run for.
+// Requests exceeding it have their context canceled and receive a 503.
+// When used with an openapi router the timeout is documented on the
+// operation with the x-timeout extension.
+func Timeout(d time.Duration) route.Option {
+	document := New(func(_ *Context, o *openapi3.Operation) error {
+		o.AddExt("x-timeout", d.String())
+		return nil
+	})
+
+	return func(i *route.Info) error {
+		i.Timeout = d
+
+		err := document(i)
+		if errors.Is(err, openapi3.ErrNoContext) {
+			return nil
+		}
+		return err
+	}
+}
+
 func ctxFromInfo(i *route.Info) (*Context, error) {
 	const contextKey = "openapi3.option.context"
 	if ctx, ok := i.Context[contextKey].(*Context); ok {
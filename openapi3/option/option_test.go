@@ -4,10 +4,16 @@ import (
 	"errors"
 	"maps"
 	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/sv-tools/openapi"
 
 	"github.com/zhamlin/routey"
+	"github.com/zhamlin/routey/extractor"
 	"github.com/zhamlin/routey/internal/test"
 	"github.com/zhamlin/routey/jsonschema"
 	"github.com/zhamlin/routey/openapi3"
@@ -493,6 +499,32 @@ func TestOption_ResponseDefaultContentType(t *testing.T) {
 	test.MatchAsJSON(t, got, want)
 }
 
+func TestOption_ResponseTooManyRequests(t *testing.T) {
+	_, info := createInfo(t)
+
+	err := option.Response[extractor.TooManyRequests](
+		http.StatusTooManyRequests, "rate limited",
+	)(&info)
+	test.NoError(t, err)
+
+	got := openapi3.OperationFromCtx(info.Context)
+	test.MatchAsJSON(t, got, `
+	{
+		"responses": {
+			"429": {
+				"description": "rate limited",
+				"headers": {
+					"Retry-After": {
+						"description": "Seconds to wait before making another request",
+						"schema": {"type": "integer"}
+					}
+				}
+			}
+		}
+	}
+	`)
+}
+
 func TestOption_ResponseContentType(t *testing.T) {
 	spec, info := createInfo(t)
 
@@ -665,3 +697,399 @@ func TestOption_ParamsError(t *testing.T) {
 	err := option.Params[int]()(&info)
 	test.IsError(t, err, param.ErrNonStructArg)
 }
+
+func TestRouter_Timeout(t *testing.T) {
+	slow := func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+			w.WriteHeader(http.StatusOK)
+		}
+	}
+
+	r, spec := openapi3.NewRouter()
+	r.Get("/slow", slow, option.ID("slow"), option.Timeout(10*time.Millisecond))
+	r.Get("/fast", func(http.ResponseWriter, *http.Request) {}, option.ID("fast"))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/slow", nil))
+	test.Equal(t, w.Code, http.StatusServiceUnavailable)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/fast", nil))
+	test.Equal(t, w.Code, http.StatusOK)
+
+	slowPath, _ := spec.GetPath("/slow")
+	test.MatchAsJSON(t, slowPath, `
+	{
+		"get": {
+			"operationId": "slow",
+			"x-timeout": "10ms"
+		}
+	}
+	`)
+}
+
+func TestRouter_NoSecurity(t *testing.T) {
+	r, spec := openapi3.NewRouter()
+	spec.Security = []openapi.SecurityRequirement{{"apiKey": {}}}
+	r.Get("/public", func(http.ResponseWriter, *http.Request) {}, option.ID("public"), option.NoSecurity())
+	r.Get("/private", func(http.ResponseWriter, *http.Request) {}, option.ID("private"))
+
+	test.MatchAsJSON(t, spec.Paths, `
+	{
+		"/private": {
+			"get": {
+				"operationId": "private"
+			}
+		},
+		"/public": {
+			"get": {
+				"operationId": "public",
+				"security": [{}]
+			}
+		}
+	}
+	`)
+}
+
+func TestRouter_NoSecurityOverridden(t *testing.T) {
+	r, spec := openapi3.NewRouter()
+	r.Get("/", func(http.ResponseWriter, *http.Request) {},
+		option.ID("id"),
+		option.NoSecurity(),
+		option.New(func(_ *option.Context, o *openapi3.Operation) error {
+			o.Security = []openapi.SecurityRequirement{{"apiKey": {}}}
+			return nil
+		}),
+	)
+
+	test.MatchAsJSON(t, spec.Paths, `
+	{
+		"/": {
+			"get": {
+				"operationId": "id",
+				"security": [{"apiKey": []}]
+			}
+		}
+	}
+	`)
+}
+
+func TestOption_TimeoutWithoutOpenAPI3Ctx(t *testing.T) {
+	info := route.Info{}
+	err := option.Timeout(time.Second)(&info)
+	test.NoError(t, err)
+	test.Equal(t, info.Timeout, time.Second)
+}
+
+func TestRouter_ErrorResponses(t *testing.T) {
+	type NotFound struct {
+		Resource string `json:"resource"`
+	}
+	type Invalid struct {
+		Fields []string `json:"fields"`
+	}
+	h := func(struct{}) (any, error) { return nil, nil }
+
+	r, spec := openapi3.NewRouter()
+	routey.Get(r, "/", h, option.ErrorResponses(http.StatusBadRequest, NotFound{}, Invalid{}))
+
+	path, _ := spec.GetPath("/")
+	test.MatchAsJSON(t, path, `
+	{
+	  "get": {
+		"responses": {
+		  "400": {
+			"description": "Bad Request",
+			"content": {
+			  "application/json": {
+				"schema": {
+				  "oneOf": [
+					{"$ref": "#/components/schemas/NotFound"},
+					{"$ref": "#/components/schemas/Invalid"}
+				  ]
+				}
+			  }
+			}
+		  }
+		}
+	  }
+	}
+	`)
+
+	test.MatchAsJSON(t, spec.Components.Spec.Schemas, `
+	{
+	  "Invalid": {
+		"type": "object",
+		"properties": {
+		  "fields": {
+			"type": "array",
+			"items": {"type": "string"}
+		  }
+		}
+	  },
+	  "NotFound": {
+		"type": "object",
+		"properties": {
+		  "resource": {"type": "string"}
+		}
+	  }
+	}
+	`)
+}
+
+type upload string
+
+func (upload) JSONSchemaExtend(s *jsonschema.Schema) {
+	maxLength := 4
+	s.Format = "binary"
+	s.MaxLength = &maxLength
+}
+
+func TestRouter_BodyMaxLength(t *testing.T) {
+	called := false
+	h := func(http.ResponseWriter, *http.Request) { called = true }
+
+	r, _ := openapi3.NewRouter()
+	r.Post("/upload", h,
+		option.ID("upload"),
+		option.Body[upload]("", true, "application/octet-stream"),
+	)
+
+	req := httptest.NewRequestWithContext(
+		t.Context(), http.MethodPost, "/upload", strings.NewReader("too large"),
+	)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	test.Equal(t, w.Code, http.StatusRequestEntityTooLarge)
+	test.Equal(t, called, false)
+
+	req = httptest.NewRequestWithContext(
+		t.Context(), http.MethodPost, "/upload", strings.NewReader("ok"),
+	)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	test.Equal(t, w.Code, http.StatusOK)
+	test.Equal(t, called, true)
+}
+
+type shortName string
+
+func (shortName) JSONSchemaExtend(s *jsonschema.Schema) {
+	maxLength := 5
+	s.MaxLength = &maxLength
+}
+
+func TestRouter_BodyMaxLengthJSON(t *testing.T) {
+	type input struct {
+		Body openapi3.JSON[shortName]
+	}
+
+	var got shortName
+	h := func(i input) (any, error) {
+		got = i.Body.V
+		return nil, nil
+	}
+
+	r, _ := openapi3.NewRouter()
+	r.Response = func(_ http.ResponseWriter, _ *http.Request, resp extractor.Response) {
+		test.NoError(t, resp.Error)
+	}
+	routey.Post(r, "/", h, option.ID("id"), option.Body[shortName]("", true))
+
+	// the encoded value is longer than the maxLength of the string
+	req := httptest.NewRequestWithContext(
+		t.Context(), http.MethodPost, "/", strings.NewReader(`"abcde"`),
+	)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	test.Equal(t, w.Code, http.StatusOK)
+	test.Equal(t, got, "abcde")
+}
+
+func TestOption_Callback(t *testing.T) {
+	type event struct {
+		ID string `json:"id"`
+	}
+	type subscribeInput struct {
+		Body openapi3.JSON[struct {
+			CallbackURL string `json:"callbackUrl"`
+		}]
+	}
+	type eventInput struct {
+		Body openapi3.JSON[event]
+	}
+
+	r, spec := openapi3.NewRouter()
+	routey.Post(r, "/subscribe", func(subscribeInput) (any, error) { return nil, nil },
+		option.ID("subscribe"),
+		option.Callback("onEvent", "{$request.body#/callbackUrl}", func(r *routey.Router) {
+			routey.Post(r, "/", func(eventInput) (any, error) { return nil, nil },
+				option.ID("onEvent"),
+				option.Summary("Event notification"),
+			)
+		}),
+	)
+
+	test.MatchAsJSON(t, spec.Paths.Spec.Paths["/subscribe"].Spec.Spec.Post.Spec.Callbacks, `
+	{
+		"onEvent": {
+			"{$request.body#/callbackUrl}": {
+				"post": {
+					"operationId": "onEvent",
+					"summary": "Event notification",
+					"requestBody": {
+						"content": {
+							"application/json": {
+								"schema": {"$ref": "#/components/schemas/event"}
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	`)
+
+	test.MatchAsJSON(t, spec.Components.Spec.Schemas["event"], `
+	{
+		"type": "object",
+		"properties": {
+			"id": {"type": "string"}
+		}
+	}
+	`)
+}
+
+func TestOption_CallbackError(t *testing.T) {
+	_, info := createInfo(t)
+
+	err := option.Callback("onEvent", "{$request.body#/url}", func(r *routey.Router) {
+		routey.Post(r, "/", func(struct{}) (any, error) { return nil, nil }, option.New(
+			func(*option.Context, *openapi3.Operation) error { return errCallback },
+		))
+	})(&info)
+	test.IsError(t, err, errCallback)
+}
+
+var errCallback = errors.New("callback error")
+
+func TestOption_ResponseExample(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+	}
+
+	r := routey.New()
+	spec := openapi3.AddSpecToRouter(r, openapi3.AddSpecToRouterOpts{Strict: true})
+	r.ErrorSink = func(err error) {
+		test.NoError(t, err)
+	}
+
+	routey.Get(r, "/", func(struct{}) (user, error) { return user{}, nil },
+		option.ID("getUser"),
+		option.Response[user](http.StatusOK, "user"),
+		option.ResponseExample(http.StatusOK, "admin", user{Name: "admin"}),
+	)
+
+	op := spec.Paths.Spec.Paths["/"].Spec.Spec.Get.Spec
+	test.MatchAsJSON(t, op.Responses.Spec.Response["200"], `
+	{
+		"description": "user",
+		"content": {
+			"application/json": {
+				"schema": {"$ref": "#/components/schemas/user"},
+				"examples": {
+					"admin": {"value": {"name": "admin"}}
+				}
+			}
+		}
+	}
+	`)
+}
+
+func TestOption_ResponseExampleInvalid(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+	}
+
+	r := routey.New()
+	openapi3.AddSpecToRouter(r, openapi3.AddSpecToRouterOpts{Strict: true})
+
+	gotError := test.WantAfterTest(t, false, true, "expected an error, got none")
+	r.ErrorSink = func(err error) {
+		test.IsError(t, err, openapi3.ErrInvalidExample)
+		*gotError = true
+	}
+
+	routey.Get(r, "/", func(struct{}) (user, error) { return user{}, nil },
+		option.ID("getUser"),
+		option.Response[user](http.StatusOK, "user"),
+		option.ResponseExample(http.StatusOK, "typo", map[string]any{"name": 1}),
+	)
+}
+
+func TestOption_ResponseExampleNoResponse(t *testing.T) {
+	_, info := createInfo(t)
+
+	err := option.ResponseExample(http.StatusOK, "example", "value")(&info)
+	test.IsError(t, err, option.ErrNoResponse)
+}
+
+type conflictError struct {
+	Message string `json:"message"`
+}
+
+func (e conflictError) Error() string {
+	return e.Message
+}
+
+func (conflictError) StatusCode() int {
+	return http.StatusConflict
+}
+
+func TestOption_MayReturn(t *testing.T) {
+	r := routey.New()
+	spec := openapi3.AddSpecToRouter(r, openapi3.AddSpecToRouterOpts{})
+	r.ErrorSink = func(err error) {
+		test.NoError(t, err)
+	}
+
+	r.Response = extractor.JSONResponse(extractor.JSONResponseOptions{})
+
+	err := openapi3.RegisterErrorResponse[conflictError](spec, http.StatusConflict, "user already exists")
+	test.NoError(t, err)
+
+	h := func(struct{}) (any, error) {
+		return nil, conflictError{Message: "user already exists"}
+	}
+	routey.Post(r, "/users", h, option.ID("createUser"), option.MayReturn[conflictError]())
+
+	op := spec.Paths.Spec.Paths["/users"].Spec.Spec.Post.Spec
+	test.MatchAsJSON(t, op.Responses.Spec.Response["409"], `
+	{
+		"description": "user already exists",
+		"content": {
+			"application/json": {
+				"schema": {"$ref": "#/components/schemas/conflictError"}
+			}
+		}
+	}
+	`)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodPost, "/users", nil)
+	r.ServeHTTP(w, req)
+	test.Equal(t, w.Code, http.StatusConflict)
+}
+
+func TestOption_MayReturnNotRegistered(t *testing.T) {
+	_, info := createInfo(t)
+
+	err := option.MayReturn[conflictError]()(&info)
+	test.IsError(t, err, option.ErrNoErrorResponse)
+}
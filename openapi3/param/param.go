@@ -7,9 +7,11 @@ import (
 	"reflect"
 	"slices"
 	"strconv"
+	"time"
 
 	"github.com/sv-tools/openapi"
 	"github.com/zhamlin/routey"
+	"github.com/zhamlin/routey/internal/stringz"
 	"github.com/zhamlin/routey/jsonschema"
 	"github.com/zhamlin/routey/param"
 )
@@ -28,6 +30,25 @@ func (p *Parameter) SetSchema(schema jsonschema.Schema) {
 	p.Schema = openapi.NewRefOrSpec[openapi.Schema](schema.Schema)
 }
 
+// ValueSchema returns the schema of the params value, which is
+// the schema of its content when the param uses content.
+func (p *Parameter) ValueSchema() *openapi.RefOrSpec[openapi.Schema] {
+	for _, mt := range p.Content {
+		return mt.Spec.Schema
+	}
+	return p.Schema
+}
+
+// SetValueSchema sets the schema of the params value, which is
+// the schema of its content when the param uses content.
+func (p *Parameter) SetValueSchema(schema *openapi.RefOrSpec[openapi.Schema]) {
+	for _, mt := range p.Content {
+		mt.Spec.Schema = schema
+		return
+	}
+	p.Schema = schema
+}
+
 // https://spec.openapis.org/oas/v3.1.0#styleValues
 type Style string
 
@@ -44,6 +65,7 @@ const (
 var (
 	ErrInvalidStyle    = errors.New("invalid parameter style")
 	ErrInvalidLocation = errors.New("invalid parameter location")
+	ErrContentStyle    = errors.New("content can not be used with style or explode")
 )
 
 func StyleFromString(str string) (Style, error) {
@@ -89,22 +111,43 @@ func LocationFromString(str string) (Location, error) {
 	return Location(""), fmt.Errorf("%w: %s", ErrInvalidLocation, str)
 }
 
-func defaultStyle(in Location) (Style, error) {
+// Default holds the style and explode values used for a location
+// when a parameter does not provide them.
+type Default struct {
+	Style   Style
+	Explode bool
+}
+
+// Defaults maps a location to its default style and explode values.
+type Defaults map[Location]Default
+
+// SpecDefaults returns the defaults defined by the OpenAPI specification.
+func SpecDefaults() Defaults {
 	// https://github.com/OAI/OpenAPI-Specification/blob/main/versions/3.1.0.md#parameterStyle
-	switch in {
-	case LocationHeader:
-		return StyleSimple, nil
-	case LocationPath:
-		return StyleSimple, nil
-	case LocationQuery:
-		return StyleForm, nil
-	case LocationCookie:
-		return StyleForm, nil
-	default:
-		return Style(""), ErrInvalidLocation
+	return Defaults{
+		LocationHeader: {Style: StyleSimple},
+		LocationPath:   {Style: StyleSimple},
+		LocationQuery:  {Style: StyleForm, Explode: true},
+		LocationCookie: {Style: StyleForm, Explode: true},
 	}
 }
 
+func (d Defaults) get(in Location) (Default, error) {
+	if d == nil {
+		d = SpecDefaults()
+	}
+
+	def, has := d[in]
+	if !has {
+		def, has = SpecDefaults()[in]
+	}
+
+	if !has {
+		return Default{}, ErrInvalidLocation
+	}
+	return def, nil
+}
+
 func fromInfoError(i param.Info, p Parameter, dataType DataType, err error) error {
 	var typ reflect.Type
 	if i.Field.Type != nil {
@@ -147,20 +190,58 @@ func fromInfoError(i param.Info, p Parameter, dataType DataType, err error) erro
 }
 
 func FromInfo(info param.Info, schemer jsonschema.Schemer) (Parameter, error) {
+	return FromInfoWithDefaults(info, schemer, nil)
+}
+
+// FromInfoWithDefaults is like [FromInfo] but uses the provided defaults
+// for parameters that do not set a style or explode value. Locations missing
+// from defaults fall back to [SpecDefaults].
+func FromInfoWithDefaults(
+	info param.Info,
+	schemer jsonschema.Schemer,
+	defaults Defaults,
+) (Parameter, error) {
+	return FromInfoWithOptions(info, schemer, Options{Defaults: defaults})
+}
+
+// Options configures how [FromInfoWithOptions] creates a parameter.
+type Options struct {
+	// Defaults are used for parameters that do not set a style or explode
+	// value. Locations missing from defaults fall back to [SpecDefaults].
+	Defaults Defaults
+	// Parser converts the default value of the param into its type, so the
+	// schema default is typed. The default is left as a string when nil.
+	Parser param.Parser
+}
+
+// FromInfoWithOptions is like [FromInfo] but configured by opts.
+func FromInfoWithOptions(
+	info param.Info,
+	schemer jsonschema.Schemer,
+	opts Options,
+) (Parameter, error) {
 	p := New()
 	p.Name = info.Name
 	p.In = info.Source
 
-	schema, err := schemer.Get(info.Type)
+	schema, err := paramSchema(info.Type, schemer)
 	if err != nil {
 		return p, fmt.Errorf("failed getting schema: %w", err)
 	}
 
+	layout := param.TimeFormatFromField(info.Field)
+	if layout != "" && schema.Format == string(jsonschema.FormatDateTime) {
+		schema.Format = timeLayoutFormat(layout)
+	}
+
 	infoHasDefault := info.Default != ""
 	schemaHasDefault := schema.Default != nil
 
 	if infoHasDefault && !schemaHasDefault {
-		schema.Default = info.Default
+		schema.Default, err = parseDefault(info, opts.Parser)
+		if err != nil {
+			return p, err
+		}
 	}
 
 	dataType, _ := getSchemasDataType(schema)
@@ -172,7 +253,14 @@ func FromInfo(info param.Info, schemer jsonschema.Schemer) (Parameter, error) {
 		return p, fromInfoError(info, p, dataType, err)
 	}
 
-	p, err = setDefaults(p, tags)
+	if tags.content != "" {
+		if err := setContent(p, tags); err != nil {
+			return p, fromInfoError(info, p, dataType, err)
+		}
+		return p, nil
+	}
+
+	p, err = setDefaults(p, tags, opts.Defaults)
 	if err != nil {
 		return p, err
 	}
@@ -190,16 +278,76 @@ func FromInfo(info param.Info, schemer jsonschema.Schemer) (Parameter, error) {
 	return p, nil
 }
 
-func setDefaults(p Parameter, tags tags) (Parameter, error) {
-	if p.Style == "" {
-		defaultStyle, err := defaultStyle(Location(p.In))
+var (
+	durationType = reflect.TypeFor[time.Duration]()
+	timeType     = reflect.TypeFor[time.Time]()
+)
+
+// paramSchema returns the schema of the param type. Durations are strings
+// parsed by [time.ParseDuration] and times are date-time strings,
+// unless their type has a registered schema.
+func paramSchema(typ reflect.Type, schemer jsonschema.Schemer) (jsonschema.Schema, error) {
+	if schemer.Has(typ) {
+		return schemer.Get(typ)
+	}
+
+	switch typ {
+	case durationType:
+		return jsonschema.NewGoDurationSchema(), nil
+	case timeType:
+		return jsonschema.NewDateTimeSchema(), nil
+	}
+	return schemer.Get(typ)
+}
+
+// timeLayoutFormat returns the format of times in the layout of
+// a `timeformat` tag, which is empty when no format matches it.
+func timeLayoutFormat(layout string) string {
+	switch layout {
+	case time.DateOnly:
+		return string(jsonschema.FormatDate)
+	case time.RFC3339, time.RFC3339Nano:
+		return string(jsonschema.FormatDateTime)
+	}
+	return ""
+}
+
+// parseDefault returns the default value of the param converted to its type.
+// Times are formatted with the layout of their `timeformat` tag, or RFC 3339.
+func parseDefault(info param.Info, parser param.Parser) (any, error) {
+	if parser == nil {
+		return info.Default, nil
+	}
+	parser = param.ParserForField(info.Field, parser)
+
+	v := reflect.New(info.Type)
+	if err := param.Parse(parser, v.Interface(), []string{info.Default}); err != nil {
+		return nil, fmt.Errorf("failed parsing default: %w", err)
+	}
+
+	switch v := v.Elem().Interface().(type) {
+	case time.Duration:
+		return v.String(), nil
+	case time.Time:
+		layout := cmp.Or(param.TimeFormatFromField(info.Field), time.RFC3339)
+		return v.Format(layout), nil
+	}
+	return v.Elem().Interface(), nil
+}
+
+func setDefaults(p Parameter, tags tags, defaults Defaults) (Parameter, error) {
+	switch {
+	case p.Style == "":
+		def, err := defaults.get(Location(p.In))
 		if err != nil {
 			return p, err
 		}
-		p.Style = string(defaultStyle)
-	}
 
-	if p.Style == string(StyleForm) && tags.explode == "" {
+		p.Style = string(def.Style)
+		if tags.explode == "" {
+			p.Explode = def.Explode
+		}
+	case p.Style == string(StyleForm) && tags.explode == "":
 		// form style defaults to explode=true
 		p.Explode = true
 	}
@@ -213,6 +361,24 @@ func setDefaults(p Parameter, tags tags) (Parameter, error) {
 	return p, nil
 }
 
+// setContent moves the schema of the param into a content map with the
+// media type from the content tag, such as a json encoded query param.
+// https://spec.openapis.org/oas/v3.1.0#fixed-fields-for-use-with-content
+func setContent(p Parameter, tags tags) error {
+	if tags.style != "" || tags.explode != "" {
+		return ErrContentStyle
+	}
+
+	mt := openapi.NewExtendable(&openapi.MediaType{Schema: p.Schema})
+	p.Content = map[string]*openapi.Extendable[openapi.MediaType]{tags.content: mt}
+	p.Schema = nil
+
+	if p.In == string(LocationPath) {
+		p.Required = true
+	}
+	return nil
+}
+
 func getSchemasDataType(schema jsonschema.Schema) (DataType, bool) {
 	types := map[string]DataType{
 		openapi.IntegerType: DataTypePrimitive,
@@ -361,23 +527,39 @@ func GetStyleFromTag(tag reflect.StructTag) (Style, error) {
 	return StyleFromString(tags.style)
 }
 
+// GetContentFromTag returns the media type of the params content,
+// or an empty string if the param does not use content.
+func GetContentFromTag(tag reflect.StructTag) string {
+	return getTags(tag).content
+}
+
 type tags struct {
-	explode    string
-	deprecated string
-	style      string
-	required   string
-	reserved   string
-	minimum    string
+	content     string
+	explode     string
+	description string
+	deprecated  string
+	style       string
+	required    string
+	reserved    string
+	minimum     string
+	minLength   string
+	maxLength   string
+	enum        string
 }
 
 func getTags(tag reflect.StructTag) tags {
 	return tags{
-		minimum:    tag.Get("minimum"),
-		explode:    tag.Get("explode"),
-		deprecated: tag.Get("deprecated"),
-		style:      tag.Get("style"),
-		required:   tag.Get("required"),
-		reserved:   tag.Get("reserved"),
+		content:     tag.Get("content"),
+		minimum:     tag.Get("minimum"),
+		minLength:   tag.Get("minLength"),
+		maxLength:   tag.Get("maxLength"),
+		enum:        tag.Get("enum"),
+		explode:     tag.Get("explode"),
+		description: tag.Get("description"),
+		deprecated:  tag.Get("deprecated"),
+		style:       tag.Get("style"),
+		required:    tag.Get("required"),
+		reserved:    tag.Get("reserved"),
 	}
 }
 
@@ -411,6 +593,23 @@ func parseInt(input string, value *int) error {
 	})
 }
 
+// parseDeprecated sets whether or not the param is deprecated. Any value that
+// is not a bool marks the param as deprecated and is used as its description,
+// allowing a replacement to be suggested: `deprecated:"use 'limit' instead"`.
+func parseDeprecated(input string, p Parameter) {
+	if input == "" {
+		return
+	}
+
+	if b, err := strconv.ParseBool(input); err == nil {
+		p.Deprecated = b
+		return
+	}
+
+	p.Deprecated = true
+	p.Description = input
+}
+
 func parseStyle(input string, value *string) error {
 	var style Style
 	if err := parse(input, &style, StyleFromString); err != nil {
@@ -420,6 +619,32 @@ func parseStyle(input string, value *string) error {
 	return nil
 }
 
+// itemsSchema returns the schema of the param,
+// or the schema of its items for arrays.
+func itemsSchema(p Parameter) *openapi.Schema {
+	schema := p.Schema.Spec
+	if items := schema.Items; items != nil && items.Schema != nil && items.Schema.Spec != nil {
+		return items.Schema.Spec
+	}
+	return schema
+}
+
+// setEnum sets the enum of the params schema, or the schema of its
+// items for arrays, parsing the comma separated values with its type.
+func setEnum(input string, p Parameter) error {
+	if input == "" || p.Schema == nil || p.Schema.Spec == nil {
+		return nil
+	}
+
+	schema := itemsSchema(p)
+	enum, err := jsonschema.ParseEnum(input, jsonschema.Schema{Schema: *schema})
+	if err != nil {
+		return err
+	}
+	schema.Enum = enum
+	return nil
+}
+
 type updateFromTagsError struct {
 	Name string
 	Err  error
@@ -446,12 +671,29 @@ func updateFromTags(tags tags, p Parameter) error {
 		p.Schema.Spec.Minimum = &n
 	}
 
+	// lengths apply to each item of arrays, as when parsed
+	lengthSchema := itemsSchema(p)
+	if tags.minLength != "" {
+		lengthSchema.MinLength = new(int)
+	}
+
+	if tags.maxLength != "" {
+		lengthSchema.MaxLength = new(int)
+	}
+
+	if tags.description != "" {
+		p.Description = stringz.TrimLinesSpace(tags.description)
+	}
+	parseDeprecated(tags.deprecated, p)
+
 	return cmp.Or(
+		wrap("enum", setEnum(tags.enum, p)),
 		wrap("explode", parseBool(tags.explode, &p.Explode)),
-		wrap("deprecated", parseBool(tags.deprecated, &p.Deprecated)),
 		wrap("required", parseBool(tags.required, &p.Required)),
 		wrap("reserved", parseBool(tags.reserved, &p.AllowReserved)),
 		wrap("style", parseStyle(tags.style, &p.Style)),
 		wrap("minimum", parseInt(tags.minimum, p.Schema.Spec.Minimum)),
+		wrap("minLength", parseInt(tags.minLength, lengthSchema.MinLength)),
+		wrap("maxLength", parseInt(tags.maxLength, lengthSchema.MaxLength)),
 	)
 }
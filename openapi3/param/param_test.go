@@ -165,6 +165,12 @@ func TestInfoToOpenAPIParam_ValidTags(t *testing.T) {
 			info:     withTag(`deprecated:"true"`),
 			validate: func(p openAPIParam.Parameter) bool { return p.Deprecated },
 		},
+		{
+			info: withTag(`deprecated:"use 'limit' instead"`),
+			validate: func(p openAPIParam.Parameter) bool {
+				return p.Deprecated && p.Description == "use 'limit' instead"
+			},
+		},
 		{
 			info:     withTag(`required:"true"`),
 			validate: func(p openAPIParam.Parameter) bool { return p.Required },
@@ -203,10 +209,6 @@ func TestInfoToOpenAPIParam_InvalidTags(t *testing.T) {
 			info: withTag(`explode:"invalid"`),
 			want: strconv.ErrSyntax,
 		},
-		{
-			info: withTag(`deprecated:"invalid"`),
-			want: strconv.ErrSyntax,
-		},
 		{
 			info: withTag(`required:"invalid"`),
 			want: strconv.ErrSyntax,
@@ -247,6 +249,34 @@ func TestInfoToOpenAPIParam_DefaultValue(t *testing.T) {
 	test.MatchAsJSON(t, got, want)
 }
 
+func TestInfoToOpenAPIParam_TypedDefaultValue(t *testing.T) {
+	params, err := param.InfoFromStruct[struct {
+		FieldName routey.Query[int] `default:"1"`
+	}](param.NamerCapitals, param.ParseInt)
+	test.NoError(t, err)
+
+	schemer := jsonschema.NewSchemer()
+	got, err := openAPIParam.FromInfoWithOptions(params[0], schemer, openAPIParam.Options{
+		Parser: param.ParseInt,
+	})
+	test.NoError(t, err)
+	test.MatchAsJSON(t, got.Schema.Spec, map[string]any{
+		"type":    "integer",
+		"default": 1,
+	})
+}
+
+func TestInfoToOpenAPIParam_Description(t *testing.T) {
+	params, err := param.InfoFromStruct[struct {
+		FieldName routey.Query[int] `description:"number of items"`
+	}](param.NamerCapitals, param.ParseInt)
+	test.NoError(t, err)
+
+	got, err := openAPIParam.FromInfo(params[0], jsonschema.NewSchemer())
+	test.NoError(t, err)
+	test.Equal(t, got.Description, "number of items")
+}
+
 func TestInfoToOpenAPIParam_ValidParam(t *testing.T) {
 	params, err := param.InfoFromStruct[struct {
 		FieldName routey.Query[int] `style:"form"`
@@ -293,6 +323,38 @@ func TestInfoToOpenAPIParam_PathAlwaysRequired(t *testing.T) {
 	test.MatchAsJSON(t, got, want)
 }
 
+func TestInfoToOpenAPIParam_Content(t *testing.T) {
+	params, err := param.InfoFromStruct[struct {
+		Limit routey.Query[int] `content:"application/json" required:"true"`
+	}](param.NamerCapitals, param.ParseInt)
+	test.NoError(t, err)
+
+	got, err := openAPIParam.FromInfo(params[0], jsonschema.NewSchemer())
+	test.NoError(t, err)
+
+	test.MatchAsJSON(t, got, `{
+		"name": "limit",
+		"in": "query",
+		"required": true,
+		"explode": false,
+		"content": {
+			"application/json": {
+				"schema": {"type": "integer"}
+			}
+		}
+	}`)
+}
+
+func TestInfoToOpenAPIParam_ContentWithStyle(t *testing.T) {
+	params, err := param.InfoFromStruct[struct {
+		Limit routey.Query[int] `content:"application/json" style:"form"`
+	}](param.NamerCapitals, param.ParseInt)
+	test.NoError(t, err)
+
+	_, err = openAPIParam.FromInfo(params[0], jsonschema.NewSchemer())
+	test.IsError(t, err, openAPIParam.ErrContentStyle)
+}
+
 func TestStyleFromString(t *testing.T) {
 	tests := []struct {
 		have string
@@ -412,3 +474,41 @@ func TestParam_QueryDefaultExplodeOvverideInJSON(t *testing.T) {
 	}
 	`)
 }
+
+func TestInfoToOpenAPIParam_CustomDefaults(t *testing.T) {
+	params, err := param.InfoFromStruct[struct {
+		Query   routey.Query[[]int]
+		Explode routey.Query[[]int] `explode:"true"`
+		Form    routey.Query[[]int] `style:"form"`
+		Path    routey.Path[[]int]
+	}](param.NamerCapitals, param.NewReflectParser(param.ParseInt))
+	test.NoError(t, err)
+
+	defaults := openAPIParam.Defaults{
+		openAPIParam.LocationQuery: {
+			Style:   openAPIParam.StyleForm,
+			Explode: false,
+		},
+	}
+
+	tests := []struct {
+		style   openAPIParam.Style
+		explode bool
+	}{
+		{style: openAPIParam.StyleForm, explode: false},
+		// an explicit explode tag overrides the default
+		{style: openAPIParam.StyleForm, explode: true},
+		// an explicit style uses the spec explode default
+		{style: openAPIParam.StyleForm, explode: true},
+		// locations not in defaults use the spec defaults
+		{style: openAPIParam.StyleSimple, explode: false},
+	}
+
+	schemer := jsonschema.NewSchemer()
+	for i, want := range tests {
+		got, err := openAPIParam.FromInfoWithDefaults(params[i], schemer, defaults)
+		test.NoError(t, err)
+		test.Equal(t, openAPIParam.Style(got.Style), want.style, got.Name)
+		test.Equal(t, got.Explode, want.explode, got.Name)
+	}
+}
@@ -0,0 +1,409 @@
+// Package postman converts an OpenAPI spec into a Postman collection.
+package postman
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/sv-tools/openapi"
+	"github.com/zhamlin/routey/openapi3"
+)
+
+// SchemaURL is the schema of the collections created by [Collection].
+const SchemaURL = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+// BaseURLVariable is the collection variable prefixed to the url of each request,
+// set to the url of the first server in the spec.
+const BaseURLVariable = "baseUrl"
+
+type collection struct {
+	Info     info       `json:"info"`
+	Item     []item     `json:"item"`
+	Variable []variable `json:"variable,omitempty"`
+}
+
+type info struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Schema      string `json:"schema"`
+}
+
+// item is either a folder containing items, or a request.
+type item struct {
+	Name    string   `json:"name"`
+	Item    []item   `json:"item,omitempty"`
+	Request *request `json:"request,omitempty"`
+}
+
+type request struct {
+	Method      string     `json:"method"`
+	Description string     `json:"description,omitempty"`
+	Header      []variable `json:"header"`
+	URL         requestURL `json:"url"`
+	Body        *body      `json:"body,omitempty"`
+}
+
+type requestURL struct {
+	Raw      string     `json:"raw"`
+	Host     []string   `json:"host"`
+	Path     []string   `json:"path"`
+	Query    []variable `json:"query,omitempty"`
+	Variable []variable `json:"variable,omitempty"`
+}
+
+type variable struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Description string `json:"description,omitempty"`
+	Disabled    bool   `json:"disabled,omitempty"`
+}
+
+type body struct {
+	Mode    string       `json:"mode"`
+	Raw     string       `json:"raw"`
+	Options *bodyOptions `json:"options,omitempty"`
+}
+
+type bodyOptions struct {
+	Raw struct {
+		Language string `json:"language"`
+	} `json:"raw"`
+}
+
+// Collection returns a Postman v2.1 collection containing a request for each
+// operation in the spec. Requests are grouped into a folder by the first tag
+// of their operation, with untagged requests at the top level. Params and
+// bodies use their examples, or an example created from their schema.
+func Collection(spec *openapi3.OpenAPI) ([]byte, error) {
+	c := collection{
+		Info: info{Schema: SchemaURL},
+		Item: []item{},
+		Variable: []variable{
+			{Key: BaseURLVariable},
+		},
+	}
+
+	if i := spec.Info; i != nil && i.Spec != nil {
+		c.Info.Name = i.Spec.Title
+		c.Info.Description = i.Spec.Description
+	}
+
+	if len(spec.Servers) > 0 && spec.Servers[0].Spec != nil {
+		c.Variable[0].Value = spec.Servers[0].Spec.URL
+	}
+
+	b := builder{components: spec.Components}
+	folders := map[string]int{}
+
+	var paths []string
+	if spec.Paths != nil {
+		paths = slices.Sorted(maps.Keys(spec.Paths.Spec.Paths))
+	}
+
+	for _, path := range paths {
+		pathItem, has := spec.GetPath(path)
+		if !has {
+			continue
+		}
+
+		for _, op := range pathItem.GetOperations() {
+			req, err := b.request(path, op.Method, pathItem, op.Operation)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", op.Method, path, err)
+			}
+
+			reqItem := item{Name: itemName(path, op), Request: &req}
+			if len(op.Operation.Tags) == 0 {
+				c.Item = append(c.Item, reqItem)
+				continue
+			}
+
+			tag := op.Operation.Tags[0]
+			idx, has := folders[tag]
+			if !has {
+				idx = len(c.Item)
+				folders[tag] = idx
+				c.Item = append(c.Item, item{Name: tag})
+			}
+			c.Item[idx].Item = append(c.Item[idx].Item, reqItem)
+		}
+	}
+
+	return json.MarshalIndent(c, "", "  ")
+}
+
+func itemName(path string, op openapi3.PathOperation) string {
+	switch {
+	case op.Operation.Summary != "":
+		return op.Operation.Summary
+	case op.Operation.OperationID != "":
+		return op.Operation.OperationID
+	}
+	return op.Method + " " + path
+}
+
+type builder struct {
+	components *openapi.Extendable[openapi.Components]
+}
+
+func (b builder) request(
+	path, method string,
+	pathItem openapi3.PathItem,
+	op openapi3.Operation,
+) (request, error) {
+	req := request{
+		Method:      method,
+		Description: op.Description,
+		Header:      []variable{},
+		URL: requestURL{
+			Host: []string{"{{" + BaseURLVariable + "}}"},
+			Path: []string{},
+		},
+	}
+
+	for segment := range strings.SplitSeq(strings.Trim(path, "/"), "/") {
+		if name, ok := strings.CutPrefix(segment, "{"); ok {
+			segment = ":" + strings.TrimSuffix(name, "}")
+		}
+		if segment != "" {
+			req.URL.Path = append(req.URL.Path, segment)
+		}
+	}
+
+	params, err := b.parameters(pathItem, op)
+	if err != nil {
+		return req, err
+	}
+
+	for _, p := range params {
+		v := variable{
+			Key:         p.Name,
+			Value:       formatValue(b.paramExample(p)),
+			Description: p.Description,
+		}
+
+		switch p.In {
+		case openapi.InPath:
+			req.URL.Variable = append(req.URL.Variable, v)
+		case openapi.InQuery:
+			v.Disabled = !p.Required
+			req.URL.Query = append(req.URL.Query, v)
+		case openapi.InHeader:
+			req.Header = append(req.Header, v)
+		}
+	}
+
+	req.URL.Raw = rawURL(req.URL)
+
+	if op.RequestBody != nil {
+		reqBody, contentType, err := b.body(op.RequestBody)
+		if err != nil {
+			return req, err
+		}
+
+		req.Body = reqBody
+		req.Header = append(req.Header, variable{Key: "Content-Type", Value: contentType})
+	}
+
+	return req, nil
+}
+
+// parameters returns the params of the operation, followed by the params of
+// the path item not overridden by the operation.
+func (b builder) parameters(pathItem openapi3.PathItem, op openapi3.Operation) ([]*openapi.Parameter, error) {
+	var params []*openapi.Parameter
+	seen := map[string]bool{}
+
+	add := func(refs []*openapi.RefOrSpec[openapi.Extendable[openapi.Parameter]]) error {
+		for _, ref := range refs {
+			p, err := ref.GetSpec(b.components)
+			if err != nil {
+				return err
+			}
+
+			if key := p.Spec.In + "." + p.Spec.Name; !seen[key] {
+				seen[key] = true
+				params = append(params, p.Spec)
+			}
+		}
+		return nil
+	}
+
+	if err := add(op.Parameters); err != nil {
+		return nil, err
+	}
+
+	if pathItem.PathItem != nil {
+		if err := add(pathItem.Parameters); err != nil {
+			return nil, err
+		}
+	}
+	return params, nil
+}
+
+func rawURL(u requestURL) string {
+	raw := strings.Join(u.Host, "") + "/" + strings.Join(u.Path, "/")
+
+	var query []string
+	for _, q := range u.Query {
+		if !q.Disabled {
+			query = append(query, q.Key+"="+q.Value)
+		}
+	}
+
+	if len(query) > 0 {
+		raw += "?" + strings.Join(query, "&")
+	}
+	return raw
+}
+
+func (b builder) paramExample(p *openapi.Parameter) any {
+	if p.Example != nil {
+		return p.Example
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(p.Examples)) {
+		if example, err := p.Examples[name].GetSpec(b.components); err == nil {
+			return example.Spec.Value
+		}
+	}
+
+	if p.Schema == nil {
+		return nil
+	}
+	return b.schemaExample(p.Schema, map[*openapi.Schema]bool{})
+}
+
+// body returns the body of the request using the json content type if the
+// request body has one, otherwise the first content type.
+func (b builder) body(ref *openapi.RefOrSpec[openapi.Extendable[openapi.RequestBody]]) (*body, string, error) {
+	reqBody, err := ref.GetSpec(b.components)
+	if err != nil {
+		return nil, "", err
+	}
+
+	content := reqBody.Spec.Content
+	contentTypes := slices.Sorted(maps.Keys(content))
+	if len(contentTypes) == 0 {
+		return nil, "", nil
+	}
+
+	contentType := contentTypes[0]
+	for _, typ := range contentTypes {
+		if isJSON(typ) {
+			contentType = typ
+			break
+		}
+	}
+
+	result := &body{Mode: "raw"}
+	if !isJSON(contentType) {
+		return result, contentType, nil
+	}
+
+	var example any
+	if media := content[contentType].Spec; media.Example != nil {
+		example = media.Example
+	} else if media.Schema != nil {
+		example = b.schemaExample(media.Schema, map[*openapi.Schema]bool{})
+	}
+
+	raw, err := json.MarshalIndent(example, "", "  ")
+	if err != nil {
+		return nil, "", err
+	}
+
+	result.Raw = string(raw)
+	result.Options = &bodyOptions{}
+	result.Options.Raw.Language = "json"
+	return result, contentType, nil
+}
+
+func isJSON(contentType string) bool {
+	return strings.Contains(contentType, "json")
+}
+
+// schemaExample returns an example value of the schema, stopping
+// at schemas already being visited to handle recursive schemas.
+func (b builder) schemaExample(
+	ref *openapi.RefOrSpec[openapi.Schema],
+	visiting map[*openapi.Schema]bool,
+) any {
+	s, err := ref.GetSpec(b.components)
+	if err != nil || visiting[s] {
+		return nil
+	}
+
+	visiting[s] = true
+	defer delete(visiting, s)
+
+	switch {
+	case s.Example != nil:
+		return s.Example
+	case len(s.Examples) > 0:
+		return s.Examples[0]
+	case s.Default != nil:
+		return s.Default
+	case s.Const != "":
+		return s.Const
+	case len(s.Enum) > 0:
+		return s.Enum[0]
+	case len(s.OneOf) > 0:
+		return b.schemaExample(s.OneOf[0], visiting)
+	case len(s.AnyOf) > 0:
+		return b.schemaExample(s.AnyOf[0], visiting)
+	}
+
+	var typ string
+	if s.Type != nil && len(*s.Type) > 0 {
+		typ = (*s.Type)[0]
+	}
+
+	switch {
+	case typ == openapi.ObjectType || len(s.Properties) > 0 || len(s.AllOf) > 0:
+		obj := map[string]any{}
+		for name, prop := range s.Properties {
+			obj[name] = b.schemaExample(prop, visiting)
+		}
+
+		for _, schema := range s.AllOf {
+			if values, ok := b.schemaExample(schema, visiting).(map[string]any); ok {
+				maps.Copy(obj, values)
+			}
+		}
+		return obj
+	case typ == openapi.ArrayType:
+		if s.Items == nil || s.Items.Schema == nil {
+			return []any{}
+		}
+		return []any{b.schemaExample(s.Items.Schema, visiting)}
+	case typ == openapi.StringType:
+		return "string"
+	case typ == openapi.IntegerType, typ == openapi.NumberType:
+		return 0
+	case typ == openapi.BooleanType:
+		return false
+	}
+	return nil
+}
+
+// formatValue returns the value formatted for a param,
+// with the items of arrays separated by commas.
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, formatValue(item))
+		}
+		return strings.Join(items, ",")
+	}
+	return fmt.Sprint(v)
+}
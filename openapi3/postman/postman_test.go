@@ -0,0 +1,125 @@
+package postman_test
+
+import (
+	"testing"
+
+	"github.com/zhamlin/routey"
+	"github.com/zhamlin/routey/internal/test"
+	"github.com/zhamlin/routey/openapi3"
+	"github.com/zhamlin/routey/openapi3/option"
+	"github.com/zhamlin/routey/openapi3/postman"
+	"github.com/zhamlin/routey/route"
+)
+
+func tags(names ...string) route.Option {
+	return option.New(func(_ *option.Context, o *openapi3.Operation) error {
+		o.Tags = names
+		return nil
+	})
+}
+
+func TestCollection(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+	type listInput struct {
+		Page routey.Query[int] `name:"page" required:"true"`
+	}
+	type getInput struct {
+		ID routey.Path[int] `name:"id"`
+	}
+	type createInput struct {
+		Body openapi3.JSON[user]
+	}
+
+	r, spec := openapi3.NewRouter()
+	r.ErrorSink = func(err error) {
+		test.NoError(t, err, "ErrorSink")
+	}
+
+	spec.Info.Spec.Title = "Users"
+	routey.Get(r, "/users", func(listInput) (any, error) { return nil, nil },
+		option.ID("listUsers"), tags("users"))
+	routey.Post(r, "/users", func(createInput) (any, error) { return nil, nil },
+		option.ID("createUser"), option.Summary("Create a user"), tags("users"))
+	routey.Get(r, "/users/{id}", func(getInput) (any, error) { return nil, nil },
+		option.ID("getUser"), tags("users"))
+	routey.Get(r, "/health", func(struct{}) (any, error) { return nil, nil },
+		option.ID("health"))
+
+	got, err := postman.Collection(spec)
+	test.NoError(t, err)
+
+	test.MatchAsJSON(t, string(got), `
+	{
+		"info": {
+			"name": "Users",
+			"schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+		},
+		"item": [
+			{
+				"name": "health",
+				"request": {
+					"method": "GET",
+					"header": [],
+					"url": {
+						"raw": "{{baseUrl}}/health",
+						"host": ["{{baseUrl}}"],
+						"path": ["health"]
+					}
+				}
+			},
+			{
+				"name": "users",
+				"item": [
+					{
+						"name": "listUsers",
+						"request": {
+							"method": "GET",
+							"header": [],
+							"url": {
+								"raw": "{{baseUrl}}/users?page=0",
+								"host": ["{{baseUrl}}"],
+								"path": ["users"],
+								"query": [{"key": "page", "value": "0"}]
+							}
+						}
+					},
+					{
+						"name": "Create a user",
+						"request": {
+							"method": "POST",
+							"header": [{"key": "Content-Type", "value": "application/json"}],
+							"url": {
+								"raw": "{{baseUrl}}/users",
+								"host": ["{{baseUrl}}"],
+								"path": ["users"]
+							},
+							"body": {
+								"mode": "raw",
+								"raw": "{\n  \"age\": 0,\n  \"name\": \"string\"\n}",
+								"options": {"raw": {"language": "json"}}
+							}
+						}
+					},
+					{
+						"name": "getUser",
+						"request": {
+							"method": "GET",
+							"header": [],
+							"url": {
+								"raw": "{{baseUrl}}/users/:id",
+								"host": ["{{baseUrl}}"],
+								"path": ["users", ":id"],
+								"variable": [{"key": "id", "value": "0"}]
+							}
+						}
+					}
+				]
+			}
+		],
+		"variable": [{"key": "baseUrl", "value": ""}]
+	}
+	`)
+}
@@ -1,17 +1,19 @@
 package openapi3
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
 	"reflect"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"unicode"
 
 	"github.com/sv-tools/openapi"
 	"github.com/zhamlin/routey"
+	"github.com/zhamlin/routey/extractor"
 	"github.com/zhamlin/routey/internal"
 	"github.com/zhamlin/routey/internal/stringz"
 	"github.com/zhamlin/routey/jsonschema"
@@ -36,7 +38,17 @@ type Context struct {
 	OpenAPI   *OpenAPI
 	Validator *jsonschema.Validator
 	Namer     param.Namer
-	Parser    param.Parser
+	JSONNames bool
+	// FormTagKey names the properties of form bodies, see [param.Config].
+	FormTagKey string
+	Joiner     param.Joiner
+	Parser     param.Parser
+	// ParamDefaults are the style and explode values used
+	// for parameters that do not set them.
+	ParamDefaults openAPIParam.Defaults
+	// OnDeprecatedParam is called when a request contains
+	// a deprecated query parameter.
+	OnDeprecatedParam func(*http.Request, Parameter)
 }
 
 type contextKey struct{}
@@ -79,7 +91,7 @@ func compileBodySchema(ctx Context, op *Operation, s *openapi.RefOrSpec[openapi.
 		return err
 	}
 
-	b, err := json.Marshal(schema.JSONSchema())
+	b, err := ctx.OpenAPI.validationSchema(schema)
 	if err != nil {
 		return err
 	}
@@ -91,27 +103,119 @@ func compileBodySchema(ctx Context, op *Operation, s *openapi.RefOrSpec[openapi.
 	return nil
 }
 
-func addBodyToOp(ctx Context, info param.Info, o *Operation) error {
-	s, err := ctx.OpenAPI.GetSchemaOrRef(info.Type, SchemaRefOptions{
-		IgnoreAddSchemaErrors: true,
-	})
-	if err != nil {
+// tagToParamError converts a [jsonschema.TagError] into a [param.InvalidParamError].
+func tagToParamError(err error) error {
+	var tagErr *jsonschema.TagError
+	if !errors.As(err, &tagErr) {
 		return err
 	}
 
-	mt := NewMediaType()
-	mt.Schema = s
+	return routey.HandlerError{
+		Err: &param.InvalidParamError{
+			Struct:  tagErr.Struct,
+			Field:   tagErr.Field,
+			Message: fmt.Sprintf("failed to parse tag %q", tagErr.Tag),
+			Err:     tagErr.Err.Error(),
+		},
+	}
+}
+
+type bodyType struct {
+	contentType string
+	typ         reflect.Type
+}
+
+// bodyTypes returns the type and content type of each body the field
+// can be extracted as. Bodies default to the JSON content type.
+func bodyTypes(info param.Info) []bodyType {
+	type multiBody interface {
+		Bodies() []extractor.ContentTyper
+	}
+
+	value := reflect.New(info.Field.Type).Elem().Interface()
+	if b, ok := value.(multiBody); ok {
+		bodies := b.Bodies()
+		types := make([]bodyType, 0, len(bodies))
 
+		for _, body := range bodies {
+			_, typ, _ := param.GetSourceAndType(reflect.TypeOf(body))
+			types = append(types, bodyType{contentType: body.ContentType(), typ: typ})
+		}
+		return types
+	}
+
+	type contentTyper interface {
+		ContentTypes() []string
+	}
+
+	if c, ok := value.(contentTyper); ok {
+		contentTypes := c.ContentTypes()
+		types := make([]bodyType, 0, len(contentTypes))
+
+		for _, contentType := range contentTypes {
+			types = append(types, bodyType{contentType: contentType, typ: info.Type})
+		}
+		return types
+	}
+
+	contentType := JSONContentType
+	if c, ok := value.(extractor.ContentTyper); ok {
+		contentType = c.ContentType()
+	}
+	return []bodyType{{contentType: contentType, typ: info.Type}}
+}
+
+// bodySchema returns the schema of the body. The properties of forms are
+// named by their form tag and always inlined, as components are named
+// by the json tag.
+func bodySchema(ctx Context, b bodyType) (*openapi.RefOrSpec[openapi.Schema], error) {
+	if b.contentType != FormContentType {
+		return ctx.OpenAPI.GetSchemaOrRef(b.typ, SchemaRefOptions{
+			IgnoreAddSchemaErrors: true,
+		})
+	}
+
+	schemer := jsonschema.NewSchemer()
+	schemer.RefPath = ""
+	schemer.TagKey = extractor.FormTagKey(ctx.FormTagKey)
+	schemer.DefaultStructRequire = ctx.OpenAPI.Schemer.DefaultStructRequire
+
+	schema, err := schemer.Get(b.typ)
+	if err != nil {
+		return nil, fmt.Errorf("error getting schema: %w", err)
+	}
+	return openapi.NewRefOrSpec[openapi.Schema](schema.Schema), nil
+}
+
+func addBodyToOp(ctx Context, info param.Info, o *Operation) error {
 	body := RequestBody{}
-	body.SetContent(JSONContentType, mt)
-	body, err = updateRequestBodyFromTags(info.Field, body)
+	var jsonSchema *openapi.RefOrSpec[openapi.Schema]
 
+	for _, b := range bodyTypes(info) {
+		s, err := bodySchema(ctx, b)
+		if err != nil {
+			return tagToParamError(err)
+		}
+
+		mt := NewMediaType()
+		mt.Schema = s
+		body.SetContent(b.contentType, mt)
+
+		if b.contentType == JSONContentType {
+			jsonSchema = s
+		}
+	}
+
+	body, err := updateRequestBodyFromTags(info.Field, body)
 	if err != nil {
 		return err
 	}
 
 	o.SetRequestBody(body)
-	return compileBodySchema(ctx, o, s)
+	if jsonSchema == nil {
+		return nil
+	}
+	return compileBodySchema(ctx, o, jsonSchema)
 }
 
 func compileParamSchema(ctx Context, p Parameter) error {
@@ -120,13 +224,13 @@ func compileParamSchema(ctx Context, p Parameter) error {
 	}
 
 	name := "param." + p.Name
-	schema, err := ctx.OpenAPI.getSchemaSource(p.Schema)
+	schema, err := ctx.OpenAPI.getSchemaSource(p.ValueSchema())
 
 	if err != nil {
 		return err
 	}
 
-	b, err := json.Marshal(schema.JSONSchema())
+	b, err := ctx.OpenAPI.validationSchema(schema)
 	if err != nil {
 		return err
 	}
@@ -138,32 +242,42 @@ func compileParamSchema(ctx Context, p Parameter) error {
 	return nil
 }
 
-func addParamToOp(ctx Context, i param.Info, o *Operation) error {
+// paramSchemaTags are the tags of params changing their schema.
+var paramSchemaTags = []string{"minimum", "minLength", "maxLength", "enum", "timeformat"}
+
+// paramCanRef returns true when the param schema can reference the schema of
+// its registered type, such as an enum, instead of inlining it. Params with a
+// default or tags changing the schema, like minimum or enum, are always inlined.
+func paramCanRef(schemer jsonschema.Schemer, i param.Info) bool {
+	hasSchemaTag := slices.ContainsFunc(paramSchemaTags, func(tag string) bool {
+		_, has := i.Field.Tag.Lookup(tag)
+		return has
+	})
+	return schemer.Has(i.Type) && i.Default == "" && !hasSchemaTag
+}
+
+func addParamToOp(ctx Context, i param.Info, o *Operation, info *route.Info) error {
 	spec := ctx.OpenAPI
-	p, err := openAPIParam.FromInfo(i, spec.Schemer)
+	p, err := openAPIParam.FromInfoWithOptions(i, spec.Schemer, openAPIParam.Options{
+		Defaults: ctx.ParamDefaults,
+		Parser:   ctx.Parser,
+	})
 
 	if err != nil {
 		return fmt.Errorf("openapi.FromInfo: %w", err)
 	}
 
-	if i.Default != "" {
-		v := reflect.New(i.Type)
-		if err := ctx.Parser(v.Interface(), []string{i.Default}); err != nil {
-			return fmt.Errorf("failed parsing default: %w", err)
-		}
-		p.Schema.Spec.Default = v.Elem().Interface()
-	}
-
 	if !o.HasParameter(p) {
 		isDeepObject := p.Style == string(openAPIParam.StyleDeepObject)
-		if isDeepObject {
-			p.Schema, err = spec.GetSchemaOrRef(
+		if isDeepObject || paramCanRef(spec.Schemer, i) {
+			schema, err := spec.GetSchemaOrRef(
 				i.Type,
 				SchemaRefOptions{IgnoreAddSchemaErrors: true},
 			)
 			if err != nil {
 				return err
 			}
+			p.SetValueSchema(schema)
 		}
 
 		o.AddParameter(p)
@@ -171,6 +285,100 @@ func addParamToOp(ctx Context, i param.Info, o *Operation) error {
 		if err := compileParamSchema(ctx, p); err != nil {
 			return err
 		}
+
+		if err := compileFieldSchemas(ctx, p, i.Type, info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fieldValidatorKey is the key of the [jsonschema.Validator] in the route
+// context holding the schemas of the fields of object params.
+type fieldValidatorKey struct{}
+
+func fieldValidatorFromCtx(ctx route.Context) *jsonschema.Validator {
+	v, _ := ctx[fieldValidatorKey{}].(*jsonschema.Validator)
+	return v
+}
+
+// fieldSchemaName returns the name of the schema of the field of the param
+// in the field validator, from the json names of it and the fields containing it.
+func fieldSchemaName(p Parameter, fields []string) string {
+	return "param." + p.In + "." + p.Name + "." + strings.Join(fields, ".")
+}
+
+// compileFieldSchemas adds the schema of each field of an object param, such
+// as a deepObject, to the field validator of the route. The fields are always
+// validated when parsed, unlike other params only validated by ValidateRequests.
+func compileFieldSchemas(ctx Context, p Parameter, typ reflect.Type, info *route.Info) error {
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	switch openAPIParam.Style(p.Style) {
+	case openAPIParam.StyleDeepObject, openAPIParam.StyleLabel, openAPIParam.StyleMatrix:
+	default:
+		return nil
+	}
+
+	if typ.Kind() != reflect.Struct || parseable(ctx.Parser, reflect.New(typ).Interface()) {
+		return nil
+	}
+
+	schema, err := ctx.OpenAPI.getSchemaSource(p.ValueSchema())
+	if err != nil {
+		return err
+	}
+
+	validator := fieldValidatorFromCtx(info.Context)
+	if validator == nil {
+		validator = jsonschema.NewValidator()
+		info.Context[fieldValidatorKey{}] = validator
+	}
+	return addFieldSchemas(ctx, validator, p, typ, schema.JSONSchema(), nil)
+}
+
+func addFieldSchemas(
+	ctx Context,
+	validator *jsonschema.Validator,
+	p Parameter,
+	typ reflect.Type,
+	schema jsonschema.Schema,
+	parents []string,
+) error {
+	for i, nested := range nestedFields(ctx.Parser, typ) {
+		f := typ.Field(i)
+		jsonName := jsonschema.JSONFieldName(f)
+		fields := append(parents[:len(parents):len(parents)], jsonName)
+
+		property := schema.Properties[jsonName]
+		if property == nil {
+			continue
+		}
+
+		prop, err := ctx.OpenAPI.getSchemaSource(property)
+		if err != nil {
+			return err
+		}
+
+		if nested {
+			err := addFieldSchemas(ctx, validator, p, f.Type, prop.JSONSchema(), fields)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		b, err := ctx.OpenAPI.validationSchema(prop)
+		if err != nil {
+			return err
+		}
+
+		name := fieldSchemaName(p, fields)
+		if err := validator.Add(name, string(b)); err != nil {
+			return fmt.Errorf("compling schema(%s) failed: %w", name, err)
+		}
 	}
 	return nil
 }
@@ -197,7 +405,7 @@ func addParam(p param.Info, ctx Context, o *Operation, info *route.Info) error {
 	if p.Source == "body" {
 		err = addBodyToOp(ctx, p, o)
 	} else {
-		err = addParamToOp(ctx, p, o)
+		err = addParamToOp(ctx, p, o, info)
 	}
 
 	var hErr routey.HandlerError
@@ -246,6 +454,10 @@ func ensureOperationID(spec *OpenAPI, operation *Operation, info *route.Info) er
 		operation.OperationID = getPublicFunctionName(info.Handler)
 	}
 
+	if info.Any && operation.OperationID != "" {
+		operation.OperationID += "_" + strings.ToLower(info.Method)
+	}
+
 	if spec.Strict {
 		if operation.OperationID == "" {
 			return routey.HandlerError{
@@ -267,15 +479,74 @@ func ensureOperationID(spec *OpenAPI, operation *Operation, info *route.Info) er
 	return nil
 }
 
+// setDefaultResponseIfAvailable sets the default response from the
+// components, unless the operation has its own default response.
 func setDefaultResponseIfAvailable(spec *OpenAPI, operation *Operation) {
+	if operation.Responses != nil && operation.Responses.Spec.Default != nil {
+		return
+	}
+
 	// TODO: get all default responses
 	if resp, has := spec.GetDefaultResponse(0); has {
 		operation.SetDefaultResponse(resp)
 	}
 }
 
+func withoutContent(
+	resp *openapi.RefOrSpec[openapi.Extendable[openapi.Response]],
+) *openapi.RefOrSpec[openapi.Extendable[openapi.Response]] {
+	if resp == nil || resp.Spec == nil {
+		return resp
+	}
+
+	r := *resp.Spec.Spec
+	r.Content = nil
+	return openapi.NewRefOrSpec[openapi.Extendable[openapi.Response]](NewExtendable(&r))
+}
+
+// headOperation returns a copy of the GET operation with all response
+// content removed, as HEAD responses do not have a body.
+func headOperation(get Operation) Operation {
+	op := *get.Operation
+	if op.OperationID != "" {
+		op.OperationID += "Head"
+	}
+
+	if get.Responses != nil {
+		responses := *get.Responses.Spec
+		responses.Default = withoutContent(responses.Default)
+		responses.Response = map[string]*openapi.RefOrSpec[openapi.Extendable[openapi.Response]]{}
+
+		for code, resp := range get.Responses.Spec.Response {
+			responses.Response[code] = withoutContent(resp)
+		}
+		op.Responses = NewExtendable(&responses)
+	}
+
+	return Operation{Operation: &op, Extensions: get.Extensions}
+}
+
+func setHeadOperation(spec *OpenAPI, info *route.Info) {
+	path, has := spec.GetPath(info.FullPattern)
+	if !has {
+		return
+	}
+
+	// the GET operation will not exist if it was ignored
+	if get, has := path.GetOperation(http.MethodGet); has {
+		path.SetOperation(http.MethodHead, headOperation(get))
+	}
+}
+
 func newOnRouteAdd(spec *OpenAPI) func(*route.Info) error {
 	return func(info *route.Info) error {
+		if info.DerivedFrom != nil {
+			if spec.DocumentAutoHead && info.Method == http.MethodHead {
+				setHeadOperation(spec, info)
+			}
+			return nil
+		}
+
 		path, has := spec.GetPath(info.FullPattern)
 		if !has {
 			path = NewPathItem()
@@ -296,6 +567,16 @@ func newOnRouteAdd(spec *OpenAPI) func(*route.Info) error {
 			return err
 		}
 
+		if spec.Strict {
+			if err := spec.validateResponseExamples(operation); err != nil {
+				return routey.HandlerError{
+					Pattern: info.Method + " " + info.FullPattern,
+					Handler: internal.GetFnInfo(info.Handler),
+					Err:     fmt.Errorf("error: openapi: %w", err),
+				}
+			}
+		}
+
 		c, err := ContextFromCtx(info.Context)
 		if err != nil {
 			return err
@@ -307,6 +588,14 @@ func newOnRouteAdd(spec *OpenAPI) func(*route.Info) error {
 			}
 		}
 
+		if err := operation.validateMutuallyExclusiveNames(); err != nil {
+			return routey.HandlerError{
+				Pattern: info.Method + " " + info.FullPattern,
+				Handler: internal.GetFnInfo(info.Handler),
+				Err:     err,
+			}
+		}
+
 		setDefaultResponseIfAvailable(spec, operation)
 		path.SetOperation(info.Method, *operation)
 		spec.SetPath(info.FullPattern, path)
@@ -321,20 +610,47 @@ type AddSpecToRouterOpts struct {
 	// Strict determines whether or not an error is thrown
 	// if required properties are not set on OpenAPI resources.
 	Strict bool
+	// DocumentAutoHead adds HEAD operations to the spec for any
+	// HEAD routes created by [routey.Router.AutoHead].
+	DocumentAutoHead bool
+	// ParamDefaults overrides the style and explode values used for
+	// parameters that do not set them. Locations not present use the
+	// defaults from the OpenAPI specification.
+	ParamDefaults openAPIParam.Defaults
+	// OnDeprecatedParam is called when a request contains a deprecated
+	// query parameter, such as to log its usage. The parameters description
+	// contains the deprecation note, if any.
+	OnDeprecatedParam func(*http.Request, Parameter)
+	// ErrorResponse is the type of the error body shared by all operations.
+	// It is added as the default response of the components, and set as the
+	// default response of every operation without its own.
+	ErrorResponse reflect.Type
 }
 
 func AddSpecToRouter(r *routey.Router, opts AddSpecToRouterOpts) *OpenAPI {
 	spec := New()
 	spec.Strict = opts.Strict
+	spec.DocumentAutoHead = opts.DocumentAutoHead
 
 	if typ := opts.DefaultContentType; typ != "" {
 		spec.DefaultContentType = typ
 	}
 
+	if typ := opts.ErrorResponse; typ != nil {
+		setDefaultResponse(spec, typ, 0)
+	}
+
 	ctx := Context{
 		OpenAPI: spec,
-		Parser:  r.Params.Parser,
+		Parser:  r.Params.GetParser(),
 		Namer:   r.Params.Namer,
+		Joiner:  r.Params.Joiner,
+
+		JSONNames:  r.Params.JSONNames,
+		FormTagKey: r.Params.FormTagKey,
+
+		ParamDefaults:     opts.ParamDefaults,
+		OnDeprecatedParam: opts.OnDeprecatedParam,
 	}
 
 	if opts.ValidateRequests {
@@ -346,10 +662,87 @@ func AddSpecToRouter(r *routey.Router, opts AddSpecToRouterOpts) *OpenAPI {
 		contextKey{}: ctx,
 	}
 	r.OnRouteAdd = newOnRouteAdd(spec)
+	spec.routerCtx = &ctx
 
 	return spec
 }
 
+// SubRouter returns a new router using the same [Context] as the router
+// the spec was added to. Routes added to the sub router are documented
+// in the spec when it is mounted on a router using the spec.
+func (o *OpenAPI) SubRouter() *routey.Router {
+	ctx := Context{OpenAPI: o}
+	if o.routerCtx != nil {
+		ctx = *o.routerCtx
+	}
+	return newRouterWithContext(ctx)
+}
+
+func newRouterWithContext(ctx Context) *routey.Router {
+	r := routey.New()
+	if ctx.Parser != nil {
+		r.Params.Parser = ctx.Parser
+	}
+	if ctx.Namer != nil {
+		r.Params.Namer = ctx.Namer
+	}
+	r.Params.Joiner = ctx.Joiner
+	r.Params.JSONNames = ctx.JSONNames
+	r.Params.FormTagKey = ctx.FormTagKey
+
+	r.Context = route.Context{
+		contextKey{}: ctx,
+	}
+	return r
+}
+
+// NewCallback returns a callback documenting the routes fn adds to the router
+// as the requests sent by the API to the url of the runtime expression, such as
+// "{$request.body#/callbackUrl}". The path of each route is appended to the
+// expression. Schemas used by the routes are added to the components of o.
+func (o *OpenAPI) NewCallback(
+	expression string,
+	fn func(*routey.Router),
+) (*openapi.RefOrSpec[openapi.Extendable[openapi.Callback]], error) {
+	spec := New()
+	spec.Schemer = o.Schemer
+	spec.DefaultContentType = o.DefaultContentType
+	o.GetComponents()
+	spec.Components = o.Components
+
+	ctx := Context{}
+	if o.routerCtx != nil {
+		ctx = *o.routerCtx
+	}
+	// the requests are sent by the API, so there is nothing to validate
+	ctx.OpenAPI = spec
+	ctx.Validator = nil
+
+	var errs []error
+	r := newRouterWithContext(ctx)
+	r.ErrorSink = func(err error) {
+		errs = append(errs, err)
+	}
+	r.OnRouteAdd = newOnRouteAdd(spec)
+	fn(r)
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	callback := openapi.NewCallbackBuilder()
+	if spec.Paths != nil {
+		for path, item := range spec.Paths.Spec.Paths {
+			key := expression
+			if path != "/" {
+				key += path
+			}
+			callback.AddPathItem(key, item)
+		}
+	}
+	return callback.Build(), nil
+}
+
 func NewRouter() (*routey.Router, *OpenAPI) {
 	r := routey.New()
 	spec := AddSpecToRouter(r, AddSpecToRouterOpts{})
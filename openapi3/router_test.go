@@ -1,10 +1,16 @@
 package openapi3_test
 
 import (
+	"encoding/json"
+	"encoding/xml"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"reflect"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/zhamlin/routey"
 	"github.com/zhamlin/routey/extractor"
@@ -12,6 +18,8 @@ import (
 	"github.com/zhamlin/routey/jsonschema"
 	"github.com/zhamlin/routey/openapi3"
 	"github.com/zhamlin/routey/openapi3/option"
+	openAPIParam "github.com/zhamlin/routey/openapi3/param"
+	"github.com/zhamlin/routey/param"
 	"github.com/zhamlin/routey/route"
 )
 
@@ -73,6 +81,38 @@ func TestRouter_DefaultResponse(t *testing.T) {
 	test.MatchAsJSON(t, got, want)
 }
 
+func TestRouter_ErrorResponse(t *testing.T) {
+	type ErrorBody struct {
+		Message string `json:"message"`
+	}
+
+	r := routey.New()
+	spec := openapi3.AddSpecToRouter(r, openapi3.AddSpecToRouterOpts{
+		ErrorResponse: reflect.TypeFor[ErrorBody](),
+	})
+	routey.Get(r, "/users", HandlerForTests)
+	routey.Post(r, "/users", HandlerForTests)
+	routey.Get(r, "/posts", HandlerForTests)
+
+	want := openapi3.Response{}
+	{
+		mt := openapi3.NewMediaType()
+		mt.SetSchemaRef(spec.Schemer.RefPath + "ErrorBody")
+		want.SetContent(openapi3.JSONContentType, mt)
+	}
+
+	operations := 0
+	for path, item := range spec.Paths.Spec.Paths {
+		pathItem := openapi3.PathItem{PathItem: item.Spec.Spec}
+		for _, op := range pathItem.GetOperations() {
+			operations++
+			got := op.Operation.Responses.Spec.Default
+			test.MatchAsJSON(t, got, want, op.Method+" "+path)
+		}
+	}
+	test.Equal(t, operations, 3)
+}
+
 func TestRouter_ValidJSONBodyParam(t *testing.T) {
 	type Body struct{ Field string }
 	type Input struct {
@@ -358,6 +398,242 @@ func TestRouter_SpecWithMount(t *testing.T) {
 	`)
 }
 
+type listedHandler struct {
+	http.Handler
+	routes []*route.Info
+}
+
+func (h listedHandler) Routes() []*route.Info { return h.routes }
+
+func TestRouter_SpecWithMountedRouteLister(t *testing.T) {
+	r, spec := newTestRouter(t)
+	r.Mount("/v1", listedHandler{
+		Handler: http.NotFoundHandler(),
+		routes: []*route.Info{
+			{Method: http.MethodGet, Pattern: "/users", FullPattern: "/users"},
+		},
+	})
+
+	test.MatchAsJSON(t, spec.Paths, `
+	{
+		"/v1/users": {
+			"get": {}
+		}
+	}
+	`)
+}
+
+func TestRouter_SubRouterSharesSpec(t *testing.T) {
+	type input struct {
+		ID routey.Path[int]
+	}
+
+	var got int
+	h := func(i input) (any, error) {
+		got = i.ID.Value
+		return nil, nil
+	}
+
+	r, spec := newTestRouter(t)
+	subRouter := spec.SubRouter()
+	subRouter.ErrorSink = r.ErrorSink
+	subRouter.Response = r.Response
+
+	routey.Get(subRouter, "/{id}", h, option.ID("getItem"))
+	r.Mount("/v1", subRouter)
+
+	_, has := spec.GetPath("/{id}")
+	test.Equal(t, has, false)
+
+	path, has := spec.GetPath("/v1/{id}")
+	test.Equal(t, has, true)
+
+	op, has := path.GetOperation(http.MethodGet)
+	test.Equal(t, has, true)
+	test.Equal(t, op.OperationID, "getItem")
+
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/v1/2", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	test.Equal(t, got, 2)
+}
+
+func TestRouter_OneOfBodySpec(t *testing.T) {
+	type body struct {
+		Name string `json:"name" form:"full_name"`
+	}
+	type input struct {
+		Body routey.OneOfBody[routey.JSON[body], routey.Form[body]]
+	}
+	h := func(input) (any, error) { return nil, nil }
+
+	r, spec := newTestRouter(t)
+	routey.Post(r, "/", h, option.ID("create"))
+
+	path, _ := spec.GetPath("/")
+	test.MatchAsJSON(t, path, `
+	{
+		"post": {
+			"operationId": "create",
+			"requestBody": {
+				"content": {
+					"application/json": {
+						"schema": {"$ref": "#/components/schemas/body"}
+					},
+					"application/x-www-form-urlencoded": {
+						"schema": {
+							"type": "object",
+							"properties": {
+								"full_name": {"type": "string"}
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	`)
+}
+
+func TestRouter_FormTagKey(t *testing.T) {
+	type body struct {
+		Name string `json:"name" field:"full_name"`
+	}
+	type input struct {
+		Body routey.Form[body]
+	}
+
+	var got body
+	h := func(in input) (any, error) {
+		got = in.Body.V
+		return nil, nil
+	}
+
+	r := routey.New()
+	r.Params.FormTagKey = "field"
+	spec := openapi3.AddSpecToRouter(r, openapi3.AddSpecToRouterOpts{})
+	routey.Post(r, "/", h, option.ID("create"))
+
+	path, _ := spec.GetPath("/")
+	schema := path.Post.Spec.RequestBody.Spec.Spec.Content[openapi3.FormContentType].Spec.Schema
+	test.MatchAsJSON(t, schema, `
+	{
+		"type": "object",
+		"properties": {
+			"full_name": {"type": "string"}
+		}
+	}
+	`)
+
+	req := httptest.NewRequestWithContext(
+		t.Context(),
+		http.MethodPost,
+		"/",
+		strings.NewReader("full_name=bob"),
+	)
+	req.Header.Set("Content-Type", openapi3.FormContentType)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	test.Equal(t, got.Name, "bob")
+}
+
+func TestRouter_BodySpec(t *testing.T) {
+	type body struct {
+		Name string `json:"name" xml:"name" form:"name"`
+	}
+	type input struct {
+		Body routey.Body[body]
+	}
+	h := func(input) (any, error) { return nil, nil }
+
+	r, spec := newTestRouter(t)
+	routey.Post(r, "/", h, option.ID("create"))
+
+	path, _ := spec.GetPath("/")
+	test.MatchAsJSON(t, path, `
+	{
+		"post": {
+			"operationId": "create",
+			"requestBody": {
+				"content": {
+					"application/json": {
+						"schema": {"$ref": "#/components/schemas/body"}
+					},
+					"application/x-www-form-urlencoded": {
+						"schema": {
+							"type": "object",
+							"properties": {
+								"name": {"type": "string", "xml": {"name": "name"}}
+							}
+						}
+					},
+					"application/xml": {
+						"schema": {"$ref": "#/components/schemas/body"}
+					}
+				}
+			}
+		}
+	}
+	`)
+}
+
+func TestRouter_XMLBodySpec(t *testing.T) {
+	type user struct {
+		XMLName xml.Name `xml:"user"`
+		ID      int      `json:"id" xml:"id,attr"`
+		Name    string   `json:"name" xml:"name"`
+	}
+	type input struct {
+		Body routey.XML[user]
+	}
+	h := func(input) (any, error) { return nil, nil }
+
+	r, spec := newTestRouter(t)
+	routey.Post(r, "/", h,
+		option.ID("create"),
+		option.ContentType(
+			[]string{openapi3.XMLContentType},
+			option.Response[user](http.StatusCreated, "created"),
+		),
+	)
+
+	path, _ := spec.GetPath("/")
+	test.MatchAsJSON(t, path, `
+	{
+		"post": {
+			"operationId": "create",
+			"requestBody": {
+				"content": {
+					"application/xml": {
+						"schema": {"$ref": "#/components/schemas/user"}
+					}
+				}
+			},
+			"responses": {
+				"201": {
+					"description": "created",
+					"content": {
+						"application/xml": {
+							"schema": {"$ref": "#/components/schemas/user"}
+						}
+					}
+				}
+			}
+		}
+	}
+	`)
+
+	schema, _ := spec.Components.Spec.Schemas["user"].GetSpec(spec.Components)
+	test.MatchAsJSON(t, schema, `
+	{
+		"type": "object",
+		"xml": {"name": "user"},
+		"properties": {
+			"id": {"type": "integer", "xml": {"name": "id", "attribute": true}},
+			"name": {"type": "string", "xml": {"name": "name"}}
+		}
+	}
+	`)
+}
+
 type object struct {
 	Field string `json:"field"`
 }
@@ -399,6 +675,135 @@ func TestRouterValidateRequest_MiddlewareDeepObjectError(t *testing.T) {
 	r.ServeHTTP(w, req)
 }
 
+func TestRouterValidateRequest_NestedDeepObject(t *testing.T) {
+	type priceRange struct {
+		Min int `json:"min" minimum:"0"`
+		Max int `json:"max" default:"100"`
+	}
+	type filter struct {
+		Name  string     `json:"name"`
+		Range priceRange `json:"range"`
+	}
+	type input struct {
+		Filter openapi3.Query[filter] `style:"deepObject"`
+	}
+
+	var got filter
+	h := func(p input) (any, error) {
+		got = p.Filter.Value
+		return nil, nil
+	}
+
+	r := routey.New()
+	openapi3.AddSpecToRouter(r, openapi3.AddSpecToRouterOpts{
+		ValidateRequests: true,
+	})
+
+	var gotErr error
+	r.Response = func(_ http.ResponseWriter, _ *http.Request, resp extractor.Response) {
+		gotErr = resp.Error
+	}
+	routey.Get(r, "/", h, option.ID("id"))
+
+	query := "/?filter[name]=a&filter[range][min]=1"
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequestWithContext(t.Context(), http.MethodGet, query, nil))
+	test.NoError(t, gotErr)
+	test.Equal(t, got, filter{Name: "a", Range: priceRange{Min: 1, Max: 100}})
+
+	query = "/?filter[range][min]=-1"
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequestWithContext(t.Context(), http.MethodGet, query, nil))
+	var want jsonschema.ValidationError
+	test.WantError(t, gotErr, &want)
+}
+
+type deepObjectFilter struct {
+	Status string `json:"status" enum:"active,inactive"`
+	Name   string `json:"name" pattern:"^[a-z]+$"`
+	Count  int    `json:"count" minimum:"1"`
+}
+
+func TestRouterValidateRequest_DeepObjectFields(t *testing.T) {
+	type input struct {
+		Filter openapi3.Query[deepObjectFilter] `style:"deepObject"`
+	}
+	h := func(input) (any, error) { return nil, nil }
+
+	tests := []struct {
+		query    string
+		validate bool
+		wantErr  any
+		errName  string
+	}{
+		{query: "/?filter[status]=active&filter[name]=a&filter[count]=1", validate: true},
+		{query: "/?filter[status]=active&filter[name]=a"},
+		// fields are validated against their schema without validating requests
+		{query: "/?filter[status]=bogus", wantErr: new(param.NotInEnumError), errName: "filter[status]"},
+		{query: "/?filter[status]=bogus", validate: true, wantErr: new(param.NotInEnumError)},
+		{query: "/?filter[name]=A1", wantErr: new(jsonschema.ValidationError), errName: "filter[name]"},
+		{query: "/?filter[name]=A1", validate: true, wantErr: new(jsonschema.ValidationError)},
+		{query: "/?filter[count]=0", wantErr: new(jsonschema.ValidationError), errName: "filter[count]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			r := routey.New()
+			openapi3.AddSpecToRouter(r, openapi3.AddSpecToRouterOpts{
+				ValidateRequests: tt.validate,
+			})
+
+			var gotErr error
+			r.Response = func(_ http.ResponseWriter, _ *http.Request, resp extractor.Response) {
+				gotErr = resp.Error
+			}
+			routey.Get(r, "/", h, option.ID("id"))
+
+			req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, tt.query, nil)
+			r.ServeHTTP(httptest.NewRecorder(), req)
+
+			if tt.wantErr == nil {
+				test.NoError(t, gotErr)
+				return
+			}
+			test.WantError(t, gotErr, tt.wantErr)
+
+			if tt.errName != "" && !strings.Contains(gotErr.Error(), tt.errName) {
+				t.Errorf("wanted error naming %s, got: %v", tt.errName, gotErr)
+			}
+		})
+	}
+}
+
+func TestRouter_DeepObjectFieldsPerRoute(t *testing.T) {
+	type anyCount struct {
+		Count int `json:"count"`
+	}
+	type strict struct {
+		Filter openapi3.Query[deepObjectFilter] `style:"deepObject"`
+	}
+	type loose struct {
+		Filter openapi3.Query[anyCount] `style:"deepObject"`
+	}
+
+	r := routey.New()
+	openapi3.AddSpecToRouter(r, openapi3.AddSpecToRouterOpts{})
+
+	var gotErr error
+	r.Response = func(_ http.ResponseWriter, _ *http.Request, resp extractor.Response) {
+		gotErr = resp.Error
+	}
+	routey.Get(r, "/strict", func(strict) (any, error) { return nil, nil }, option.ID("strict"))
+	routey.Get(r, "/loose", func(loose) (any, error) { return nil, nil }, option.ID("loose"))
+
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/loose?filter[count]=0", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	test.NoError(t, gotErr)
+
+	req = httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/strict?filter[count]=0", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	var want jsonschema.ValidationError
+	test.WantError(t, gotErr, &want)
+}
+
 func TestRouterValidateRequest_QueryError(t *testing.T) {
 	type input struct {
 		Int openapi3.Query[int] `minimum:"2"`
@@ -414,6 +819,7 @@ func TestRouterValidateRequest_QueryError(t *testing.T) {
 	r.Response = func(_ http.ResponseWriter, _ *http.Request, resp extractor.Response) {
 		var want jsonschema.ValidationError
 		test.WantError(t, resp.Error, &want)
+		test.Equal(t, resp.Status, http.StatusBadRequest)
 		*gotError = true
 	}
 
@@ -428,17 +834,1140 @@ func TestRouterValidateRequest_QueryError(t *testing.T) {
 	r.ServeHTTP(w, req)
 }
 
-func TestRouter_DuplicateOperationIDs(t *testing.T) {
-	h := func(struct{}) (any, error) { return nil, nil }
+func TestRouterValidateRequest_DurationQuery(t *testing.T) {
+	type input struct {
+		Timeout openapi3.Query[time.Duration] `default:"30s"`
+	}
+
+	var got time.Duration
+	h := func(p input) (any, error) {
+		got = p.Timeout.Value
+		return nil, nil
+	}
+
+	r := routey.New()
+	spec := openapi3.AddSpecToRouter(r, openapi3.AddSpecToRouterOpts{
+		ValidateRequests: true,
+	})
+	r.Response = func(_ http.ResponseWriter, _ *http.Request, resp extractor.Response) {
+		test.NoError(t, resp.Error)
+	}
+
+	routey.Get(r, "/", h, option.ID("id"))
+
+	test.MatchAsJSON(t, spec.Paths, `
+	{
+		"/": {
+			"get": {
+				"operationId": "id",
+				"parameters": [
+					{
+						"in": "query",
+						"explode": true,
+						"name": "timeout",
+						"style": "form",
+						"schema": {
+							"type": "string",
+							"format": "go-duration",
+							"pattern": "^[-+]?(0|(([0-9]+(\\.[0-9]*)?|\\.[0-9]+)(ns|us|µs|μs|ms|s|m|h))+)$",
+							"default": "30s"
+						}
+					}
+				]
+			}
+		}
+	}
+	`)
+
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/?timeout=1h30m", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	test.Equal(t, got, 90*time.Minute)
+}
+
+func TestRouter_SubRouterAddParser(t *testing.T) {
+	type input struct {
+		Level openapi3.Query[level]
+	}
+
+	var got level
+	h := func(p input) (any, error) {
+		got = p.Level.Value
+		return nil, nil
+	}
+
 	r, spec := newTestRouter(t)
-	spec.Strict = true
+	openapi3.RegisterType[level](spec, jsonschema.NewBuilder().Type("string").Build())
 
-	gotError := test.WantAfterTest(t, false, true, "expected an error, got none")
-	r.ErrorSink = func(err error) {
-		test.IsError(t, err, openapi3.ErrDuplicateOperationID)
-		*gotError = true
+	sub := spec.SubRouter()
+	sub.Params.AddParser(parseLevel)
+	routey.Get(sub, "/", h, option.ID("id"))
+
+	r.Mount("/sub", sub)
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/sub/?level=high", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	test.Equal(t, got, levelHigh)
+}
+
+type level int
+
+const levelHigh level = 2
+
+func parseLevel(value any, params []string) error {
+	v, ok := value.(*level)
+	if !ok {
+		return param.ErrInvalidParamType
 	}
+	if params[0] == "high" {
+		*v = levelHigh
+	}
+	return nil
+}
 
-	routey.Get(r, "/foo", h, option.ID("id"))
-	routey.Get(r, "/bar", h, option.ID("id"))
+func TestRouterValidateRequest_DelimitedQuery(t *testing.T) {
+	type input struct {
+		Pipe  openapi3.Query[[]string] `style:"pipeDelimited" explode:"false"`
+		Space openapi3.Query[[]int]    `style:"spaceDelimited" explode:"false"`
+	}
+
+	var got input
+	h := func(p input) (any, error) {
+		got = p
+		return nil, nil
+	}
+
+	r := routey.New()
+	openapi3.AddSpecToRouter(r, openapi3.AddSpecToRouterOpts{
+		ValidateRequests: true,
+	})
+	r.Response = func(_ http.ResponseWriter, _ *http.Request, resp extractor.Response) {
+		test.NoError(t, resp.Error)
+	}
+
+	routey.Get(r, "/", h, option.ID("id"))
+
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/?pipe=a|b|c&space=1%202", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	test.MatchAsJSON(t, got.Pipe.Value, []string{"a", "b", "c"})
+	test.MatchAsJSON(t, got.Space.Value, []int{1, 2})
+}
+
+func TestRouterValidateRequest_TimeQuery(t *testing.T) {
+	type input struct {
+		Since openapi3.Query[time.Time]
+	}
+
+	var got time.Time
+	h := func(p input) (any, error) {
+		got = p.Since.Value
+		return nil, nil
+	}
+
+	r := routey.New()
+	spec := openapi3.AddSpecToRouter(r, openapi3.AddSpecToRouterOpts{
+		ValidateRequests: true,
+	})
+	r.Response = func(_ http.ResponseWriter, _ *http.Request, resp extractor.Response) {
+		test.NoError(t, resp.Error)
+	}
+
+	routey.Get(r, "/", h, option.ID("id"))
+
+	test.MatchAsJSON(t, spec.Paths, `
+	{
+		"/": {
+			"get": {
+				"operationId": "id",
+				"parameters": [
+					{
+						"in": "query",
+						"explode": true,
+						"name": "since",
+						"style": "form",
+						"schema": {
+							"type": "string",
+							"format": "date-time"
+						}
+					}
+				]
+			}
+		}
+	}
+	`)
+
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/?since=2024-01-02", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	test.Equal(t, got, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC))
+}
+
+func TestRouterValidateRequest_TimeFormatQuery(t *testing.T) {
+	type input struct {
+		Day openapi3.Query[time.Time] `timeformat:"2006-01-02" default:"2024-01-01"`
+	}
+
+	var got time.Time
+	h := func(p input) (any, error) {
+		got = p.Day.Value
+		return nil, nil
+	}
+
+	r := routey.New()
+	spec := openapi3.AddSpecToRouter(r, openapi3.AddSpecToRouterOpts{
+		ValidateRequests: true,
+	})
+
+	var gotErr error
+	r.Response = func(_ http.ResponseWriter, _ *http.Request, resp extractor.Response) {
+		gotErr = resp.Error
+	}
+
+	routey.Get(r, "/", h, option.ID("id"))
+
+	test.MatchAsJSON(t, spec.Paths, `
+	{
+		"/": {
+			"get": {
+				"operationId": "id",
+				"parameters": [
+					{
+						"in": "query",
+						"explode": true,
+						"name": "day",
+						"style": "form",
+						"schema": {
+							"type": "string",
+							"format": "date",
+							"default": "2024-01-01"
+						}
+					}
+				]
+			}
+		}
+	}
+	`)
+
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/?day=2024-03-04", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	test.NoError(t, gotErr)
+	test.Equal(t, got, time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC))
+
+	// only the layout of the tag is accepted
+	req = httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/?day=2024-03-04T00:00:00Z", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	if gotErr == nil {
+		t.Error("expected an error parsing a time not in the layout")
+	}
+}
+
+func TestRouterValidateRequest_MutuallyExclusiveQuery(t *testing.T) {
+	type input struct {
+		ID   openapi3.Query[int]
+		Name openapi3.Query[string]
+	}
+	h := func(p input) (any, error) { return nil, nil }
+
+	tests := []struct {
+		query   string
+		wantErr bool
+	}{
+		{query: "/?id=1"},
+		{query: "/?name=test"},
+		{query: "/"},
+		{query: "/?id=1&name=test", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			r := routey.New()
+			openapi3.AddSpecToRouter(r, openapi3.AddSpecToRouterOpts{
+				ValidateRequests: true,
+			})
+
+			var gotErr error
+			r.Response = func(_ http.ResponseWriter, _ *http.Request, resp extractor.Response) {
+				gotErr = resp.Error
+			}
+
+			routey.Get(r, "/", h, option.ID("id"), option.MutuallyExclusive("id", "name"))
+			req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, tt.query, nil)
+			r.ServeHTTP(httptest.NewRecorder(), req)
+
+			if !tt.wantErr {
+				test.NoError(t, gotErr)
+				return
+			}
+
+			var want jsonschema.ValidationError
+			test.WantError(t, gotErr, &want)
+		})
+	}
+}
+
+func TestRouter_MutuallyExclusiveWithoutValidation(t *testing.T) {
+	type input struct {
+		ID   openapi3.Query[int]
+		Name openapi3.Query[string]
+	}
+	h := func(p input) (any, error) { return nil, nil }
+
+	r := routey.New()
+	openapi3.AddSpecToRouter(r, openapi3.AddSpecToRouterOpts{})
+
+	var gotErr error
+	r.Response = func(_ http.ResponseWriter, _ *http.Request, resp extractor.Response) {
+		gotErr = resp.Error
+	}
+
+	routey.Get(r, "/", h, option.ID("id"), option.MutuallyExclusive("id", "name"))
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/?id=1&name=test", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	var want jsonschema.ValidationError
+	test.WantError(t, gotErr, &want)
+}
+
+func TestRouter_MutuallyExclusiveUnknownParam(t *testing.T) {
+	type input struct {
+		ID openapi3.Query[int]
+	}
+	h := func(p input) (any, error) { return nil, nil }
+
+	r := routey.New()
+	haveErr := false
+	r.ErrorSink = func(err error) {
+		haveErr = true
+		test.IsError(t, err, openapi3.ErrUnknownMutuallyExclusiveParam)
+	}
+	openapi3.AddSpecToRouter(r, openapi3.AddSpecToRouterOpts{})
+	routey.Get(r, "/", h, option.ID("id"), option.MutuallyExclusive("id", "nmae"))
+
+	if !haveErr {
+		t.Errorf("expected an error, got none")
+	}
+}
+
+func TestRouter_MissingRequiredQuery(t *testing.T) {
+	type input struct {
+		ID openapi3.Query[int] `required:"true"`
+	}
+	h := func(p input) (any, error) { return nil, nil }
+
+	r := routey.New()
+	openapi3.AddSpecToRouter(r, openapi3.AddSpecToRouterOpts{})
+
+	var got extractor.Response
+	r.Response = func(_ http.ResponseWriter, _ *http.Request, resp extractor.Response) {
+		got = resp
+	}
+
+	routey.Get(r, "/", h, option.ID("id"))
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	var want *param.MissingRequiredError
+	test.WantError(t, got.Error, &want)
+	test.Equal(t, want.Name, "id")
+	test.Equal(t, got.Status, http.StatusBadRequest)
+}
+
+func TestRouterValidateRequest_BodyEnumError(t *testing.T) {
+	type body struct {
+		Status string `json:"status" enum:"active,inactive"`
+	}
+	type input struct {
+		Body openapi3.JSON[body]
+	}
+	h := func(p input) (any, error) { return nil, nil }
+
+	r := routey.New()
+	openapi3.AddSpecToRouter(r, openapi3.AddSpecToRouterOpts{
+		ValidateRequests: true,
+	})
+
+	gotError := test.WantAfterTest(t, false, true, "expected an error, got none")
+	r.Response = func(_ http.ResponseWriter, _ *http.Request, resp extractor.Response) {
+		var want jsonschema.ValidationError
+		test.WantError(t, resp.Error, &want)
+		*gotError = true
+	}
+
+	routey.Post(r, "/", h, option.ID("id"))
+	req := httptest.NewRequestWithContext(
+		t.Context(),
+		http.MethodPost,
+		"/",
+		strings.NewReader(`{"status": "banned"}`),
+	)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+}
+
+func TestRouterValidateRequest_BodyMinLengthError(t *testing.T) {
+	type body struct {
+		Name string `json:"name" minLength:"3" maxLength:"20"`
+	}
+	type input struct {
+		Body openapi3.JSON[body]
+	}
+	h := func(p input) (any, error) { return nil, nil }
+
+	r := routey.New()
+	openapi3.AddSpecToRouter(r, openapi3.AddSpecToRouterOpts{
+		ValidateRequests: true,
+	})
+
+	gotError := test.WantAfterTest(t, false, true, "expected an error, got none")
+	r.Response = func(_ http.ResponseWriter, _ *http.Request, resp extractor.Response) {
+		var want jsonschema.ValidationError
+		test.WantError(t, resp.Error, &want)
+		test.Equal(t, resp.Status, http.StatusBadRequest)
+		*gotError = true
+	}
+
+	routey.Post(r, "/", h, option.ID("id"))
+	req := httptest.NewRequestWithContext(
+		t.Context(),
+		http.MethodPost,
+		"/",
+		strings.NewReader(`{"name": "ab"}`),
+	)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+}
+
+func TestRouterValidateRequest_BodyDiscriminatorProperty(t *testing.T) {
+	type body struct {
+		Discriminator string `json:"discriminator" minLength:"3"`
+	}
+	type input struct {
+		Body openapi3.JSON[body]
+	}
+	h := func(p input) (any, error) { return nil, nil }
+
+	r := routey.New()
+	openapi3.AddSpecToRouter(r, openapi3.AddSpecToRouterOpts{
+		ValidateRequests: true,
+	})
+
+	var gotErr error
+	r.Response = func(_ http.ResponseWriter, _ *http.Request, resp extractor.Response) {
+		gotErr = resp.Error
+	}
+
+	routey.Post(r, "/", h, option.ID("id"))
+	req := httptest.NewRequestWithContext(
+		t.Context(),
+		http.MethodPost,
+		"/",
+		strings.NewReader(`{"discriminator": "ab"}`),
+	)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	var want jsonschema.ValidationError
+	test.WantError(t, gotErr, &want)
+}
+
+func TestRouter_InvalidBodyStructTag(t *testing.T) {
+	type body struct {
+		Name string `json:"name" maxLength:"ten"`
+	}
+	type input struct {
+		Body openapi3.JSON[body]
+	}
+	h := func(input) (any, error) { return nil, nil }
+
+	r := routey.New()
+	haveErr := false
+	r.ErrorSink = func(err error) {
+		haveErr = true
+
+		var want *param.InvalidParamError
+		test.WantError(t, err, &want)
+		test.Equal(t, want.Field.Name, "Name")
+	}
+	openapi3.AddSpecToRouter(r, openapi3.AddSpecToRouterOpts{})
+	routey.Post(r, "/", h, option.ID("id"))
+
+	if !haveErr {
+		t.Errorf("expected an error, got none")
+	}
+}
+
+func TestRouter_DeprecatedParamNote(t *testing.T) {
+	type input struct {
+		Count openapi3.Query[int] `deprecated:"use 'limit' instead"`
+		Limit openapi3.Query[int]
+	}
+	h := func(input) (any, error) { return nil, nil }
+
+	var got []openapi3.Parameter
+	r := routey.New()
+	spec := openapi3.AddSpecToRouter(r, openapi3.AddSpecToRouterOpts{
+		OnDeprecatedParam: func(_ *http.Request, p openapi3.Parameter) {
+			got = append(got, p)
+		},
+	})
+	routey.Get(r, "/", h, option.ID("id"))
+
+	path, _ := spec.GetPath("/")
+	op, _ := path.GetOperation(http.MethodGet)
+	p, _ := op.GetParameter("count", "query")
+	test.Equal(t, p.Deprecated, true)
+	test.Equal(t, p.Description, "use 'limit' instead")
+
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/?limit=1", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	test.Equal(t, len(got), 0)
+
+	req = httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/?count=1", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	test.Equal(t, len(got), 1)
+	test.Equal(t, got[0].Name, "count")
+}
+
+func TestRouter_DuplicateOperationIDs(t *testing.T) {
+	h := func(struct{}) (any, error) { return nil, nil }
+	r, spec := newTestRouter(t)
+	spec.Strict = true
+
+	gotError := test.WantAfterTest(t, false, true, "expected an error, got none")
+	r.ErrorSink = func(err error) {
+		test.IsError(t, err, openapi3.ErrDuplicateOperationID)
+		*gotError = true
+	}
+
+	routey.Get(r, "/foo", h, option.ID("id"))
+	routey.Get(r, "/bar", h, option.ID("id"))
+}
+
+func TestRouter_AnyOperationIDs(t *testing.T) {
+	r, spec := newTestRouter(t)
+	spec.Strict = true
+
+	routey.Any(r, "/proxy", HandlerForTests, option.ID("proxy"))
+
+	item := spec.Paths.Spec.Paths["/proxy"].Spec.Spec
+	test.Equal(t, item.Get.Spec.OperationID, "proxy_get")
+	test.Equal(t, item.Post.Spec.OperationID, "proxy_post")
+	test.Equal(t, item.Options.Spec.OperationID, "proxy_options")
+}
+
+func TestRouter_DocumentAutoHead(t *testing.T) {
+	h := func(struct{}) (any, error) { return nil, nil }
+
+	r := routey.New()
+	r.AutoHead = true
+	r.ErrorSink = func(err error) {
+		test.NoError(t, err)
+	}
+	spec := openapi3.AddSpecToRouter(r, openapi3.AddSpecToRouterOpts{
+		DocumentAutoHead: true,
+	})
+
+	routey.Get(r, "/", h,
+		option.ID("id"),
+		option.Response[string](http.StatusOK, "ok"),
+	)
+
+	test.MatchAsJSON(t, spec.Paths, `
+	{
+	  "/": {
+		"get": {
+		  "operationId": "id",
+		  "responses": {
+			"200": {
+			  "description": "ok",
+			  "content": {
+				"application/json": {
+				  "schema": {
+					"type": "string"
+				  }
+				}
+			  }
+			}
+		  }
+		},
+		"head": {
+		  "operationId": "idHead",
+		  "responses": {
+			"200": {
+			  "description": "ok"
+			}
+		  }
+		}
+	  }
+	}
+	`)
+}
+
+func TestRouter_ParamDefaults(t *testing.T) {
+	type input struct {
+		IDs openapi3.Query[[]string] `name:"ids"`
+	}
+
+	var got []string
+	h := func(p input) (any, error) {
+		got = p.IDs.Value
+		return nil, nil
+	}
+
+	r := routey.New()
+	r.ErrorSink = func(err error) {
+		test.NoError(t, err)
+	}
+	spec := openapi3.AddSpecToRouter(r, openapi3.AddSpecToRouterOpts{
+		ParamDefaults: openAPIParam.Defaults{
+			openAPIParam.LocationQuery: {
+				Style:   openAPIParam.StyleForm,
+				Explode: false,
+			},
+		},
+	})
+
+	routey.Get(r, "/", h, option.ID("id"))
+
+	path, has := spec.GetPath("/")
+	test.Equal(t, has, true)
+
+	op, has := path.GetOperation(http.MethodGet)
+	test.Equal(t, has, true)
+
+	p, has := op.GetParameter("ids", "query")
+	test.Equal(t, has, true)
+	test.Equal(t, p.Explode, false)
+
+	req := httptest.NewRequestWithContext(
+		t.Context(),
+		http.MethodGet,
+		"/?ids=a,b",
+		nil,
+	)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	test.MatchAsJSON(t, got, []string{"a", "b"})
+}
+
+type pet struct {
+	json.RawMessage
+}
+
+type cat struct {
+	Kind  string `json:"kind"`
+	Lives int    `json:"lives" minimum:"1"`
+}
+
+type dog struct {
+	Kind string `json:"kind"`
+}
+
+func TestRouterValidateRequest_BodyDiscriminator(t *testing.T) {
+	type input struct {
+		Body openapi3.JSON[pet]
+	}
+	h := func(p input) (any, error) { return nil, nil }
+
+	tests := []struct {
+		name    string
+		body    string
+		wantErr bool
+	}{
+		{
+			name: "valid cat",
+			body: `{"kind": "cat", "lives": 9}`,
+		},
+		{
+			name:    "invalid cat",
+			body:    `{"kind": "cat", "lives": 0}`,
+			wantErr: true,
+		},
+		{
+			name: "only the dog schema is checked",
+			body: `{"kind": "dog", "lives": 0}`,
+		},
+		{
+			name:    "unknown kind",
+			body:    `{"kind": "bird"}`,
+			wantErr: true,
+		},
+		{
+			name:    "missing kind",
+			body:    `{"lives": 9}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := routey.New()
+			spec := openapi3.AddSpecToRouter(r, openapi3.AddSpecToRouterOpts{
+				ValidateRequests: true,
+			})
+			err := openapi3.RegisterInterface[pet](spec, "kind", map[string]reflect.Type{
+				"cat": reflect.TypeFor[cat](),
+				"dog": reflect.TypeFor[dog](),
+			})
+			test.NoError(t, err)
+
+			var gotErr error
+			r.Response = func(_ http.ResponseWriter, _ *http.Request, resp extractor.Response) {
+				gotErr = resp.Error
+			}
+
+			routey.Post(r, "/", h, option.ID("id"))
+			req := httptest.NewRequestWithContext(
+				t.Context(),
+				http.MethodPost,
+				"/",
+				strings.NewReader(tt.body),
+			)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if !tt.wantErr {
+				test.NoError(t, gotErr)
+				return
+			}
+
+			var want jsonschema.ValidationError
+			test.WantError(t, gotErr, &want)
+		})
+	}
+}
+
+type Status string
+
+func (s *Status) UnmarshalText(b []byte) error {
+	*s = Status(b)
+	return nil
+}
+
+func TestRouter_ParamRegisteredTypeRef(t *testing.T) {
+	type input struct {
+		Status openapi3.Query[Status] `name:"status"`
+	}
+	h := func(input) (any, error) { return nil, nil }
+
+	r, spec := newTestRouter(t)
+	schema := jsonschema.NewBuilder().Type(jsonschema.TypeString).Enum("active", "inactive").Build()
+	test.NoError(t, openapi3.RegisterType[Status](spec, schema))
+
+	routey.Get(r, "/", h)
+
+	test.MatchAsJSON(t, spec.Paths, `
+	{
+		"/": {
+			"get": {
+				"parameters": [
+					{
+						"in": "query",
+						"explode": true,
+						"name": "status",
+						"style": "form",
+						"schema": {
+							"$ref": "#/components/schemas/Status"
+						}
+					}
+				]
+			}
+		}
+	}
+	`)
+}
+
+func TestRouter_ParamRegisteredTypeTagsInlined(t *testing.T) {
+	type input struct {
+		Status openapi3.Query[Status] `name:"status" enum:"ab,abc" maxLength:"3"`
+	}
+	h := func(input) (any, error) { return nil, nil }
+
+	r, spec := newTestRouter(t)
+	schema := jsonschema.NewBuilder().Type(jsonschema.TypeString).Build()
+	test.NoError(t, openapi3.RegisterType[Status](spec, schema))
+
+	routey.Get(r, "/", h)
+
+	test.MatchAsJSON(t, spec.Paths, `
+	{
+		"/": {
+			"get": {
+				"parameters": [
+					{
+						"in": "query",
+						"explode": true,
+						"name": "status",
+						"style": "form",
+						"schema": {
+							"type": "string",
+							"enum": ["ab", "abc"],
+							"maxLength": 3
+						}
+					}
+				]
+			}
+		}
+	}
+	`)
+}
+
+func TestRouter_ContentParam(t *testing.T) {
+	type filter struct {
+		Name  string `json:"name"`
+		Limit int    `json:"limit"`
+	}
+	type input struct {
+		Filter openapi3.Query[filter] `content:"application/json"`
+	}
+
+	var got filter
+	h := func(in input) (any, error) {
+		got = in.Filter.Value
+		return nil, nil
+	}
+
+	r, spec := newTestRouter(t)
+	routey.Get(r, "/", h, option.ID("list"))
+
+	test.MatchAsJSON(t, spec.Paths, `
+	{
+		"/": {
+			"get": {
+				"operationId": "list",
+				"parameters": [
+					{
+						"in": "query",
+						"explode": false,
+						"name": "filter",
+						"content": {
+							"application/json": {
+								"schema": {"$ref": "#/components/schemas/filter"}
+							}
+						}
+					}
+				]
+			}
+		}
+	}
+	`)
+
+	values := url.Values{"filter": {`{"name":"a","limit":2}`}}
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/?"+values.Encode(), nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	test.Equal(t, got, filter{Name: "a", Limit: 2})
+}
+
+func TestRouter_ParamRegisteredTypeNoRef(t *testing.T) {
+	type input struct {
+		Status openapi3.Query[Status] `name:"status"`
+	}
+	h := func(input) (any, error) { return nil, nil }
+
+	r, spec := newTestRouter(t)
+	schema := jsonschema.NewBuilder().Type(jsonschema.TypeString).Enum("active", "inactive").Build()
+	test.NoError(t, openapi3.RegisterType[Status](spec, schema, jsonschema.NoRef()))
+
+	routey.Get(r, "/", h)
+
+	test.MatchAsJSON(t, spec.Paths, `
+	{
+		"/": {
+			"get": {
+				"parameters": [
+					{
+						"in": "query",
+						"explode": true,
+						"name": "status",
+						"style": "form",
+						"schema": {
+							"type": "string",
+							"enum": ["active", "inactive"]
+						}
+					}
+				]
+			}
+		}
+	}
+	`)
+}
+
+func TestRouterValidateRequest_MatrixPathParam(t *testing.T) {
+	type color struct {
+		R int `json:"r" maximum:"255"`
+		G int `json:"g" maximum:"255"`
+	}
+	type input struct {
+		Color openapi3.Path[color] `name:"color" style:"matrix" explode:"true"`
+	}
+
+	var got color
+	h := func(p input) (any, error) {
+		got = p.Color.Value
+		return nil, nil
+	}
+
+	r := routey.New()
+	openapi3.AddSpecToRouter(r, openapi3.AddSpecToRouterOpts{
+		ValidateRequests: true,
+	})
+
+	var gotErr error
+	r.Response = func(_ http.ResponseWriter, _ *http.Request, resp extractor.Response) {
+		gotErr = resp.Error
+	}
+	r.ErrorSink = func(err error) {
+		test.NoError(t, err, "ErrorSink")
+	}
+
+	routey.Get(r, "/colors/{color}", h, option.ID("id"))
+
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/colors/;r=100;g=200", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	test.NoError(t, gotErr)
+	test.Equal(t, got, color{R: 100, G: 200})
+
+	req = httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/colors/;r=100;g=300", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	var want jsonschema.ValidationError
+	test.WantError(t, gotErr, &want)
+	test.Equal(t, want.Location, "#/parameters/path/color")
+}
+
+type pageParams struct {
+	Page  routey.Query[int] `description:"page to return"`
+	Limit routey.Query[int] `name:"per_page"`
+}
+
+func TestRouter_SpecWithEmbeddedParams(t *testing.T) {
+	type input struct {
+		pageParams
+		Extra routey.Query[int]
+	}
+	h := func(input) (any, error) { return nil, nil }
+	r, spec := newTestRouter(t)
+
+	routey.Get(r, "/", h)
+
+	test.MatchAsJSON(t, spec.Paths, `
+	{
+		"/": {
+			"get": {
+				"parameters": [
+					{
+						"in": "query",
+						"explode": true,
+						"name": "page",
+						"description": "page to return",
+						"style": "form",
+						"schema": {"type": "integer"}
+					},
+					{
+						"in": "query",
+						"explode": true,
+						"name": "per_page",
+						"style": "form",
+						"schema": {"type": "integer"}
+					},
+					{
+						"in": "query",
+						"explode": true,
+						"name": "extra",
+						"style": "form",
+						"schema": {"type": "integer"}
+					}
+				]
+			}
+		}
+	}
+	`)
+}
+
+func TestOpenAPI_HoistSharedParams(t *testing.T) {
+	type getInput struct {
+		ID     openapi3.Path[int]
+		Fields openapi3.Query[string]
+	}
+	type updateInput struct {
+		ID openapi3.Path[int]
+	}
+
+	r, spec := newTestRouter(t)
+	routey.Get(r, "/users/{id}", func(getInput) (any, error) { return nil, nil }, option.ID("getUser"))
+	routey.Post(r, "/users/{id}", func(updateInput) (any, error) { return nil, nil }, option.ID("updateUser"))
+
+	spec.HoistSharedParams()
+
+	test.MatchAsJSON(t, spec.Paths, `
+	{
+		"/users/{id}": {
+			"parameters": [
+				{
+					"in": "path",
+					"name": "id",
+					"required": true,
+					"explode": false,
+					"style": "simple",
+					"schema": {"type": "integer"}
+				}
+			],
+			"get": {
+				"operationId": "getUser",
+				"parameters": [
+					{
+						"in": "query",
+						"name": "fields",
+						"explode": true,
+						"style": "form",
+						"schema": {"type": "string"}
+					}
+				]
+			},
+			"post": {
+				"operationId": "updateUser"
+			}
+		}
+	}
+	`)
+}
+
+func TestRouter_EnumParamSpec(t *testing.T) {
+	type input struct {
+		Sort   openapi3.Query[string] `enum:"asc,desc"`
+		Levels openapi3.Query[[]int]  `enum:"1,2"`
+		Name   openapi3.Query[string]
+	}
+
+	r, spec := newTestRouter(t)
+	routey.Get(r, "/", func(input) (any, error) { return nil, nil }, option.ID("id"))
+
+	test.MatchAsJSON(t, spec.Paths, `
+	{
+		"/": {
+			"get": {
+				"operationId": "id",
+				"parameters": [
+					{
+						"in": "query",
+						"name": "sort",
+						"explode": true,
+						"style": "form",
+						"schema": {"type": "string", "enum": ["asc", "desc"]}
+					},
+					{
+						"in": "query",
+						"name": "levels",
+						"explode": true,
+						"style": "form",
+						"schema": {
+							"type": "array",
+							"items": {"type": "integer", "enum": [1, 2]}
+						}
+					},
+					{
+						"in": "query",
+						"name": "name",
+						"explode": true,
+						"style": "form",
+						"schema": {"type": "string"}
+					}
+				]
+			}
+		}
+	}
+	`)
+}
+
+func TestRouter_LengthParamSpec(t *testing.T) {
+	type input struct {
+		Name openapi3.Query[string] `minLength:"2" maxLength:"4"`
+	}
+
+	r, spec := newTestRouter(t)
+	routey.Get(r, "/", func(input) (any, error) { return nil, nil }, option.ID("id"))
+
+	test.MatchAsJSON(t, spec.Paths, `
+	{
+		"/": {
+			"get": {
+				"operationId": "id",
+				"parameters": [
+					{
+						"in": "query",
+						"name": "name",
+						"explode": true,
+						"style": "form",
+						"schema": {"type": "string", "minLength": 2, "maxLength": 4}
+					}
+				]
+			}
+		}
+	}
+	`)
+}
+
+func TestRouter_LengthItemsParamSpec(t *testing.T) {
+	type input struct {
+		Names openapi3.Query[[]string] `minLength:"2" maxLength:"4"`
+	}
+
+	r, spec := newTestRouter(t)
+	routey.Get(r, "/", func(input) (any, error) { return nil, nil }, option.ID("id"))
+
+	test.MatchAsJSON(t, spec.Paths, `
+	{
+		"/": {
+			"get": {
+				"operationId": "id",
+				"parameters": [
+					{
+						"in": "query",
+						"name": "names",
+						"explode": true,
+						"style": "form",
+						"schema": {
+							"type": "array",
+							"items": {"type": "string", "minLength": 2, "maxLength": 4}
+						}
+					}
+				]
+			}
+		}
+	}
+	`)
+}
+
+func TestRouter_ContentResponseSpec(t *testing.T) {
+	h := func(struct{}) (routey.Content, error) {
+		return routey.Content{Type: "text/plain", Value: "id,name", Status: http.StatusCreated}, nil
+	}
+
+	r, spec := newTestRouter(t)
+	routey.Get(r, "/users.csv", h,
+		option.ID("exportUsers"),
+		option.ContentResponse(http.StatusCreated, "users", "text/plain"),
+	)
+
+	test.MatchAsJSON(t, spec.Paths, `
+	{
+		"/users.csv": {
+			"get": {
+				"operationId": "exportUsers",
+				"responses": {
+					"201": {
+						"description": "users",
+						"content": {
+							"text/plain": {}
+						}
+					}
+				}
+			}
+		}
+	}
+	`)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/users.csv", nil))
+	test.Equal(t, w.Code, http.StatusCreated)
+	test.Equal(t, w.Header().Get("Content-Type"), "text/plain")
+	test.Equal(t, w.Body.String(), "id,name")
 }
@@ -0,0 +1,264 @@
+package openapi3
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/sv-tools/openapi"
+	"github.com/zhamlin/routey/jsonschema"
+)
+
+// validationSchema returns the json encoding of the schema to use for
+// validating requests. The component schemas the schema references are
+// included so the references can be resolved, and discriminators are
+// converted into conditionals that only check the schema matching the
+// property value.
+func (o OpenAPI) validationSchema(s Schema) ([]byte, error) {
+	b, err := json.Marshal(s.JSONSchema())
+	if err != nil {
+		return nil, err
+	}
+
+	schema := map[string]any{}
+	if err := json.Unmarshal(b, &schema); err != nil {
+		return nil, err
+	}
+
+	if o.Components != nil && len(o.Components.Spec.Schemas) > 0 {
+		b, err := json.Marshal(o.Components.Spec.Schemas)
+		if err != nil {
+			return nil, err
+		}
+
+		schemas := map[string]any{}
+		if err := json.Unmarshal(b, &schemas); err != nil {
+			return nil, err
+		}
+
+		if used := referencedSchemas(schema, schemas); len(used) > 0 {
+			for _, component := range used {
+				replaceDiscriminators(component)
+			}
+			schema["components"] = map[string]any{
+				"schemas": used,
+			}
+		}
+	}
+
+	return json.Marshal(replaceDiscriminators(schema))
+}
+
+// referencedSchemas returns the component schemas referenced by the decoded
+// schema, including the schemas referenced by them.
+func referencedSchemas(schema map[string]any, schemas map[string]any) map[string]any {
+	used := map[string]any{}
+	pending := collectRefs(schema)
+
+	for len(pending) > 0 {
+		ref := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+
+		name, isSchema := strings.CutPrefix(ref, componentsRefPrefix+"schemas/")
+		component, has := schemas[name]
+		if !isSchema || !has || used[name] != nil {
+			continue
+		}
+
+		used[name] = component
+		pending = append(pending, collectRefs(component)...)
+	}
+	return used
+}
+
+// schemaFields hold a schema, or a list of schemas.
+var schemaFields = []string{
+	"additionalProperties",
+	"allOf",
+	"anyOf",
+	"contains",
+	"contentSchema",
+	"else",
+	"if",
+	"items",
+	"not",
+	"oneOf",
+	"prefixItems",
+	"propertyNames",
+	"then",
+	"unevaluatedItems",
+	"unevaluatedProperties",
+}
+
+// namedSchemaFields hold a map of names to schemas.
+var namedSchemaFields = []string{"$defs", "dependentSchemas", "patternProperties", "properties"}
+
+// replaceDiscriminators walks the subschemas of the decoded schema replacing
+// any oneOf with a discriminator mapping by an if/then for each value in the
+// mapping. The discriminator property becomes required and limited to the
+// mapped values.
+func replaceDiscriminators(v any) any {
+	schema, ok := v.(map[string]any)
+	if !ok {
+		return v
+	}
+
+	for key, value := range schema {
+		switch {
+		case slices.Contains(schemaFields, key):
+			if items, ok := value.([]any); ok {
+				for _, item := range items {
+					replaceDiscriminators(item)
+				}
+				continue
+			}
+			replaceDiscriminators(value)
+		case slices.Contains(namedSchemaFields, key):
+			named, _ := value.(map[string]any)
+			for _, item := range named {
+				replaceDiscriminators(item)
+			}
+		}
+	}
+
+	discriminatorToConditions(schema)
+	return schema
+}
+
+func discriminatorToConditions(schema map[string]any) {
+	discriminator, ok := schema["discriminator"].(map[string]any)
+	if !ok {
+		return
+	}
+	delete(schema, "discriminator")
+
+	property, _ := discriminator["propertyName"].(string)
+	mapping, _ := discriminator["mapping"].(map[string]any)
+	if _, has := schema["oneOf"]; !has || property == "" || len(mapping) == 0 {
+		return
+	}
+	delete(schema, "oneOf")
+
+	values := slices.Sorted(maps.Keys(mapping))
+	conditions := make([]any, 0, len(values))
+
+	for _, value := range values {
+		conditions = append(conditions, map[string]any{
+			"if": map[string]any{
+				"properties": map[string]any{
+					property: map[string]any{"const": value},
+				},
+			},
+			"then": map[string]any{"$ref": mapping[value]},
+		})
+	}
+
+	enum := make([]any, 0, len(values))
+	for _, value := range values {
+		enum = append(enum, value)
+	}
+
+	properties, _ := schema["properties"].(map[string]any)
+	if properties == nil {
+		properties = map[string]any{}
+	}
+	properties[property] = map[string]any{"enum": enum}
+
+	required, _ := schema["required"].([]any)
+	if !slices.Contains(required, any(property)) {
+		required = append(required, property)
+	}
+
+	allOf, _ := schema["allOf"].([]any)
+
+	schema["properties"] = properties
+	schema["required"] = required
+	schema["allOf"] = append(allOf, conditions...)
+}
+
+var ErrInvalidExample = errors.New("example does not match the schema")
+
+// validateResponseExamples returns an error for the first example of the
+// responses of the operation that does not match the schema of its content.
+func (o OpenAPI) validateResponseExamples(op *Operation) error {
+	if op.Responses == nil {
+		return nil
+	}
+
+	responses := op.Responses.Spec
+	codes := slices.Sorted(maps.Keys(responses.Response))
+	validator := jsonschema.NewValidator()
+
+	for _, code := range codes {
+		resp := responses.Response[code]
+		if resp.Spec == nil {
+			continue
+		}
+
+		content := resp.Spec.Spec.Content
+		for _, contentType := range slices.Sorted(maps.Keys(content)) {
+			mt := content[contentType].Spec
+			if mt.Schema == nil {
+				continue
+			}
+
+			examples := map[string]any{}
+			if mt.Example != nil {
+				examples["example"] = mt.Example
+			}
+
+			for name, example := range mt.Examples {
+				if example.Spec != nil {
+					examples[name] = example.Spec.Spec.Value
+				}
+			}
+
+			if len(examples) == 0 {
+				continue
+			}
+
+			location := fmt.Sprintf("responses.%s.content.%s", code, contentType)
+			if err := o.validateExamples(validator, location, mt.Schema, examples); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (o OpenAPI) validateExamples(
+	validator *jsonschema.Validator,
+	location string,
+	src *openapi.RefOrSpec[openapi.Schema],
+	examples map[string]any,
+) error {
+	schema, err := o.getSchemaSource(src)
+	if err != nil {
+		return err
+	}
+
+	b, err := o.validationSchema(schema)
+	if err != nil {
+		return err
+	}
+
+	if err := validator.Add(location, string(b)); err != nil {
+		return fmt.Errorf("compling schema(%s) failed: %w", location, err)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(examples)) {
+		value, err := json.Marshal(examples[name])
+		if err != nil {
+			return err
+		}
+
+		if err := validator.Validate(location, value); err != nil {
+			return fmt.Errorf("%s.examples.%s: %w: %w", location, name, ErrInvalidExample, err)
+		}
+	}
+	return nil
+}
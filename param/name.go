@@ -1,7 +1,10 @@
 package param
 
 import (
+	"reflect"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/zhamlin/routey/internal/stringz"
 )
@@ -17,3 +20,122 @@ func NamerCapitals(name, _ string) string {
 
 	return strings.Join(chunks, "_")
 }
+
+// words splits name into lower case words at underscores and capitals,
+// separating acronyms from the following word: HTTPServerID is http,
+// server and id, and User_ID is user and id.
+func words(name string) []string {
+	var result []string
+	for part := range strings.SplitSeq(name, "_") {
+		runes := []rune(part)
+		start := 0
+
+		for i := 1; i < len(runes); i++ {
+			prev, cur := runes[i-1], runes[i]
+			if !unicode.IsUpper(cur) {
+				continue
+			}
+
+			endsAcronym := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if !unicode.IsUpper(prev) || endsAcronym {
+				result = append(result, strings.ToLower(string(runes[start:i])))
+				start = i
+			}
+		}
+
+		if start < len(runes) {
+			result = append(result, strings.ToLower(string(runes[start:])))
+		}
+	}
+	return result
+}
+
+// NamerSnake names params in snake_case: HTTPServerID is http_server_id.
+func NamerSnake(name, _ string) string {
+	return strings.Join(words(name), "_")
+}
+
+// NamerKebab names params in kebab-case: HTTPServerID is http-server-id.
+func NamerKebab(name, _ string) string {
+	return strings.Join(words(name), "-")
+}
+
+// NamerTrain names params in Train-Case, commonly used by headers:
+// HTTPServerID is Http-Server-Id.
+func NamerTrain(name, _ string) string {
+	w := words(name)
+	for i := range w {
+		first, size := utf8.DecodeRuneInString(w[i])
+		w[i] = string(unicode.ToUpper(first)) + w[i][size:]
+	}
+	return strings.Join(w, "-")
+}
+
+// NamerBySource returns a Namer using the namer for the source of the param,
+// or fallback for sources without one:
+//
+//	NamerBySource(NamerSnake, map[string]Namer{"header": NamerTrain})
+func NamerBySource(fallback Namer, namers map[string]Namer) Namer {
+	return func(name, source string) string {
+		if namer, has := namers[source]; has {
+			return namer(name, source)
+		}
+		return fallback(name, source)
+	}
+}
+
+// NamerFromJSON names params like [NamerCapitals]. It is the entry point for
+// naming params after their `json` tag: using it as the Namer of a [Config]
+// sets JSONNames, and [NameFromField] prefers the json tag when given it.
+func NamerFromJSON(name, style string) string {
+	return NamerCapitals(name, style)
+}
+
+var namerFromJSONAddr = reflect.ValueOf(NamerFromJSON).Pointer()
+
+func isNamerFromJSON(namer Namer) bool {
+	return namer != nil && reflect.ValueOf(namer).Pointer() == namerFromJSONAddr
+}
+
+// jsonTagName returns the name from the `json` tag of the field,
+// or an empty string when the tag has no name or is "-".
+func jsonTagName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// Joiner combines the name of a parent field with the name of a nested param.
+type Joiner func(parent, name string) string
+
+// JoinDot joins the names as `parent.name`.
+func JoinDot(parent, name string) string {
+	return parent + "." + name
+}
+
+// JoinBrackets joins the names as `parent[name]`.
+func JoinBrackets(parent, name string) string {
+	return parent + "[" + name + "]"
+}
+
+// NestedName prefixes name with the names of the parent fields, named by the
+// config and joined by its Joiner. Parents are ordered from the innermost field
+// to the outermost field, matching [Info.ParentFields]. Embedded fields are
+// skipped, as their fields are promoted.
+//
+// If the Joiner is nil name is returned unchanged.
+func NestedName(name string, parents []reflect.StructField, config Config, source string) string {
+	if config.Joiner == nil {
+		return name
+	}
+
+	for _, parent := range parents {
+		if parent.Anonymous {
+			continue
+		}
+		name = config.Joiner(config.FieldName(parent, source), name)
+	}
+	return name
+}
@@ -1,8 +1,10 @@
 package param_test
 
 import (
+	"reflect"
 	"testing"
 
+	"github.com/zhamlin/routey/internal/test"
 	"github.com/zhamlin/routey/param"
 )
 
@@ -14,3 +16,69 @@ func TestNamerCapitals(t *testing.T) {
 		t.Errorf("wanted: %s, got: %s", want, got)
 	}
 }
+
+func TestNamerCases(t *testing.T) {
+	tests := []struct {
+		name  string
+		snake string
+		kebab string
+		train string
+	}{
+		{name: "UserID", snake: "user_id", kebab: "user-id", train: "User-Id"},
+		{name: "HTTPServerID", snake: "http_server_id", kebab: "http-server-id", train: "Http-Server-Id"},
+		{name: "APIKey", snake: "api_key", kebab: "api-key", train: "Api-Key"},
+		{name: "lowerUpper", snake: "lower_upper", kebab: "lower-upper", train: "Lower-Upper"},
+		{name: "ID", snake: "id", kebab: "id", train: "Id"},
+		{name: "Page2", snake: "page2", kebab: "page2", train: "Page2"},
+		{name: "User_ID", snake: "user_id", kebab: "user-id", train: "User-Id"},
+		{name: "user__name_", snake: "user_name", kebab: "user-name", train: "User-Name"},
+		{name: "ÄpfelID", snake: "äpfel_id", kebab: "äpfel-id", train: "Äpfel-Id"},
+		{name: "straßeNr", snake: "straße_nr", kebab: "straße-nr", train: "Straße-Nr"},
+		{name: "ÜBERSize", snake: "über_size", kebab: "über-size", train: "Über-Size"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test.Equal(t, param.NamerSnake(tt.name, ""), tt.snake)
+			test.Equal(t, param.NamerKebab(tt.name, ""), tt.kebab)
+			test.Equal(t, param.NamerTrain(tt.name, ""), tt.train)
+		})
+	}
+}
+
+func TestNamerBySource(t *testing.T) {
+	namer := param.NamerBySource(param.NamerSnake, map[string]param.Namer{
+		"header": param.NamerTrain,
+	})
+
+	test.Equal(t, namer("RequestID", "query"), "request_id")
+	test.Equal(t, namer("RequestID", "header"), "Request-Id")
+}
+
+func TestNestedName(t *testing.T) {
+	type Inner struct{ Value int }
+	type Outer struct {
+		Inner Inner
+		Embed Inner `name:"embed"`
+	}
+
+	typ := reflect.TypeFor[Outer]()
+	parents := []reflect.StructField{typ.Field(0), typ.Field(1)}
+
+	tests := []struct {
+		joiner param.Joiner
+		want   string
+	}{
+		{joiner: nil, want: "value"},
+		{joiner: param.JoinDot, want: "embed.inner.value"},
+		{joiner: param.JoinBrackets, want: "embed[inner[value]]"},
+	}
+
+	for _, test := range tests {
+		config := param.Config{Namer: param.NamerCapitals, Joiner: test.joiner}
+		got := param.NestedName("value", parents, config, "")
+		if got != test.want {
+			t.Errorf("wanted: %s, got: %s", test.want, got)
+		}
+	}
+}
@@ -1,7 +1,14 @@
 package param
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"unicode/utf8"
 )
 
 // Config contains things used to parse params.
@@ -10,6 +17,48 @@ type Config struct {
 	Parser Parser
 	// Allows modifying of param names from the structs field name.
 	Namer Namer
+	// JSONNames names params after the `json` tag of their
+	// field when it has a name, instead of using the Namer.
+	// It is set when the Namer is [NamerFromJSON].
+	JSONNames bool
+	// Joins the names of nested params with their parent field names.
+	// When nil only the name of the nested field is used. Embedded
+	// fields are never joined, as their params are promoted.
+	Joiner Joiner
+	// ContextParser is tried before the Parser when set.
+	ContextParser ContextParser
+	// TimeLayouts are the layouts accepted when parsing [time.Time] params
+	// that are not in the RFC 3339 format, replacing [DefaultTimeLayouts].
+	TimeLayouts []string
+	// FormTagKey is the struct tag naming the fields of url encoded form
+	// bodies. The `form` tag is used when empty.
+	FormTagKey string
+}
+
+// GetParser returns the Parser, preceded by a time parser accepting the
+// TimeLayouts when they are set. The time parser is created by [NewParser],
+// so the times of slices, arrays, maps and pointers accept the layouts too.
+func (c Config) GetParser() Parser {
+	if c.TimeLayouts == nil || c.Parser == nil {
+		return c.Parser
+	}
+	return Parsers{NewParser(NewTimeParser(c.TimeLayouts...)), c.Parser}.Parse
+}
+
+// SetParsers sets the Parser to one created by [NewParser] from the parsers.
+func (c *Config) SetParsers(parsers ...Parser) {
+	c.Parser = NewParser(parsers...)
+}
+
+// AddParser adds the parser ahead of the current Parser, however it was set.
+// The result is combined with a reflect parser by [NewParser], so the items
+// of slices, arrays, maps and pointers are parsed by the parser too.
+func (c *Config) AddParser(parser Parser) {
+	parsers := Parsers{parser}
+	if c.Parser != nil {
+		parsers = append(parsers, c.Parser)
+	}
+	c.SetParsers(parsers...)
 }
 
 // Pather is the interface implemented by an object that can
@@ -22,19 +71,242 @@ type Pather interface {
 type Opts struct {
 	Name    string
 	Default string
-	Parser  Parser
-	Pather  Pather
+	// Required causes extractors to error when the param is missing.
+	Required bool
+	Parser   Parser
+	Pather   Pather
+	// ContextParser is tried before the Parser when set, using Context.
+	ContextParser ContextParser
+	// Context is the context of the request being extracted.
+	Context context.Context
+	// Enum are the values allowed for the param, any are allowed when empty.
+	Enum []string
+	// MinLength and MaxLength limit the number of characters
+	// of string values, or of each item of string slices, when set.
+	MinLength *int
+	MaxLength *int
+	// Separator splits a single param into the items of slices, arrays
+	// and maps, replacing the comma used by [NewReflectParser].
+	Separator string
+	// FormTagKey is the struct tag naming the fields of form bodies,
+	// see [Config].
+	FormTagKey string
+}
+
+// ErrMissingRequired is wrapped by [MissingRequiredError].
+var ErrMissingRequired = errors.New("missing required param")
+
+// MissingRequiredError is returned by extractors when a required param
+// is not in the request and has no default value.
+type MissingRequiredError struct {
+	Name   string
+	Source string
+}
+
+func (e MissingRequiredError) Error() string {
+	return fmt.Sprintf("%s: %s param %q", ErrMissingRequired, e.Source, e.Name)
+}
+
+func (e MissingRequiredError) Unwrap() error {
+	return ErrMissingRequired
+}
+
+// StatusCode responds with a 400, as the request is missing the param.
+func (e MissingRequiredError) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+// ErrNotInEnum is wrapped by [NotInEnumError].
+var ErrNotInEnum = errors.New("param value not allowed")
+
+// NotInEnumError is returned when parsing a param value
+// that is not one of the values allowed by [Opts.Enum].
+type NotInEnumError struct {
+	Name  string
+	Value string
+	Enum  []string
+}
+
+func (e NotInEnumError) Error() string {
+	return fmt.Sprintf("%s: %q: %q must be one of: %s",
+		ErrNotInEnum, e.Name, e.Value, strings.Join(e.Enum, ", "),
+	)
+}
+
+func (e NotInEnumError) Unwrap() error {
+	return ErrNotInEnum
+}
+
+// StatusCode responds with a 400, as the request has an invalid value.
+func (e NotInEnumError) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+// ErrInvalidLength is wrapped by [LengthError].
+var ErrInvalidLength = errors.New("param value has an invalid length")
+
+// LengthError is returned when parsing a string param value that is
+// shorter than [Opts.MinLength] or longer than [Opts.MaxLength].
+type LengthError struct {
+	Name  string
+	Value string
+	// Limit is the length the value is shorter or longer than.
+	Limit int
+	// TooLong is true when the value is longer than the Limit.
+	TooLong bool
+}
+
+func (e LengthError) Error() string {
+	bound := "least"
+	if e.TooLong {
+		bound = "most"
+	}
+	return fmt.Sprintf("%s: %q must be at %s %d characters, got %q",
+		ErrInvalidLength, e.Name, bound, e.Limit, e.Value,
+	)
+}
+
+func (e LengthError) Unwrap() error {
+	return ErrInvalidLength
+}
+
+// StatusCode responds with a 400, as the request has an invalid value.
+func (e LengthError) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+// checkLength returns an error if value points to a string and any of the
+// params are shorter than the MinLength, or longer than the MaxLength. The
+// separated items of the params are checked for slices and arrays of strings.
+func (o Opts) checkLength(value any, params []string) error {
+	if o.MinLength == nil && o.MaxLength == nil {
+		return nil
+	}
+
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Pointer {
+		return nil
+	}
+
+	typ := v.Type().Elem()
+	split := isCollection(value) && typ.Kind() != reflect.Map
+	if split {
+		typ = typ.Elem()
+	}
+
+	if typ.Kind() != reflect.String {
+		return nil
+	}
+
+	for _, p := range params {
+		items := []string{p}
+		if split {
+			items = strings.Split(p, o.separator())
+		}
+
+		for _, item := range items {
+			n := utf8.RuneCountInString(item)
+			if o.MinLength != nil && n < *o.MinLength {
+				return LengthError{Name: o.Name, Value: item, Limit: *o.MinLength}
+			}
+
+			if o.MaxLength != nil && n > *o.MaxLength {
+				return LengthError{Name: o.Name, Value: item, Limit: *o.MaxLength, TooLong: true}
+			}
+		}
+	}
+	return nil
+}
+
+// checkEnum returns an error if any of the params are not in the enum.
+// The separated items of the params are checked for collections.
+func (o Opts) checkEnum(value any, params []string) error {
+	if len(o.Enum) == 0 {
+		return nil
+	}
+
+	split := isCollection(value)
+	for _, p := range params {
+		items := []string{p}
+		if split {
+			items = strings.Split(p, o.separator())
+		}
+
+		for _, value := range items {
+			if !slices.Contains(o.Enum, value) {
+				return NotInEnumError{Name: o.Name, Value: value, Enum: o.Enum}
+			}
+		}
+	}
+	return nil
 }
 
 func (o Opts) PathValue(name string, r *http.Request) string {
 	return o.Pather.Param(name, r)
 }
 
+func (o Opts) separator() string {
+	if o.Separator == "" {
+		return ","
+	}
+	return o.Separator
+}
+
+// isCollection returns true if value points to a slice, array or map,
+// unless it parses its own params.
+func isCollection(value any) bool {
+	if _, ok := value.(FieldParser); ok {
+		return false
+	}
+
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Pointer {
+		return false
+	}
+
+	switch v.Type().Elem().Kind() {
+	case reflect.Array, reflect.Slice, reflect.Map:
+		return true
+	}
+	return false
+}
+
 func (o Opts) Parse(value any, params []string) error {
 	if l := len(params); l == 0 && o.Default != "" {
 		params = []string{o.Default}
 	} else if l == 0 {
 		return nil
 	}
-	return o.Parser(value, params)
+
+	parser := o.Parser
+	if o.Separator != "" && isCollection(value) {
+		if len(params) == 1 {
+			params = strings.Split(params[0], o.Separator)
+		}
+		// items are never split by the comma of the Parser
+		parser = newReflectParser(o.Parser, o.Separator)
+	}
+
+	if err := o.checkEnum(value, params); err != nil {
+		return err
+	}
+
+	if err := o.checkLength(value, params); err != nil {
+		return err
+	}
+
+	if o.ContextParser != nil {
+		if _, ok := value.(FieldParser); !ok {
+			ctx := o.Context
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
+			err := o.ContextParser(ctx, value, params)
+			if !errors.Is(err, ErrInvalidParamType) {
+				return err
+			}
+		}
+	}
+	return Parse(parser, value, params)
 }
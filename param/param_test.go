@@ -1,6 +1,7 @@
 package param_test
 
 import (
+	"context"
 	"net/http"
 	"testing"
 
@@ -43,6 +44,167 @@ func TestOpts_Parse(t *testing.T) {
 	test.NoError(t, err)
 }
 
+func TestOpts_ParseEnum(t *testing.T) {
+	opts := param.Opts{
+		Name:   "sort",
+		Parser: param.ParseString,
+		Enum:   []string{"asc", "desc"},
+	}
+
+	var got string
+	err := opts.Parse(&got, []string{"desc"})
+	test.NoError(t, err)
+	test.Equal(t, got, "desc")
+
+	err = opts.Parse(&got, []string{"up"})
+	test.IsError(t, err, param.ErrNotInEnum)
+
+	var want param.NotInEnumError
+	test.WantError(t, err, &want)
+	test.Equal(t, want.Value, "up")
+	test.Equal(t, want.StatusCode(), http.StatusBadRequest)
+
+	// scalar values are not split into items
+	err = opts.Parse(&got, []string{"asc,desc"})
+	test.WantError(t, err, &want)
+	test.Equal(t, want.Value, "asc,desc")
+}
+
+func TestOpts_ParseEnumCollection(t *testing.T) {
+	opts := param.Opts{
+		Name:   "sort",
+		Parser: param.NewReflectParser(param.ParseString),
+		Enum:   []string{"asc", "desc"},
+	}
+
+	var got []string
+	err := opts.Parse(&got, []string{"asc,desc"})
+	test.NoError(t, err)
+	test.MatchAsJSON(t, got, []string{"asc", "desc"})
+
+	err = opts.Parse(&got, []string{"asc,up"})
+	test.IsError(t, err, param.ErrNotInEnum)
+}
+
+func TestOpts_ParseSeparator(t *testing.T) {
+	opts := param.Opts{
+		Name:      "tags",
+		Parser:    param.NewParser(param.ParseString),
+		Enum:      []string{"a", "b", "c"},
+		Separator: "|",
+	}
+
+	var got []string
+	err := opts.Parse(&got, []string{"a|b|c"})
+	test.NoError(t, err)
+	test.MatchAsJSON(t, got, []string{"a", "b", "c"})
+
+	err = opts.Parse(&got, []string{"a|d"})
+	test.IsError(t, err, param.ErrNotInEnum)
+
+	// commas are part of the items
+	opts.Enum = nil
+	err = opts.Parse(&got, []string{"a,b"})
+	test.NoError(t, err)
+	test.MatchAsJSON(t, got, []string{"a,b"})
+
+	var m map[string]string
+	err = opts.Parse(&m, []string{"x:a,b"})
+	test.NoError(t, err)
+	test.MatchAsJSON(t, m, map[string]string{"x": "a,b"})
+
+	// values that are not collections are not split
+	var s string
+	opts.Parser = param.ParseString
+	err = opts.Parse(&s, []string{"a|b"})
+	test.NoError(t, err)
+	test.Equal(t, s, "a|b")
+}
+
+func TestOpts_ParseLength(t *testing.T) {
+	minLength, maxLength := 2, 3
+	opts := param.Opts{
+		Name:      "name",
+		Parser:    param.ParseString,
+		MinLength: &minLength,
+		MaxLength: &maxLength,
+	}
+
+	var got string
+	err := opts.Parse(&got, []string{"ab"})
+	test.NoError(t, err)
+
+	// characters are counted, not bytes
+	err = opts.Parse(&got, []string{"äöü"})
+	test.NoError(t, err)
+
+	err = opts.Parse(&got, []string{"a"})
+	test.IsError(t, err, param.ErrInvalidLength)
+
+	err = opts.Parse(&got, []string{"abcd"})
+	test.IsError(t, err, param.ErrInvalidLength)
+
+	// only strings are checked
+	var n int
+	opts.Parser = param.ParseInt
+	err = opts.Parse(&n, []string{"1"})
+	test.NoError(t, err)
+}
+
+func TestOpts_ParseLengthItems(t *testing.T) {
+	minLength, maxLength := 2, 3
+	opts := param.Opts{
+		Name:      "names",
+		Parser:    param.NewParser(param.ParseString),
+		MinLength: &minLength,
+		MaxLength: &maxLength,
+	}
+
+	var got []string
+	err := opts.Parse(&got, []string{"ab,abc"})
+	test.NoError(t, err)
+
+	// each item is checked, not the whole param
+	err = opts.Parse(&got, []string{"ab,a"})
+	test.IsError(t, err, param.ErrInvalidLength)
+
+	err = opts.Parse(&got, []string{"ab", "abcd"})
+	test.IsError(t, err, param.ErrInvalidLength)
+}
+
+type tenantKey struct{}
+
+// parseTenantID prefixes ids with the tenant from the context.
+func parseTenantID(ctx context.Context, value any, params []string) error {
+	v, ok := value.(*string)
+	if !ok {
+		return param.ErrInvalidParamType
+	}
+
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	*v = tenant + "/" + params[0]
+	return nil
+}
+
+func TestOpts_ParseContextParser(t *testing.T) {
+	opts := param.Opts{
+		Parser:        param.ParseInt,
+		ContextParser: parseTenantID,
+		Context:       context.WithValue(t.Context(), tenantKey{}, "acme"),
+	}
+
+	var got string
+	err := opts.Parse(&got, []string{"1"})
+	test.NoError(t, err)
+	test.Equal(t, got, "acme/1")
+
+	// types the context parser cannot parse use the parser
+	var i int
+	err = opts.Parse(&i, []string{"1"})
+	test.NoError(t, err)
+	test.Equal(t, i, 1)
+}
+
 type testPather struct {
 	value string
 }
@@ -1,17 +1,24 @@
 package param
 
 import (
+	"context"
 	"encoding"
 	"errors"
 	"fmt"
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // Parser represents a function that can parse a value from a slice of params.
 type Parser func(value any, params []string) error
 
+// ContextParser is a [Parser] with access to the context of the request,
+// allowing the value to depend on it. Returning ErrInvalidParamType falls
+// back to the Parser.
+type ContextParser func(ctx context.Context, value any, params []string) error
+
 type Parsers []Parser
 
 // Parse calls each parser until one returns no error or any error other than ErrInvalidParamType.
@@ -26,6 +33,52 @@ func (p Parsers) Parse(value any, params []string) error {
 	return err
 }
 
+// DefaultParsers returns the parsers of the types supported by default,
+// which [NewParser] combines with a reflect parser.
+func DefaultParsers() Parsers {
+	return Parsers{
+		// before ParseTextUnmarshaller, which only accepts RFC 3339 times
+		ParseTime,
+		ParseTextUnmarshaller,
+		ParseInt,
+		ParseUint,
+		ParseFloat,
+		ParseDuration,
+		ParseString,
+		ParseBool,
+	}
+}
+
+// NewParser returns a parser trying each of the parsers, followed by
+// a [NewReflectParser] parsing the items of slices, arrays, maps
+// and pointers with them.
+func NewParser(parsers ...Parser) Parser {
+	base := Parsers(parsers).Parse
+	return Parsers{base, NewReflectParser(base)}.Parse
+}
+
+// FieldParser is implemented by types that parse their own value from
+// the params, and is preferred over the [Parser] when parsing them.
+type FieldParser interface {
+	ParseParam(params []string) error
+}
+
+var fieldParserType = reflect.TypeFor[FieldParser]()
+
+// IsFieldParser returns true if pointers to the type implement [FieldParser].
+func IsFieldParser(typ reflect.Type) bool {
+	return reflect.PointerTo(typ).Implements(fieldParserType)
+}
+
+// Parse parses the params into value using its [FieldParser] implementation,
+// or the parser when value does not implement it.
+func Parse(parser Parser, value any, params []string) error {
+	if p, ok := value.(FieldParser); ok {
+		return p.ParseParam(params)
+	}
+	return parser(value, params)
+}
+
 // ErrInvalidParamType represents an error when a type cannot be parsed as a param.
 var ErrInvalidParamType = errors.New("invalid param type")
 
@@ -101,6 +154,77 @@ func ParseFloat(value any, params []string) error {
 	return err
 }
 
+// ParseDuration parses a [time.Duration] using [time.ParseDuration].
+func ParseDuration(value any, params []string) error {
+	err := ErrInvalidParamType
+	if v, ok := value.(*time.Duration); ok {
+		*v, err = time.ParseDuration(params[0])
+	}
+	return err
+}
+
+// DefaultTimeLayouts are the layouts accepted by [ParseTime]
+// when a value is not in the RFC 3339 format.
+var DefaultTimeLayouts = []string{time.DateOnly}
+
+// ParseTime parses a [time.Time] in the RFC 3339 format,
+// or any of the [DefaultTimeLayouts].
+func ParseTime(value any, params []string) error {
+	return parseTime(value, params, DefaultTimeLayouts)
+}
+
+// NewTimeParser returns a [Parser] for [time.Time] accepting values
+// in the RFC 3339 format, or any of the layouts.
+func NewTimeParser(layouts ...string) Parser {
+	return func(value any, params []string) error {
+		return parseTime(value, params, layouts)
+	}
+}
+
+// newLayoutParser returns a [Parser] for [time.Time]
+// only accepting values in the layout.
+func newLayoutParser(layout string) Parser {
+	return func(value any, params []string) error {
+		v, ok := value.(*time.Time)
+		if !ok {
+			return ErrInvalidParamType
+		}
+
+		t, err := time.Parse(layout, params[0])
+		if err != nil {
+			return err
+		}
+
+		*v = t
+		return nil
+	}
+}
+
+func parseTime(value any, params []string, layouts []string) error {
+	v, ok := value.(*time.Time)
+	if !ok {
+		return ErrInvalidParamType
+	}
+
+	t, err := time.Parse(time.RFC3339, params[0])
+	for _, layout := range layouts {
+		if err == nil {
+			break
+		}
+
+		if parsed, layoutErr := time.Parse(layout, params[0]); layoutErr == nil {
+			t, err = parsed, nil
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	*v = t
+	return nil
+}
+
 func ParseTextUnmarshaller(value any, params []string) error {
 	if v, ok := value.(encoding.TextUnmarshaler); ok {
 		return v.UnmarshalText([]byte(params[0]))
@@ -124,9 +248,9 @@ func ParseString(value any, params []string) error {
 	return err
 }
 
-func createSlice(parser Parser, params []string, typ reflect.Type) (reflect.Value, error) {
+func createSlice(parser Parser, params []string, typ reflect.Type, sep string) (reflect.Value, error) {
 	if len(params) == 1 {
-		params = strings.Split(params[0], ",")
+		params = strings.Split(params[0], sep)
 	}
 
 	l := len(params)
@@ -142,8 +266,42 @@ func createSlice(parser Parser, params []string, typ reflect.Type) (reflect.Valu
 	return s, nil
 }
 
+// ErrInvalidMapItem is returned when a map item is not a key:value pair.
+var ErrInvalidMapItem = errors.New("invalid map item, expected key:value")
+
+func createMap(parser Parser, params []string, typ reflect.Type, sep string) (reflect.Value, error) {
+	if len(params) == 1 {
+		params = strings.Split(params[0], sep)
+	}
+
+	m := reflect.MakeMapWithSize(typ, len(params))
+	for _, item := range params {
+		key, value, ok := strings.Cut(item, ":")
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("%w: %q", ErrInvalidMapItem, item)
+		}
+
+		v := reflect.New(typ.Elem())
+		if err := parser(v.Interface(), []string{value}); err != nil {
+			return reflect.Value{}, fmt.Errorf("error parsing map item %q: %w", key, err)
+		}
+		m.SetMapIndex(reflect.ValueOf(key).Convert(typ.Key()), v.Elem())
+	}
+
+	return m, nil
+}
+
 // NewReflectParser returns a parser that uses reflection to set the value.
+// Slices and arrays are parsed from each param, or the comma separated items
+// of a single param. Maps with string keys are parsed from key:value items.
+// Pointers are set to a new value parsed by the parser.
 func NewReflectParser(parser Parser) Parser {
+	return newReflectParser(parser, ",")
+}
+
+// newReflectParser returns a [NewReflectParser] splitting a single
+// param into items by the separator.
+func newReflectParser(parser Parser, sep string) Parser {
 	return func(value any, params []string) error {
 		// value should be a pointer to a value
 		v := reflect.ValueOf(value).Elem()
@@ -151,11 +309,28 @@ func NewReflectParser(parser Parser) Parser {
 
 		switch typ.Kind() {
 		case reflect.Array, reflect.Slice:
-			s, err := createSlice(parser, params, typ)
+			s, err := createSlice(parser, params, typ, sep)
 			if err == nil {
 				v.Set(s)
 			}
 			return err
+		case reflect.Pointer:
+			elem := reflect.New(typ.Elem())
+			if err := parser(elem.Interface(), params); err != nil {
+				return err
+			}
+			v.Set(elem)
+			return nil
+		case reflect.Map:
+			if typ.Key().Kind() != reflect.String {
+				break
+			}
+
+			m, err := createMap(parser, params, typ, sep)
+			if err == nil {
+				v.Set(m)
+			}
+			return err
 		}
 
 		return ErrInvalidParamType
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"reflect"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/zhamlin/routey/internal/test"
 	"github.com/zhamlin/routey/param"
@@ -52,6 +54,135 @@ func TestParseString(t *testing.T) {
 	compareParsed(t, want, []string{"test"}, param.ParseString)
 }
 
+func TestParseDuration(t *testing.T) {
+	want := 90 * time.Minute
+	compareParsed(t, want, []string{"1h30m"}, param.ParseDuration)
+}
+
+func TestParseTime(t *testing.T) {
+	want := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	compareParsed(t, want, []string{"2024-01-02T03:04:05Z"}, param.ParseTime)
+
+	want = time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	compareParsed(t, want, []string{"2024-01-02"}, param.ParseTime)
+}
+
+func TestNewTimeParser(t *testing.T) {
+	parser := param.NewTimeParser("01/02/2006")
+
+	want := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	compareParsed(t, want, []string{"01/02/2024"}, parser)
+
+	var got time.Time
+	err := parser(&got, []string{"2024-01-02"})
+	if err == nil {
+		t.Fatal("expected an error parsing a time not matching the layouts")
+	}
+}
+
+func TestConfig_GetParserTimeLayouts(t *testing.T) {
+	config := param.Config{
+		Parser:      param.ParseTime,
+		TimeLayouts: []string{"01/02/2006"},
+	}
+
+	want := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	compareParsed(t, want, []string{"01/02/2024"}, config.GetParser())
+}
+
+func TestConfig_GetParserTimeLayoutsItems(t *testing.T) {
+	config := param.Config{
+		Parser:      param.NewParser(param.DefaultParsers()...),
+		TimeLayouts: []string{"01/02/2006"},
+	}
+	parser := config.GetParser()
+
+	day := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	compareParsed(t, []time.Time{day, day.AddDate(0, 0, 1)}, []string{"01/02/2024,01/03/2024"}, parser)
+	compareParsed(t, &day, []string{"01/02/2024"}, parser)
+	compareParsed(t, []int{1, 2}, []string{"1,2"}, parser)
+}
+
+func TestParserForField_TimeFormat(t *testing.T) {
+	type params struct {
+		Day   time.Time `timeformat:"01/02/2006"`
+		Other time.Time
+	}
+	typ := reflect.TypeFor[params]()
+
+	day := param.ParserForField(typ.Field(0), param.ParseTime)
+	want := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	compareParsed(t, want, []string{"01/02/2024"}, day)
+
+	var got time.Time
+	err := day(&got, []string{"2024-01-02"})
+	if err == nil {
+		t.Error("expected an error parsing a time not in the layout")
+	}
+
+	other := param.ParserForField(typ.Field(1), param.ParseTime)
+	compareParsed(t, want, []string{"2024-01-02"}, other)
+}
+
+type color string
+
+func parseColor(value any, params []string) error {
+	v, ok := value.(*color)
+	if !ok {
+		return param.ErrInvalidParamType
+	}
+	*v = color("color:" + params[0])
+	return nil
+}
+
+func TestConfig_AddParser(t *testing.T) {
+	var config param.Config
+	config.SetParsers(param.DefaultParsers()...)
+	config.AddParser(parseColor)
+
+	compareParsed(t, color("color:red"), []string{"red"}, config.Parser)
+	// the reflect parser uses the added parser for items
+	compareParsed(t, []color{"color:red", "color:blue"}, []string{"red,blue"}, config.Parser)
+	compareParsed(t, []int{1, 2}, []string{"1,2"}, config.Parser)
+}
+
+func TestConfig_AddParserToParser(t *testing.T) {
+	config := param.Config{Parser: param.ParseInt}
+	config.AddParser(parseColor)
+
+	compareParsed(t, color("color:red"), []string{"red"}, config.Parser)
+	compareParsed(t, 1, []string{"1"}, config.Parser)
+}
+
+func TestConfig_AddParserAfterAssigningParser(t *testing.T) {
+	var config param.Config
+	config.SetParsers(param.DefaultParsers()...)
+
+	// replaces the parsers set above, and is kept by AddParser
+	config.Parser = param.NewParser(param.ParseInt, parseUpper)
+	config.AddParser(parseColor)
+
+	compareParsed(t, color("color:red"), []string{"red"}, config.Parser)
+	compareParsed(t, upper("A"), []string{"a"}, config.Parser)
+	compareParsed(t, []upper{"A", "B"}, []string{"a,b"}, config.Parser)
+	compareParsed(t, []color{"color:a", "color:b"}, []string{"a,b"}, config.Parser)
+
+	var s string
+	err := config.Parser(&s, []string{"a"})
+	test.IsError(t, err, param.ErrInvalidParamType)
+}
+
+type upper string
+
+func parseUpper(value any, params []string) error {
+	v, ok := value.(*upper)
+	if !ok {
+		return param.ErrInvalidParamType
+	}
+	*v = upper(strings.ToUpper(params[0]))
+	return nil
+}
+
 func TestParseParamsInt(t *testing.T) {
 	tests := []struct {
 		want   any
@@ -167,6 +298,40 @@ func TestParseParamReflect_ErrorParsingItem(t *testing.T) {
 	test.IsError(t, err, strconv.ErrSyntax)
 }
 
+func TestParseParamReflect_Map(t *testing.T) {
+	parser := param.NewReflectParser(param.ParseInt)
+	want := map[string]int{"a": 1, "b": 2}
+	compareParsed(t, want, []string{"a:1,b:2"}, parser)
+	compareParsed(t, want, []string{"a:1", "b:2"}, parser)
+}
+
+func TestParseParamReflect_MapErrors(t *testing.T) {
+	parser := param.NewReflectParser(param.ParseInt)
+
+	var m map[string]int
+	err := parser(&m, []string{"a=1"})
+	test.IsError(t, err, param.ErrInvalidMapItem)
+
+	err = parser(&m, []string{"a:b"})
+	test.IsError(t, err, strconv.ErrSyntax)
+
+	var intKeys map[int]int
+	err = parser(&intKeys, []string{"1:1"})
+	test.IsError(t, err, param.ErrInvalidParamType)
+}
+
+func TestParseParamReflect_Pointer(t *testing.T) {
+	parser := param.NewReflectParser(param.ParseInt)
+
+	var got *int
+	err := parser(&got, []string{"1"})
+	test.NoError(t, err)
+	test.Equal(t, *got, 1)
+
+	err = parser(&got, []string{"a"})
+	test.IsError(t, err, strconv.ErrSyntax)
+}
+
 func TestParsersParse(t *testing.T) {
 	parse := param.Parsers{
 		param.ParseBool,
@@ -212,3 +377,26 @@ func BenchmarkParsesParse(b *testing.B) {
 		}
 	}
 }
+
+type fieldParser struct {
+	Values []string
+}
+
+func (f *fieldParser) ParseParam(params []string) error {
+	f.Values = append(f.Values, params...)
+	return nil
+}
+
+func TestParse_FieldParser(t *testing.T) {
+	got := fieldParser{}
+	err := param.Parse(param.ParseString, &got, []string{"a", "b"})
+	test.NoError(t, err)
+	test.MatchAsJSON(t, got.Values, []string{"a", "b"})
+}
+
+func TestParse_FallbackToParser(t *testing.T) {
+	var got int
+	err := param.Parse(param.ParseInt, &got, []string{"1"})
+	test.NoError(t, err)
+	test.Equal(t, got, 1)
+}
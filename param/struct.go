@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
 
 	"github.com/zhamlin/routey/internal/stringz"
@@ -11,9 +12,10 @@ import (
 )
 
 type Info struct {
-	Name    string
-	Source  string
-	Default string
+	Name     string
+	Source   string
+	Default  string
+	Required bool
 	// Type of the param, can be different than the
 	// fields type.
 	Type reflect.Type
@@ -55,11 +57,13 @@ var ErrNonStructArg = errors.New("handler argument should be a struct")
 
 // InvalidParamError represents a param that cannot be parsed.
 type InvalidParamError struct {
-	Struct       reflect.Type
-	Field        reflect.StructField
-	ParamType    reflect.Type
-	Message      string
-	Err          string
+	Struct    reflect.Type
+	Field     reflect.StructField
+	ParamType reflect.Type
+	Message   string
+	Err       string
+	// Help replaces the default help text when set.
+	Help         string
 	UnderlineAll bool
 }
 
@@ -131,24 +135,154 @@ func writeInvalidParamError(
 		},
 	}, colors)
 
+	help := errInvalidParamHelp
+	if invalidParam.Help != "" {
+		help = invalidParam.Help
+	}
+
 	msg.WriteString(stringz.PrefixBorder("| ", structOutput) + "\n")
 	fmt.Fprintln(msg)
-	fmt.Fprintln(msg, stringz.FormatText("help: ", errInvalidParamHelp))
+	fmt.Fprintln(msg, stringz.FormatText("help: ", help))
+}
+
+// SkipFromField returns true if the field has the `param` tag set to "-",
+// leaving it to be set by the handler instead of being extracted.
+func SkipFromField(f reflect.StructField) bool {
+	return f.Tag.Get("param") == "-"
+}
+
+// EnumFromField returns the comma separated values of the `enum` tag,
+// which are the only values allowed for the param.
+func EnumFromField(f reflect.StructField) []string {
+	tag := f.Tag.Get("enum")
+	if tag == "" {
+		return nil
+	}
+
+	values := strings.Split(tag, ",")
+	for i := range values {
+		values[i] = strings.TrimSpace(values[i])
+	}
+	return values
+}
+
+// SeparatorFromField returns the `sep` tag, used to split a single
+// param into the items of the field instead of a comma.
+func SeparatorFromField(f reflect.StructField) string {
+	return f.Tag.Get("sep")
+}
+
+// LengthFromField returns the `minLength` and `maxLength` tags,
+// which are nil when not set.
+func LengthFromField(f reflect.StructField) (*int, *int, error) {
+	parse := func(tag string) (*int, error) {
+		value := f.Tag.Get(tag)
+		if value == "" {
+			return nil, nil
+		}
+
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, err
+		}
+		return &n, nil
+	}
+
+	minLength, err := parse("minLength")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	maxLength, err := parse("maxLength")
+	if err != nil {
+		return nil, nil, err
+	}
+	return minLength, maxLength, nil
+}
+
+// hasStringValues returns true if the type is a string,
+// or a slice or array of strings.
+func hasStringValues(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Slice, reflect.Array:
+		typ = typ.Elem()
+	}
+	return typ.Kind() == reflect.String
 }
 
+// TimeFormatFromField returns the `timeformat` tag, the only
+// layout accepted when parsing [time.Time] params.
+func TimeFormatFromField(f reflect.StructField) string {
+	return f.Tag.Get("timeformat")
+}
+
+// ParserForField returns the parser, preceded by a parser of [time.Time]
+// accepting only the layout of the `timeformat` tag when the field has one.
+func ParserForField(f reflect.StructField, parser Parser) Parser {
+	layout := TimeFormatFromField(f)
+	if layout == "" || parser == nil {
+		return parser
+	}
+	return Parsers{newLayoutParser(layout), parser}.Parse
+}
+
+// RequiredFromField returns true if the field has the `required` tag set to true.
+func RequiredFromField(f reflect.StructField) (bool, error) {
+	value := f.Tag.Get("required")
+	if value == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+// RawNameFromField returns the `rawName` tag, which
+// is false when not set.
+func RawNameFromField(f reflect.StructField) (bool, error) {
+	value := f.Tag.Get("rawName")
+	if value == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+// NameFromField returns the name of the param for the field, in order of
+// precedence:
+//
+//   - the `name` tag, exactly as written
+//   - the field name unchanged, when the `rawName` tag is true
+//   - the name of the `json` tag, when the namer is [NamerFromJSON]
+//   - the name created by the namer
 func NameFromField(f reflect.StructField, namer Namer, source string) string {
-	name := f.Tag.Get("name")
-	if name == "" {
-		name = namer(f.Name, source)
+	return Config{Namer: namer}.FieldName(f, source)
+}
+
+// FieldName returns the name of the param for the field like [NameFromField],
+// using the Namer of the config. When JSONNames is set, or the Namer is
+// [NamerFromJSON], the name from the `json` tag of the field is preferred
+// over the Namer.
+func (c Config) FieldName(f reflect.StructField, source string) string {
+	if name := f.Tag.Get("name"); name != "" {
+		return name
+	}
+
+	// invalid tags are rejected by InfoFromType
+	if raw, _ := RawNameFromField(f); raw {
+		return f.Name
+	}
+
+	jsonNames := c.JSONNames || isNamerFromJSON(c.Namer)
+	if name := jsonTagName(f); name != "" && jsonNames {
+		return name
 	}
-	return name
+	return c.Namer(f.Name, source)
 }
 
 type CustomParser interface {
 	CanParse(p Parser, source reflect.StructField, value any) error
 }
 
-// canParseType tests if the parser can handle the given type.
+// canParseType tests if the parser can handle the given type. Types
+// implementing [FieldParser] are not parsed to test them.
 //
 //nolint:wrapcheck // do not wrap errors to reduce allocations
 func canParseType(
@@ -156,6 +290,10 @@ func canParseType(
 	value any,
 	field reflect.StructField,
 ) error {
+	if IsFieldParser(reflect.TypeOf(value).Elem()) {
+		return nil
+	}
+
 	if !errors.Is(parser(value, []string{""}), ErrInvalidParamType) {
 		return nil
 	}
@@ -173,11 +311,75 @@ func canParseType(
 }
 
 func InfoFromStruct[T any](namer Namer, parser Parser) ([]Info, error) {
-	structType := reflect.TypeFor[T]()
-	return infoFromValue(structType, namer, parser)
+	return InfoFromType(reflect.TypeFor[T](), Config{
+		Parser: parser,
+		Namer:  namer,
+	})
 }
 
-var ErrUnparsableDefault = "default value cannot be parsed"
+// InfoFromType returns all params found on the struct type using the config
+// to name and parse them. Params of embedded structs, including pointers and
+// unexported types, are promoted as if declared on the struct, allowing a
+// shared params struct to be embedded into many handler inputs.
+func InfoFromType(typ reflect.Type, config Config) ([]Info, error) {
+	params, err := infoFromValue(typ, config)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, info := range params {
+		params[i].Name = NestedName(info.Name, info.ParentFields, config, info.Source)
+	}
+
+	if err := ensureNoDuplicates(params); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+const errDuplicateParamHelp = `
+	params must have a unique name for each source
+	The name tag renames a param, r.Params.Joiner prefixes nested params with their parent
+`
+
+// ensureNoDuplicates returns an error if any params share the same name and source.
+func ensureNoDuplicates(params []Info) error {
+	type key struct{ name, source string }
+	seen := make(map[key]Info, len(params))
+
+	for _, info := range params {
+		k := key{name: info.Name, source: info.Source}
+		existing, has := seen[k]
+
+		if !has {
+			seen[k] = info
+			continue
+		}
+
+		return &InvalidParamError{
+			Struct:    info.Struct,
+			Field:     info.Field,
+			ParamType: info.Type,
+			Message:   fmt.Sprintf("%s: %s %q", ErrDuplicateParam, info.Source, info.Name),
+			Err: fmt.Sprintf(
+				"already declared by %s.%s", existing.Struct.Name(), existing.Field.Name,
+			),
+			Help:         errDuplicateParamHelp,
+			UnderlineAll: true,
+		}
+	}
+
+	return nil
+}
+
+var (
+	ErrUnparsableDefault  = "default value cannot be parsed"
+	ErrUnparsableRequired = "required value cannot be parsed"
+	ErrUnparsableRawName  = "rawName value cannot be parsed"
+	ErrUnparsableLength   = "length value cannot be parsed"
+	ErrInvalidLengthType  = "length is only valid for strings or slices of strings"
+	ErrDuplicateParam     = "duplicate param"
+)
 
 func getType(value any) reflect.Type {
 	var structType reflect.Type
@@ -190,7 +392,7 @@ func getType(value any) reflect.Type {
 	return structType
 }
 
-func infoFromValue(value any, namer Namer, parser Parser) ([]Info, error) {
+func infoFromValue(value any, config Config) ([]Info, error) {
 	structType := getType(value)
 	if structType.Kind() != reflect.Struct {
 		return nil, fmt.Errorf("%w: got: %q", ErrNonStructArg, structType)
@@ -199,7 +401,11 @@ func infoFromValue(value any, namer Namer, parser Parser) ([]Info, error) {
 	params := make([]Info, 0, structType.NumField())
 	for i := range structType.NumField() {
 		field := structType.Field(i)
-		info, err := infoFromField(structType, field, namer, parser)
+		if SkipFromField(field) {
+			continue
+		}
+
+		info, err := infoFromField(structType, field, config)
 
 		if err != nil {
 			return nil, err
@@ -214,14 +420,27 @@ func infoFromValue(value any, namer Namer, parser Parser) ([]Info, error) {
 func infoFromField(
 	structType reflect.Type,
 	field reflect.StructField,
-	namer Namer,
-	parser Parser,
+	config Config,
 ) ([]Info, error) {
 	source, typ, isParam := GetSourceAndType(field.Type)
+
+	// checked for struct fields too, as they name their nested params
+	if _, err := RawNameFromField(field); err != nil {
+		return nil, &InvalidParamError{
+			Struct:       structType,
+			ParamType:    typ,
+			Field:        field,
+			Err:          err.Error(),
+			Message:      ErrUnparsableRawName + ": " + field.Tag.Get("rawName"),
+			UnderlineAll: true,
+		}
+	}
+
 	if !isParam {
-		return getParamsFromStruct(field, namer, parser)
+		return getParamsFromStruct(field, config)
 	}
 
+	parser := ParserForField(field, config.Parser)
 	value := reflect.New(typ).Interface()
 	if err := canParseType(parser, value, field); err != nil {
 		var want *InvalidParamError
@@ -238,7 +457,7 @@ func infoFromField(
 
 	defaultValue := field.Tag.Get("default")
 	if defaultValue != "" {
-		err := parser(value, []string{defaultValue})
+		err := Parse(parser, value, []string{defaultValue})
 		if err != nil {
 			return nil, &InvalidParamError{
 				Struct:    structType,
@@ -250,33 +469,70 @@ func infoFromField(
 		}
 	}
 
-	name := NameFromField(field, namer, source)
+	required, err := RequiredFromField(field)
+	if err != nil {
+		return nil, &InvalidParamError{
+			Struct:       structType,
+			ParamType:    typ,
+			Field:        field,
+			Err:          err.Error(),
+			Message:      ErrUnparsableRequired + ": " + field.Tag.Get("required"),
+			UnderlineAll: true,
+		}
+	}
+
+	minLength, maxLength, err := LengthFromField(field)
+	if err != nil {
+		return nil, &InvalidParamError{
+			Struct:       structType,
+			ParamType:    typ,
+			Field:        field,
+			Err:          err.Error(),
+			Message:      ErrUnparsableLength,
+			UnderlineAll: true,
+		}
+	}
+
+	if (minLength != nil || maxLength != nil) && !hasStringValues(typ) {
+		return nil, &InvalidParamError{
+			Struct:       structType,
+			ParamType:    typ,
+			Field:        field,
+			Message:      ErrInvalidLengthType,
+			UnderlineAll: true,
+		}
+	}
+
+	name := config.FieldName(field, source)
 	return []Info{{
 		Name:    name,
 		Source:  source,
 		Default: defaultValue,
-		Type:    typ,
-		Field:   field,
-		Struct:  structType,
+		// a default value means the param can never be missing
+		Required: required && defaultValue == "",
+		Type:     typ,
+		Field:    field,
+		Struct:   structType,
 	}}, nil
 }
 
 var ErrNoParser = errors.New("no param parser provided")
 
-func getParamsFromStruct(
-	field reflect.StructField,
-	namer Namer,
-	parser Parser,
-) ([]Info, error) {
-	if parser == nil {
+func getParamsFromStruct(field reflect.StructField, config Config) ([]Info, error) {
+	if config.Parser == nil {
 		return nil, ErrNoParser
 	}
 
-	if field.Type.Kind() != reflect.Struct {
+	typ := field.Type
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	if typ.Kind() != reflect.Struct {
 		return nil, nil
 	}
 
-	infos, err := infoFromValue(field.Type, namer, parser)
+	infos, err := infoFromValue(typ, config)
 	if err != nil {
 		return nil, err
 	}
@@ -60,6 +60,101 @@ func TestNameFromField_OverrideWithTag(t *testing.T) {
 	}
 }
 
+func TestNameFromField_Unchanged(t *testing.T) {
+	type Object struct {
+		Top    int    `name:"$top"`
+		APIKey string `name:"X-Api-Key"`
+		Filter string `name:"filter[Name]"`
+		OData  string `rawName:"true"`
+	}
+
+	want := []string{"$top", "X-Api-Key", "filter[Name]", "OData"}
+	typ := reflect.TypeFor[Object]()
+
+	for i, name := range want {
+		got := param.NameFromField(typ.Field(i), param.NamerCapitals, "")
+		test.Equal(t, got, name)
+	}
+}
+
+func TestConfig_FieldNameJSONNames(t *testing.T) {
+	type Object struct {
+		UserID  string `json:"uid"`
+		OrgID   string `json:"org,omitempty" name:"organization"`
+		GroupID string `json:",omitempty"`
+		Ignored string `json:"-"`
+	}
+
+	want := []string{"uid", "organization", "group_id", "ignored"}
+	typ := reflect.TypeFor[Object]()
+
+	config := param.Config{Namer: param.NamerCapitals, JSONNames: true}
+
+	for i, name := range want {
+		got := config.FieldName(typ.Field(i), "")
+		test.Equal(t, got, name)
+	}
+
+	// wrapped namers still use the json tag
+	config.Namer = param.NamerBySource(param.NamerCapitals, map[string]param.Namer{
+		"header": param.NamerTrain,
+	})
+	test.Equal(t, config.FieldName(typ.Field(0), "header"), "uid")
+	test.Equal(t, config.FieldName(typ.Field(2), "header"), "Group-Id")
+
+	// the json tag is ignored without JSONNames
+	got := param.NameFromField(typ.Field(0), param.NamerCapitals, "")
+	test.Equal(t, got, "user_id")
+}
+
+func TestNameFromField_NamerFromJSON(t *testing.T) {
+	type Object struct {
+		UserID  string `json:"uid"`
+		OrgID   string `json:"org,omitempty" name:"organization"`
+		GroupID string `json:",omitempty"`
+		Ignored string `json:"-"`
+	}
+
+	want := []string{"uid", "organization", "group_id", "ignored"}
+	typ := reflect.TypeFor[Object]()
+
+	for i, name := range want {
+		got := param.NameFromField(typ.Field(i), param.NamerFromJSON, "")
+		test.Equal(t, got, name)
+	}
+
+	config := param.Config{Namer: param.NamerFromJSON}
+	test.Equal(t, config.FieldName(typ.Field(0), ""), "uid")
+}
+
+type panicParser struct{}
+
+func (panicParser) ParseParam([]string) error {
+	panic("ParseParam called at registration")
+}
+
+func TestInfoFromStruct_FieldParserNotCalled(t *testing.T) {
+	type Params struct {
+		Value routey.Query[panicParser]
+	}
+	got, err := param.InfoFromStruct[Params](param.NamerCapitals, param.ParseInt)
+	test.NoError(t, err)
+	test.Equal(t, len(got), 1)
+}
+
+func TestInfoFromStruct_UnusualNames(t *testing.T) {
+	type Params struct {
+		Top  routey.Query[int] `name:"$top"`
+		Skip routey.Query[int] `name:"api-skip"`
+	}
+	got, err := param.InfoFromStruct[Params](param.NamerCapitals, param.ParseInt)
+	test.NoError(t, err)
+
+	test.Equal(t, len(got), 2)
+	test.Equal(t, got[0].Name, "$top")
+	test.Equal(t, got[1].Name, "api-skip")
+}
+
 func TestGetParamsFromStruct(t *testing.T) {
 	type Params struct{ Value routey.Query[int] }
 	got, err := param.InfoFromStruct[Params](param.NamerCapitals, param.ParseInt)
@@ -106,6 +201,18 @@ func TestGetParamsFromStruct_SkipNonParam(t *testing.T) {
 	test.MatchAsJSON(t, got, want)
 }
 
+func TestGetParamsFromStruct_SkipTag(t *testing.T) {
+	type Params struct {
+		Value    routey.Query[int]
+		Computed routey.Query[int] `param:"-"`
+	}
+	got, err := param.InfoFromStruct[Params](param.NamerCapitals, param.ParseInt)
+	test.NoError(t, err)
+
+	test.Equal(t, len(got), 1)
+	test.Equal(t, got[0].Name, "value")
+}
+
 func TestGetParamsFromStruct_InvalidParamErr(t *testing.T) {
 	type Params struct{ Value routey.Query[int] }
 	_, err := param.InfoFromStruct[Params](param.NamerCapitals, param.ParseString)
@@ -114,7 +221,130 @@ func TestGetParamsFromStruct_InvalidParamErr(t *testing.T) {
 	test.WantError(t, err, &want)
 }
 
+func TestGetParamsFromStruct_InvalidLengthErr(t *testing.T) {
+	type Params struct {
+		Value routey.Query[string] `maxLength:"ten"`
+	}
+	_, err := param.InfoFromStruct[Params](param.NamerCapitals, param.ParseString)
+
+	var want *param.InvalidParamError
+	test.WantError(t, err, &want)
+	test.Equal(t, want.Message, param.ErrUnparsableLength)
+}
+
+func TestGetParamsFromStruct_InvalidLengthTypeErr(t *testing.T) {
+	type Params struct {
+		Value routey.Query[int] `maxLength:"10"`
+	}
+	_, err := param.InfoFromStruct[Params](param.NamerCapitals, param.ParseInt)
+
+	var want *param.InvalidParamError
+	test.WantError(t, err, &want)
+	test.Equal(t, want.Message, param.ErrInvalidLengthType)
+
+	type Items struct {
+		Values routey.Query[[]string] `maxLength:"10"`
+	}
+	_, err = param.InfoFromStruct[Items](param.NamerCapitals, param.NewParser(param.ParseString))
+	test.NoError(t, err)
+}
+
+func TestGetParamsFromStruct_InvalidRawNameErr(t *testing.T) {
+	type Params struct {
+		Value routey.Query[string] `rawName:"yes please"`
+	}
+	_, err := param.InfoFromStruct[Params](param.NamerCapitals, param.ParseString)
+
+	var want *param.InvalidParamError
+	test.WantError(t, err, &want)
+	test.Equal(t, want.Message, param.ErrUnparsableRawName+": yes please")
+
+	type Nested struct {
+		Filter struct {
+			Value routey.Query[string]
+		} `rawName:"maybe"`
+	}
+	_, err = param.InfoFromStruct[Nested](param.NamerCapitals, param.ParseString)
+	test.WantError(t, err, &want)
+	test.Equal(t, want.Message, param.ErrUnparsableRawName+": maybe")
+}
+
 func TestGetParamsFromStruct_NonStructError(t *testing.T) {
 	_, err := param.InfoFromStruct[int](nil, nil)
 	test.IsError(t, err, param.ErrNonStructArg)
 }
+
+func TestInfoFromType_NestedJoiner(t *testing.T) {
+	type Filters struct{ Limit routey.Query[int] }
+	type Params struct {
+		Users  Filters
+		Groups Filters
+	}
+
+	got, err := param.InfoFromType(reflect.TypeFor[Params](), param.Config{
+		Parser: param.ParseInt,
+		Namer:  param.NamerCapitals,
+		Joiner: param.JoinDot,
+	})
+	test.NoError(t, err)
+
+	if l := len(got); l != 2 {
+		t.Fatalf("expected two params, got: %d", l)
+	}
+	test.Equal(t, got[0].Name, "users.limit")
+	test.Equal(t, got[1].Name, "groups.limit")
+}
+
+func TestInfoFromType_NestedNoJoiner(t *testing.T) {
+	type Filters struct{ Limit routey.Query[int] }
+	type Params struct{ Users Filters }
+
+	got, err := param.InfoFromType(reflect.TypeFor[Params](), param.Config{
+		Parser: param.ParseInt,
+		Namer:  param.NamerCapitals,
+	})
+	test.NoError(t, err)
+	test.Equal(t, got[0].Name, "limit")
+}
+
+func TestInfoFromType_DuplicateParamErr(t *testing.T) {
+	type Params struct {
+		Value routey.Query[int]
+		Other routey.Query[int] `name:"value"`
+	}
+	_, err := param.InfoFromStruct[Params](param.NamerCapitals, param.ParseInt)
+
+	var want *param.InvalidParamError
+	test.WantError(t, err, &want)
+
+	test.Equal(t, want.Field.Name, "Other")
+	test.Equal(t, want.Message, param.ErrDuplicateParam+`: query "value"`)
+}
+
+type Pagination struct {
+	Page routey.Query[int]
+	Size routey.Query[int]
+}
+
+func TestInfoFromType_EmbeddedDuplicateParamErr(t *testing.T) {
+	type Params struct {
+		Pagination
+		Page routey.Query[int]
+	}
+	_, err := param.InfoFromStruct[Params](param.NamerCapitals, param.ParseInt)
+
+	var want *param.InvalidParamError
+	test.WantError(t, err, &want)
+
+	test.Equal(t, want.Field.Name, "Page")
+	test.Equal(t, want.Message, param.ErrDuplicateParam+`: query "page"`)
+}
+
+func TestInfoFromType_DuplicateNameDifferentSource(t *testing.T) {
+	type Params struct {
+		Value routey.Query[int]
+		Other routey.Path[int] `name:"value"`
+	}
+	_, err := param.InfoFromStruct[Params](param.NamerCapitals, param.ParseInt)
+	test.NoError(t, err)
+}
@@ -0,0 +1,35 @@
+package routey
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/zhamlin/routey/extractor"
+)
+
+// LimitQueryParams returns a middleware rejecting requests with more than max
+// query param values, with each value of a repeated param counted. An
+// [extractor.StatusError] with a 400 is passed to the Response handler of r,
+// or written directly when r has no Response handler.
+//
+// The query is parsed with [extractor.GetAndSetQueryValues], so the parsed
+// values are reused by the extractors of the handler.
+func LimitQueryParams(r *Router, max int) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			count := 0
+			for _, values := range extractor.GetAndSetQueryValues(req) {
+				count += len(values)
+			}
+
+			if count <= max {
+				next.ServeHTTP(w, req)
+				return
+			}
+
+			err := extractor.BadRequest(nil)
+			err.Message = fmt.Sprintf("%d query params exceeds the limit of %d", count, max)
+			respondError(r, w, req, err, err.StatusCode())
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package routey_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/zhamlin/routey"
+	"github.com/zhamlin/routey/extractor"
+	"github.com/zhamlin/routey/internal/test"
+)
+
+func TestLimitQueryParams_TooMany(t *testing.T) {
+	r := newTestRouter(t)
+	r.Use(routey.LimitQueryParams(r, 2))
+
+	var gotResp extractor.Response
+	r.Response = func(w http.ResponseWriter, _ *http.Request, resp extractor.Response) {
+		gotResp = resp
+		http.Error(w, "", resp.Status)
+	}
+	r.Get("/", func(http.ResponseWriter, *http.Request) {})
+
+	req := newRequest(t, http.MethodGet, "/?a=1&a=2&b=3", nil)
+	compareRespStatus(t, r, req, http.StatusBadRequest)
+
+	var want extractor.StatusError
+	test.WantError(t, gotResp.Error, &want)
+	test.Equal(t, want.Message, "3 query params exceeds the limit of 2")
+	test.Equal(t, gotResp.Status, http.StatusBadRequest)
+}
+
+func TestLimitQueryParams_WithinLimit(t *testing.T) {
+	r := newTestRouter(t)
+	r.Use(routey.LimitQueryParams(r, 2))
+
+	type input struct {
+		A routey.Query[int] `name:"a"`
+		B routey.Query[int] `name:"b"`
+	}
+
+	got := 0
+	routey.Get(r, "/", func(in input) (any, error) {
+		got = in.A.Value + in.B.Value
+		return nil, nil
+	})
+
+	req := newRequest(t, http.MethodGet, "/?a=1&b=2", nil)
+	compareRespStatus(t, r, req, http.StatusOK)
+	test.Equal(t, got, 3)
+}
@@ -0,0 +1,13 @@
+// Package option contains [route.Option] funcs that only
+// modify the [route.Info] and do not depend on a spec.
+package option
+
+import "github.com/zhamlin/routey/route"
+
+// Name sets the name of the route, which must be unique for the router.
+func Name(name string) route.Option {
+	return func(i *route.Info) error {
+		i.Name = name
+		return nil
+	}
+}
@@ -2,6 +2,7 @@ package route
 
 import (
 	"reflect"
+	"time"
 
 	"github.com/zhamlin/routey/param"
 )
@@ -13,6 +14,8 @@ type Context map[any]any
 // RouteInfo contains information about a given pattern and its handler.
 type Info struct {
 	Handler any `json:"-"`
+	// Name is an optional unique name used to reference the route.
+	Name string
 	// Method is optional, a handler could be for all methods.
 	Method string
 	// Full pattern that of the route. If mounted this will contain
@@ -26,4 +29,17 @@ type Info struct {
 	// Stored values provided during the route registering.
 	Context Context `json:"-"`
 	Options []Option
+	// Timeout is the maximum duration the handler can run for,
+	// no timeout is applied when zero.
+	Timeout time.Duration
+	// MaxBodySize is the maximum size in bytes of the request body,
+	// no limit is applied when zero.
+	MaxBodySize int64
+
+	// DerivedFrom is set when the route was created from another route,
+	// such as a HEAD route created from a GET route.
+	DerivedFrom *Info `json:"-"`
+	// Any is set when the route was registered for each method by Any,
+	// its name and operation id are suffixed with the lowercase method.
+	Any bool `json:"-"`
 }
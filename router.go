@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"path"
 	"reflect"
 	"slices"
 	"strings"
@@ -20,7 +21,16 @@ import (
 
 type Path[T any] = extractor.Path[T]
 type Query[T any] = extractor.Query[T]
+type QueryOrForm[T any] = extractor.QueryOrForm[T]
 type JSON[T any] = extractor.JSON[T]
+type XML[T any] = extractor.XML[T]
+type Form[T any] = extractor.Form[T]
+type OneOfBody[A, B extractor.ContentTyper] = extractor.OneOfBody[A, B]
+type Body[T any] = extractor.Body[T]
+type RawBody = extractor.RawBody
+type Content = extractor.Content
+type SSE = extractor.SSE
+type RangeContent = extractor.RangeContent
 
 // Mux is the interface implemented by an object that can
 // be used as a http handler.
@@ -31,23 +41,22 @@ type Mux interface {
 	Handle(method, pattern string, handler http.Handler)
 }
 
-func newParamParsers() param.Parser {
-	parsers := param.Parsers{
-		param.ParseTextUnmarshaller,
-		param.ParseInt,
-		param.ParseUint,
-		param.ParseFloat,
-		param.ParseString,
-		param.ParseBool,
-	}
-
-	reflectParser := param.NewReflectParser(parsers.Parse)
-	finalParser := param.Parsers{parsers.Parse, reflectParser}
-	return finalParser.Parse
+// Matcher is the interface implemented by a [Mux] that can find
+// the handler for a request without serving it.
+type Matcher interface {
+	// Handler returns the handler for the request and the pattern it
+	// matched, which is empty when no handler is registered. The request
+	// must be ready to be served by the handler, with its path values set.
+	Handler(r *http.Request) (h http.Handler, pattern string)
 }
 
 // New returns a ready to use [Router] with the default settings.
 func New() *Router {
+	params := param.Config{
+		Namer: param.NamerCapitals,
+	}
+	params.SetParsers(param.DefaultParsers()...)
+
 	return &Router{
 		pattern:  "",
 		isNested: false,
@@ -61,16 +70,21 @@ func New() *Router {
 			fmt.Println(err.Error())
 			os.Exit(1)
 		},
-		Params: param.Config{
-			Parser: newParamParsers(),
-			Namer:  param.NamerCapitals,
-		},
+		Params: params,
 		Errors: ErrorConfig{
 			Colored:    false,
 			CallerSkip: 1,
 		},
-		Response: nil,
-		Context:  route.Context{},
+		Response:    nil,
+		Context:     route.Context{},
+		Extractors:  extractor.NewExtractorRegistry(),
+		AutoHead:    false,
+		AutoOptions: false,
+		// handled by the Mux by default
+		MethodNotAllowed: nil,
+		NotFound:         nil,
+		DeferMiddleware:  false,
+		PoolArgs:         false,
 	}
 }
 
@@ -88,18 +102,208 @@ func applyMiddleware(h http.Handler, mw ...Middleware) http.Handler {
 	return h
 }
 
+// Recover returns a middleware that recovers panics from the handlers it
+// wraps, passing an [extractor.PanicError] with the panic value and stack
+// trace to the Response handler of r. A 500 is written when r has no
+// Response handler. Panics with [http.ErrAbortHandler] are not recovered.
+func Recover(r *Router) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			var err error
+			func() {
+				defer extractor.RecoverPanic(&err)
+				next.ServeHTTP(w, req)
+			}()
+
+			if err != nil {
+				respondError(r, w, req, err, http.StatusInternalServerError)
+			}
+		})
+	}
+}
+
+// respondError passes the error with the status code to the Response handler
+// of r, or writes it directly when r has no Response handler.
+func respondError(r *Router, w http.ResponseWriter, req *http.Request, err error, code int) {
+	if r.Response != nil {
+		r.Response(w, req, extractor.Response{Error: err, Status: code})
+		return
+	}
+
+	if h, ok := err.(http.Handler); ok {
+		h.ServeHTTP(w, req)
+		return
+	}
+	http.Error(w, http.StatusText(code), code)
+}
+
 type sharedRoutes struct {
 	Routes []*route.Info
+	// patterns with an OPTIONS handler added by [Router.AutoOptions]
+	autoOptions map[string]bool
+	// middleware applied to each route when registered, outermost first
+	middleware map[*route.Info][]Middleware
+	// handlers added by [Router.HandleNotFound], longest prefix first
+	notFound []prefixHandler
+	// methods registered for each full pattern, in the order they were added
+	methods map[string][]string
+	// unique methods of all routes, in the order they were added
+	allMethods []string
+}
+
+// prefixHandler handles requests with a path starting with the prefix.
+type prefixHandler struct {
+	prefix  []string
+	handler http.Handler
+}
+
+func patternSegments(pattern string) []string {
+	pattern = strings.Trim(pattern, "/")
+	if pattern == "" {
+		return nil
+	}
+	return strings.Split(pattern, "/")
+}
+
+// hasPrefix returns true if the path starts with the segments of the
+// prefix, where wildcard segments of the prefix match any segment.
+func (p prefixHandler) hasPrefix(path []string) bool {
+	if len(p.prefix) > len(path) {
+		return false
+	}
+
+	for i, segment := range p.prefix {
+		isWildcard := strings.HasPrefix(segment, "{")
+		if !isWildcard && segment != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (sb *sharedRoutes) setNotFound(pattern string, h http.Handler) {
+	prefix := patternSegments(pattern)
+	sb.notFound = slices.DeleteFunc(sb.notFound, func(p prefixHandler) bool {
+		return slices.Equal(p.prefix, prefix)
+	})
+
+	sb.notFound = append(sb.notFound, prefixHandler{prefix: prefix, handler: h})
+	slices.SortStableFunc(sb.notFound, func(a, b prefixHandler) int {
+		return len(b.prefix) - len(a.prefix)
+	})
+}
+
+// notFoundFor returns the not found handler with the longest
+// prefix matching the path, or nil if there are none.
+func (sb *sharedRoutes) notFoundFor(path string) http.Handler {
+	segments := patternSegments(path)
+	for _, p := range sb.notFound {
+		if p.hasPrefix(segments) {
+			return p.handler
+		}
+	}
+	return nil
+}
+
+func (sb *sharedRoutes) setMiddleware(info *route.Info, mw []Middleware) {
+	if sb.middleware == nil {
+		sb.middleware = map[*route.Info][]Middleware{}
+	}
+	sb.middleware[info] = mw
+}
+
+// find returns the route matching the method and full pattern.
+func (sb *sharedRoutes) find(method, pattern string) *route.Info {
+	for _, info := range sb.Routes {
+		if info.Method == method && info.FullPattern == pattern {
+			return info
+		}
+	}
+	return nil
+}
+
+// Methods returns the unique methods registered for the pattern,
+// in the order they were added.
+func (sb *sharedRoutes) Methods(pattern string) []string {
+	var methods []string
+	for _, info := range sb.Routes {
+		if info.FullPattern != pattern || info.Method == "" {
+			continue
+		}
+
+		if !slices.Contains(methods, info.Method) {
+			methods = append(methods, info.Method)
+		}
+	}
+	return methods
+}
+
+// ByName returns the route with the provided name.
+func (sb *sharedRoutes) ByName(name string) *route.Info {
+	for _, info := range sb.Routes {
+		if info.Name == name {
+			return info
+		}
+	}
+	return nil
+}
+
+// ensureUniqueName returns an error if another route has the same name as info.
+func (sb *sharedRoutes) ensureUniqueName(info *route.Info) error {
+	if info.Name == "" {
+		return nil
+	}
+
+	for _, other := range sb.Routes {
+		if other != info && other.Name == info.Name {
+			return fmt.Errorf(
+				"%w: %q: already used by %q",
+				ErrDuplicateRouteName, info.Name, other.Method+" "+other.FullPattern,
+			)
+		}
+	}
+	return nil
 }
 
 func (sb *sharedRoutes) Append(infos ...*route.Info) {
 	sb.Routes = append(sb.Routes, infos...)
+	for _, info := range infos {
+		sb.indexMethod(info)
+	}
+}
+
+// indexMethod adds the method of the route to the methods of its pattern.
+func (sb *sharedRoutes) indexMethod(info *route.Info) {
+	method := info.Method
+	if method == "" {
+		return
+	}
+
+	if sb.methods == nil {
+		sb.methods = map[string][]string{}
+	}
+
+	if methods := sb.methods[info.FullPattern]; !slices.Contains(methods, method) {
+		sb.methods[info.FullPattern] = append(methods, method)
+	}
+
+	if !slices.Contains(sb.allMethods, method) {
+		sb.allMethods = append(sb.allMethods, method)
+	}
 }
 
 func (sb *sharedRoutes) Pop() (*route.Info, bool) {
 	if len(sb.Routes) > 0 {
 		last := sb.Routes[len(sb.Routes)-1]
 		sb.Routes = sb.Routes[:len(sb.Routes)-1]
+		delete(sb.middleware, last)
+
+		if last.Method != "" && sb.find(last.Method, last.FullPattern) == nil {
+			sb.methods[last.FullPattern] = slices.DeleteFunc(
+				sb.methods[last.FullPattern],
+				func(method string) bool { return method == last.Method },
+			)
+		}
 		return last, true
 	}
 	return nil, false
@@ -120,6 +324,37 @@ type Router struct {
 	routes     *sharedRoutes
 	// The base router used to register handlers with.
 	Mux Mux
+	// AutoHead adds a HEAD route derived from every GET route added to the
+	// routes, so it can be documented. No handler is registered with the Mux,
+	// as [http.ServeMux] already handles HEAD requests with GET handlers.
+	// Paths with a HEAD route registered before the GET route are skipped.
+	AutoHead bool
+	// AutoOptions registers an OPTIONS handler for every path added that
+	// responds with a 204 and the Allow header set to the paths methods.
+	// Paths with an OPTIONS route registered before any other methods are skipped.
+	AutoOptions bool
+	// MethodNotAllowed is called when a request matches a path but not a method,
+	// with the Allow header already set to the methods registered for the path.
+	// Requires the Mux to implement [Matcher], when nil the Mux handles these requests.
+	MethodNotAllowed http.Handler
+	// NotFound is called with the routers global middleware applied when a
+	// request does not match any path. Requires the Mux to implement [Matcher],
+	// when nil the Mux handles these requests.
+	NotFound http.Handler
+	// DeferMiddleware applies the global middleware when serving a request
+	// instead of to each route when it is registered. This allows [Router.Use]
+	// to be called after routes are added, and the middleware runs for every
+	// request, including those without a matching route.
+	//
+	// The middleware runs before the Mux matches the request, so path values
+	// and the matched pattern are not set on the request yet. Middleware added
+	// with Use inside of [Router.Route] only applies to the routes added after it.
+	DeferMiddleware bool
+	// PoolArgs reuses the argument structs of typed handlers between requests
+	// to reduce allocations. Handlers receive a copy of the struct, but any
+	// extractor keeping a pointer into it after the request will see it reset
+	// and reused, so only enable this when no extractors do so.
+	PoolArgs bool
 	// Called when there is an error while registering handlers.
 	ErrorSink func(error)
 	// Called when a new route is added to the router.
@@ -129,13 +364,32 @@ type Router struct {
 	Response extractor.ResponseHandler
 	Params   param.Config
 	Errors   ErrorConfig
+	// Extractors used for handler argument fields of this router,
+	// falling back to the global registry when a type is not found.
+	Extractors *extractor.ExtractorRegistry
 }
 
 func (r *Router) Routes() []*route.Info {
 	return r.routes.Routes
 }
 
+// RouteByName returns the route with the name set on its [route.Info],
+// or nil if no route has the name.
+func (r *Router) RouteByName(name string) *route.Info {
+	return r.routes.ByName(name)
+}
+
+// RouteLister is implemented by handlers listing the routes they serve,
+// such as [Router]. The routes of a mounted RouteLister are added to the
+// router with the mount pattern as a prefix.
+type RouteLister interface {
+	Routes() []*route.Info
+}
+
+var _ RouteLister = &Router{}
+
 // Mount handles nested routers by applying global middleware to the mounted handler.
+// The routes of handlers implementing [RouteLister] are added to the router.
 func (r *Router) Mount(pattern string, handler http.Handler) {
 	newPattern, err := url.JoinPath(pattern, "/")
 	if err != nil {
@@ -143,7 +397,7 @@ func (r *Router) Mount(pattern string, handler http.Handler) {
 	}
 
 	handle := r.Handle
-	router, ok := handler.(*Router)
+	lister, ok := handler.(RouteLister)
 
 	if ok {
 		handle = r.silentHandle
@@ -151,17 +405,42 @@ func (r *Router) Mount(pattern string, handler http.Handler) {
 
 	handle("", newPattern, http.StripPrefix(pattern, handler))
 
-	if ok {
-		// remove the route added from handle call above
-		r.routes.Pop()
+	if !ok {
+		return
+	}
+
+	// remove the route added from handle call above
+	r.routes.Pop()
+
+	// middleware applied to the mounted handler, and by it when serving
+	mountMW := r.appliedMiddleware()
+	router, isRouter := handler.(*Router)
+	if isRouter && router.DeferMiddleware {
+		mountMW = append(mountMW, router.middleware.global...)
+	}
+
+	for _, route := range lister.Routes() {
+		var routeMW []Middleware
+		if isRouter {
+			routeMW = router.routes.middleware[route]
+		} else {
+			// the routes are owned by the handler, so update a copy
+			info := *route
+			route = &info
+		}
 
-		for _, route := range router.routes.Routes {
-			route.FullPattern = joinPatterns(newPattern, route.FullPattern)
-			route.Context = maps.Clone(r.Context)
+		route.FullPattern = joinPatterns(newPattern, route.FullPattern)
+		route.Context = maps.Clone(r.Context)
 
-			r.routes.Append(route)
-			r.onRouteAdd(route)
+		if err := r.routes.ensureUniqueName(route); err != nil {
+			r.handleError(maybeToHandlerErr(err, route.Method, route.FullPattern, route.Handler))
+			continue
 		}
+
+		r.routes.Append(route)
+		r.routes.setMiddleware(route, slices.Concat(mountMW, routeMW))
+		r.onRouteAdd(route)
+		r.handleAutoOptions(route)
 	}
 }
 
@@ -174,12 +453,36 @@ func (r *Router) Use(mw ...Middleware) {
 	}
 }
 
+// UsePrepend inserts the middleware at the front of the router middleware
+// stack, so it runs before any middleware already added with [Router.Use].
+// Like Use, only routes added afterwards are affected unless the middleware
+// is deferred.
+func (r *Router) UsePrepend(mw ...Middleware) {
+	if r.isNested {
+		r.middleware.route = slices.Concat(mw, r.middleware.route)
+	} else {
+		r.middleware.global = slices.Concat(mw, r.middleware.global)
+	}
+}
+
 func (r *Router) Route(pattern string, fn func(*Router)) {
 	cloned := r.clone()
 	cloned.pattern = pattern
+	// the global middleware is only applied by the router being served
+	cloned.isNested = r.DeferMiddleware
 	fn(cloned)
 }
 
+// HandleNotFound sets the handler called for requests under the routers pattern
+// that do not match any path, such as for the router passed to [Router.Route].
+// The handler for the longest matching pattern is used, falling back to NotFound.
+// The middleware of the router is applied to the handler. Requires the Mux to
+// implement [Matcher].
+func (r *Router) HandleNotFound(h http.Handler) {
+	h = applyMiddleware(h, r.middleware.route...)
+	r.routes.setNotFound(r.pattern, r.withGlobalMiddleware(h))
+}
+
 // With appends the middlware onto the handlers middleware stack.
 func (r *Router) With(mw ...Middleware) *Router {
 	cloned := r.clone()
@@ -198,7 +501,135 @@ func (r *Router) Group(fn func(*Router)) {
 
 // ServeHTTP implments the [http.Handler] interface.
 func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
-	r.Mux.ServeHTTP(w, req)
+	if r.DeferMiddleware && len(r.middleware.global) > 0 {
+		applyMiddleware(http.HandlerFunc(r.serveHTTP), r.middleware.global...).ServeHTTP(w, req)
+		return
+	}
+	r.serveHTTP(w, req)
+}
+
+func (r *Router) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	if r.MethodNotAllowed == nil && r.NotFound == nil && len(r.routes.notFound) == 0 {
+		r.Mux.ServeHTTP(w, req)
+		return
+	}
+
+	m, ok := r.Mux.(Matcher)
+	if !ok {
+		r.Mux.ServeHTTP(w, req)
+		return
+	}
+
+	// the handler of the Mux is used for unmatched requests
+	// not served by the handlers of the router
+	h, pattern := m.Handler(req)
+	if pattern != "" {
+		h.ServeHTTP(w, req)
+		return
+	}
+
+	// paths with other methods are not found handlers, the Mux
+	// responds with a 405 unless MethodNotAllowed is set
+	if allowed := r.allowedMethods(m, req); len(allowed) > 0 {
+		if r.MethodNotAllowed == nil {
+			h.ServeHTTP(w, req)
+			return
+		}
+
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		r.MethodNotAllowed.ServeHTTP(w, req)
+		return
+	}
+
+	if h := r.routes.notFoundFor(req.URL.Path); h != nil {
+		h.ServeHTTP(w, req)
+		return
+	}
+
+	if r.NotFound != nil {
+		r.withGlobalMiddleware(r.NotFound).ServeHTTP(w, req)
+		return
+	}
+
+	h.ServeHTTP(w, req)
+}
+
+// MiddlewareFor returns the names of the middleware that run for the route
+// matching the method and full pattern, outermost first. Nil is returned when
+// no route matches. Middleware is named after its func using the package, or the
+// enclosing func, and its name. Anonymous middleware have generated names such
+// as "NewLogger.func1", so middleware returned by the same func share a name.
+func (r *Router) MiddlewareFor(method, pattern string) []string {
+	info := r.routes.find(method, pattern)
+	if info == nil {
+		return nil
+	}
+
+	var middleware []Middleware
+	if r.DeferMiddleware {
+		middleware = slices.Clone(r.middleware.global)
+	}
+	middleware = append(middleware, r.routes.middleware[info]...)
+
+	names := make([]string, 0, len(middleware))
+	for _, mw := range middleware {
+		names = append(names, middlewareName(mw))
+	}
+	return names
+}
+
+func middlewareName(mw Middleware) string {
+	fn := internal.GetFnInfo(mw)
+	return path.Base(fn.Pkg) + "." + fn.Name
+}
+
+// appliedMiddleware returns the middleware applied to routes when registered.
+func (r *Router) appliedMiddleware() []Middleware {
+	if r.DeferMiddleware {
+		return slices.Clone(r.middleware.route)
+	}
+	return slices.Concat(r.middleware.global, r.middleware.route)
+}
+
+// allowedMethods returns all registered methods that have a handler for the
+// requests path. Each pattern matched adds all of the methods registered for
+// it, so only methods of other patterns need to be matched again.
+func (r *Router) allowedMethods(m Matcher, req *http.Request) []string {
+	var allowed []string
+	for _, method := range r.routes.allMethods {
+		if slices.Contains(allowed, method) {
+			continue
+		}
+
+		methodReq := *req
+		methodReq.Method = method
+
+		_, pattern := m.Handler(&methodReq)
+		if pattern == "" {
+			continue
+		}
+
+		pattern = strings.TrimPrefix(pattern, method+" ")
+		for _, patternMethod := range r.routes.methods[pattern] {
+			if !slices.Contains(allowed, patternMethod) {
+				allowed = append(allowed, patternMethod)
+			}
+		}
+
+		if !slices.Contains(allowed, method) {
+			allowed = append(allowed, method)
+		}
+	}
+	return allowed
+}
+
+// MethodNotAllowedHandler returns a handler that responds
+// with a 405 method not allowed error.
+func MethodNotAllowedHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		code := http.StatusMethodNotAllowed
+		http.Error(w, http.StatusText(code), code)
+	})
 }
 
 func joinPatterns(prefix, pattern string) string {
@@ -220,8 +651,28 @@ func joinPatterns(prefix, pattern string) string {
 	return prefix + "/" + pattern
 }
 
+var (
+	ErrAutoOptionsExists  = errors.New("OPTIONS handler already added by AutoOptions")
+	ErrAutoHeadExists     = errors.New("HEAD route already added by AutoHead")
+	ErrDuplicateRouteName = errors.New("duplicate route name")
+)
+
 func (r *Router) Handle(method, pattern string, handler http.Handler, opts ...route.Option) {
 	pattern = joinPatterns(r.pattern, pattern)
+	if method == http.MethodOptions && r.routes.autoOptions[pattern] {
+		err := maybeToHandlerErr(ErrAutoOptionsExists, method, pattern, handler)
+		r.handleError(err)
+		return
+	}
+
+	if method == http.MethodHead {
+		if head := r.routes.find(method, pattern); head != nil && head.DerivedFrom != nil {
+			err := maybeToHandlerErr(ErrAutoHeadExists, method, pattern, handler)
+			r.handleError(err)
+			return
+		}
+	}
+
 	info := r.getOrAddRouteInfo(route.Info{
 		Method:      method,
 		FullPattern: pattern,
@@ -238,10 +689,112 @@ func (r *Router) Handle(method, pattern string, handler http.Handler, opts ...ro
 		}
 	}
 
+	if err := r.routes.ensureUniqueName(info); err != nil {
+		r.routes.Pop()
+		r.handleError(maybeToHandlerErr(err, method, pattern, info.Handler))
+		return
+	}
+
+	if info.Timeout > 0 {
+		handler = http.TimeoutHandler(handler, info.Timeout, "")
+	}
+
+	if info.MaxBodySize > 0 {
+		handler = maxBodySizeHandler(handler, info.MaxBodySize)
+	}
+
 	handler = applyMiddleware(handler, r.middleware.route...)
-	handler = applyMiddleware(handler, r.middleware.global...)
+	handler = r.withGlobalMiddleware(handler)
 
 	r.Mux.Handle(method, pattern, handler)
+	r.routes.setMiddleware(info, r.appliedMiddleware())
+	r.onRouteAdd(info)
+
+	if r.AutoHead && method == http.MethodGet {
+		r.handleHead(info)
+	}
+
+	r.handleAutoOptions(info)
+}
+
+// withGlobalMiddleware applies the global middleware to h,
+// unless they are applied when serving requests.
+func (r *Router) withGlobalMiddleware(h http.Handler) http.Handler {
+	if r.DeferMiddleware {
+		return h
+	}
+	return applyMiddleware(h, r.middleware.global...)
+}
+
+// maxBodySizeHandler responds with a 413 to requests declaring a Content-Length
+// larger than size, without reading the body. Bodies with an unknown length
+// are limited to size bytes while being read.
+func maxBodySizeHandler(h http.Handler, size int64) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.ContentLength > size {
+			code := http.StatusRequestEntityTooLarge
+			http.Error(w, http.StatusText(code), code)
+			return
+		}
+
+		if req.Body != nil {
+			req.Body = http.MaxBytesReader(w, req.Body, size)
+		}
+		h.ServeHTTP(w, req)
+	})
+}
+
+// handleAutoOptions registers an OPTIONS handler for the routes pattern
+// if one does not already exist.
+func (r *Router) handleAutoOptions(info *route.Info) {
+	if !r.AutoOptions || info.Method == "" {
+		return
+	}
+
+	pattern := info.FullPattern
+	methods := r.routes.Methods(pattern)
+
+	if r.routes.autoOptions[pattern] || slices.Contains(methods, http.MethodOptions) {
+		return
+	}
+
+	if r.routes.autoOptions == nil {
+		r.routes.autoOptions = map[string]bool{}
+	}
+	r.routes.autoOptions[pattern] = true
+
+	routes := r.routes
+	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		// info.FullPattern can change if the router is mounted
+		allow := routes.Methods(info.FullPattern)
+		w.Header().Set("Allow", strings.Join(allow, ", "))
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	handler = applyMiddleware(handler, r.middleware.route...)
+	handler = r.withGlobalMiddleware(handler)
+	r.Mux.Handle(http.MethodOptions, pattern, handler)
+}
+
+// handleHead adds a HEAD route derived from the GET route,
+// unless the pattern already has a HEAD route.
+func (r *Router) handleHead(get *route.Info) {
+	if r.routes.find(http.MethodHead, get.FullPattern) != nil {
+		return
+	}
+
+	info := &route.Info{
+		Handler:     get.Handler,
+		Method:      http.MethodHead,
+		FullPattern: get.FullPattern,
+		Pattern:     get.Pattern,
+		Params:      get.Params,
+		ReturnType:  get.ReturnType,
+		Context:     maps.Clone(r.Context),
+		DerivedFrom: get,
+	}
+	r.routes.Append(info)
+	r.routes.setMiddleware(info, r.routes.middleware[get])
 	r.onRouteAdd(info)
 }
 
@@ -269,6 +822,46 @@ func (r *Router) Delete(pattern string, handler http.HandlerFunc, opts ...route.
 	r.Handle(http.MethodDelete, pattern, handler, opts...)
 }
 
+// Any registers the handler for each of the standard methods, creating a
+// route per method. HEAD is skipped when derived from GET by [Router.AutoHead].
+// A name set by the options is suffixed with the lowercase method,
+// e.g. "proxy_get", to keep it unique.
+func (r *Router) Any(pattern string, handler http.HandlerFunc, opts ...route.Option) {
+	opts = append(slices.Clone(opts), anyRoute)
+	for _, method := range r.anyMethods() {
+		r.Handle(method, pattern, handler, opts...)
+	}
+}
+
+// anyRoute marks the route as registered by Any and suffixes
+// its name with the method.
+func anyRoute(i *route.Info) error {
+	i.Any = true
+	if i.Name != "" {
+		i.Name += "_" + strings.ToLower(i.Method)
+	}
+	return nil
+}
+
+// anyMethods returns the methods registered by [Router.Any]. OPTIONS is first
+// so [Router.AutoOptions] does not register its own handler for the path.
+func (r *Router) anyMethods() []string {
+	methods := []string{
+		http.MethodOptions,
+		http.MethodGet,
+		http.MethodHead,
+		http.MethodPut,
+		http.MethodPost,
+		http.MethodPatch,
+		http.MethodDelete,
+	}
+
+	if r.AutoHead {
+		return slices.DeleteFunc(methods, func(m string) bool { return m == http.MethodHead })
+	}
+	return methods
+}
+
 func (r *Router) silentHandle(method, pattern string, handler http.Handler, opts ...route.Option) {
 	r.silentAdd = true
 	r.Handle(method, pattern, handler, opts...)
@@ -284,16 +877,36 @@ func (r *Router) clone() *Router {
 			global: slices.Clone(r.middleware.global),
 			route:  slices.Clone(r.middleware.route),
 		},
-		Mux:        r.Mux,
-		ErrorSink:  r.ErrorSink,
-		Response:   r.Response,
-		Params:     r.Params,
-		Errors:     r.Errors,
-		OnRouteAdd: r.OnRouteAdd,
-		Context:    maps.Clone(r.Context),
+		Mux:         r.Mux,
+		ErrorSink:   r.ErrorSink,
+		Response:    r.Response,
+		Params:      r.Params,
+		Errors:      r.Errors,
+		OnRouteAdd:  r.OnRouteAdd,
+		Context:     maps.Clone(r.Context),
+		Extractors:  r.Extractors,
+		AutoHead:    r.AutoHead,
+		AutoOptions: r.AutoOptions,
+
+		MethodNotAllowed: r.MethodNotAllowed,
+		NotFound:         r.NotFound,
+		DeferMiddleware:  r.DeferMiddleware,
+		PoolArgs:         r.PoolArgs,
 	}
 }
 
+// Clone returns a new router with the same configuration and middleware as r.
+// Unlike the routers used by [Router.Group] and [Router.With], the clone does not
+// share any routes with r, registers handlers on a new [std.Mux], and has its own
+// copy of the Extractors. Values in Context are copied shallowly.
+func (r *Router) Clone() *Router {
+	cloned := r.clone()
+	cloned.routes = &sharedRoutes{}
+	cloned.Mux = std.Mux{ServeMux: http.NewServeMux()}
+	cloned.Extractors = r.Extractors.Clone()
+	return cloned
+}
+
 func (r *Router) onRouteAdd(info *route.Info) {
 	if fn := r.OnRouteAdd; !r.silentAdd && fn != nil {
 		if err := fn(info); err != nil {
@@ -334,11 +947,18 @@ func (r *Router) handlerParams(pattern string) extractor.HandlerParams {
 	return extractor.HandlerParams{
 		Response:         r.Response,
 		ErrorSink:        r.handleError,
-		Parser:           r.Params.Parser,
+		Parser:           r.Params.GetParser(),
+		ContextParser:    r.Params.ContextParser,
 		Namer:            r.Params.Namer,
+		JSONNames:        r.Params.JSONNames,
+		FormTagKey:       r.Params.FormTagKey,
+		Joiner:           r.Params.Joiner,
 		ParamPather:      r.Mux,
+		Extractors:       r.Extractors,
 		Pattern:          pattern,
 		CollectAllErrors: r.Errors.CollectAll,
+		RecoverPanics:    r.Errors.RecoverPanics,
+		PoolArgs:         r.PoolArgs,
 	}
 }
 
@@ -374,7 +994,7 @@ func Handle[T, R any](
 	}
 	hParmas := r.handlerParams(errPattern)
 
-	params, err := param.InfoFromStruct[T](r.Params.Namer, r.Params.Parser)
+	params, err := param.InfoFromType(reflect.TypeFor[T](), r.Params)
 	if err != nil {
 		r.handleError(HandlerError{
 			Err:     err,
@@ -431,3 +1051,11 @@ func Patch[T, R any](r *Router, pattern string, fn func(T) (R, error), opts ...r
 func Delete[T, R any](r *Router, pattern string, fn func(T) (R, error), opts ...route.Option) {
 	Handle(r, http.MethodDelete, pattern, fn, opts...)
 }
+
+// Any registers fn for each of the standard methods, see [Router.Any].
+func Any[T, R any](r *Router, pattern string, fn func(T) (R, error), opts ...route.Option) {
+	opts = append(slices.Clone(opts), anyRoute)
+	for _, method := range r.anyMethods() {
+		Handle(r, method, pattern, fn, opts...)
+	}
+}
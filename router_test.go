@@ -2,6 +2,7 @@ package routey_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -18,6 +19,7 @@ import (
 	"github.com/zhamlin/routey/internal/test"
 	"github.com/zhamlin/routey/param"
 	"github.com/zhamlin/routey/route"
+	routeOption "github.com/zhamlin/routey/route/option"
 )
 
 func expectErrSink(t *testing.T, want any) func(error) {
@@ -302,6 +304,215 @@ func TestRouter_MiddlewareOrder(t *testing.T) {
 	}
 }
 
+func TestRouter_UsePrepend(t *testing.T) {
+	r := newTestRouter(t)
+	gotOrder := []string{}
+	mw := func(name string) routey.Middleware {
+		return func(h http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotOrder = append(gotOrder, name)
+				h.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	r.Use(mw("global-1"), mw("global-2"))
+	r.UsePrepend(mw("prepended-1"), mw("prepended-2"))
+
+	r.Group(func(r *routey.Router) {
+		r.Use(mw("group"))
+		r.UsePrepend(mw("group-prepended"))
+		r.Get("/foo", func(http.ResponseWriter, *http.Request) {})
+	})
+
+	req := newRequest(t, http.MethodGet, "/foo", nil)
+	compareRespStatus(t, r, req, http.StatusOK)
+
+	wantOrder := []string{
+		"prepended-1", "prepended-2", "global-1", "global-2",
+		"group-prepended", "group",
+	}
+	if !reflect.DeepEqual(gotOrder, wantOrder) {
+		t.Errorf("wanted: %v, got: %v", wantOrder, gotOrder)
+	}
+}
+
+func TestRouter_DeferMiddlewareAfterRoutes(t *testing.T) {
+	r := newTestRouter(t)
+	r.DeferMiddleware = true
+	gotOrder := []string{}
+	mw := func(name string) routey.Middleware {
+		return func(h http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotOrder = append(gotOrder, name)
+				h.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	r.Use(mw("global-1"))
+	r.Group(func(r *routey.Router) {
+		r.Use(mw("group"))
+		r.Get("/foo", func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+		})
+	})
+	r.Use(mw("global-2"))
+
+	req := newRequest(t, http.MethodGet, "/foo", nil)
+	compareRespStatus(t, r, req, http.StatusCreated)
+
+	wantOrder := []string{"global-1", "global-2", "group"}
+	if !reflect.DeepEqual(gotOrder, wantOrder) {
+		t.Errorf("wanted: %v, got: %v", wantOrder, gotOrder)
+	}
+
+	gotOrder = []string{}
+	req = newRequest(t, http.MethodGet, "/missing", nil)
+	compareRespStatus(t, r, req, http.StatusNotFound)
+
+	wantOrder = []string{"global-1", "global-2"}
+	if !reflect.DeepEqual(gotOrder, wantOrder) {
+		t.Errorf("wanted: %v, got: %v", wantOrder, gotOrder)
+	}
+}
+
+func TestRouter_DeferMiddlewareRoute(t *testing.T) {
+	r := newTestRouter(t)
+	r.DeferMiddleware = true
+	wantMW := func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+			h.ServeHTTP(w, r)
+		})
+	}
+
+	r.Route("/v1", func(r *routey.Router) {
+		r.Use(wantMW)
+		r.Get("/foo", func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		})
+	})
+
+	r.Get("/bar", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	})
+
+	req := newRequest(t, http.MethodGet, "/v1/foo", nil)
+	compareRespStatus(t, r, req, http.StatusCreated)
+
+	req = newRequest(t, http.MethodGet, "/bar", nil)
+	compareRespStatus(t, r, req, http.StatusBadRequest)
+}
+
+func TestRouter_RecoverPanics(t *testing.T) {
+	r := newTestRouter(t)
+	r.Errors.RecoverPanics = true
+
+	var gotErr error
+	r.Response = func(w http.ResponseWriter, _ *http.Request, resp extractor.Response) {
+		gotErr = resp.Error
+		http.Error(w, "", http.StatusInternalServerError)
+	}
+
+	routey.Get(r, "/", func(struct{}) (any, error) {
+		panic("handler panic")
+	})
+
+	req := newRequest(t, http.MethodGet, "/", nil)
+	compareRespStatus(t, r, req, http.StatusInternalServerError)
+
+	var want extractor.PanicError
+	test.WantError(t, gotErr, &want)
+	test.Equal(t, want.Value, any("handler panic"))
+}
+
+func TestRecover(t *testing.T) {
+	r := newTestRouter(t)
+	r.Use(routey.Recover(r))
+
+	var gotErr error
+	r.Response = func(w http.ResponseWriter, _ *http.Request, resp extractor.Response) {
+		gotErr = resp.Error
+		http.Error(w, "", http.StatusInternalServerError)
+	}
+
+	r.Get("/", func(http.ResponseWriter, *http.Request) {
+		panic("handler panic")
+	})
+
+	req := newRequest(t, http.MethodGet, "/", nil)
+	compareRespStatus(t, r, req, http.StatusInternalServerError)
+
+	var want extractor.PanicError
+	test.WantError(t, gotErr, &want)
+	test.Equal(t, want.Value, any("handler panic"))
+
+	if len(want.Stack) == 0 {
+		t.Error("expected the stack trace of the panic")
+	}
+}
+
+func TestRecover_NoResponseHandler(t *testing.T) {
+	r := newTestRouter(t)
+	r.Response = nil
+	r.Use(routey.Recover(r))
+
+	r.Get("/", func(http.ResponseWriter, *http.Request) {
+		panic("handler panic")
+	})
+
+	req := newRequest(t, http.MethodGet, "/", nil)
+	compareRespStatus(t, r, req, http.StatusInternalServerError)
+}
+
+func loggerMiddleware(h http.Handler) http.Handler { return h }
+func authMiddleware(h http.Handler) http.Handler   { return h }
+func cacheMiddleware(h http.Handler) http.Handler  { return h }
+
+func TestRouter_MiddlewareFor(t *testing.T) {
+	r := newTestRouter(t)
+	r.Use(loggerMiddleware)
+
+	r.Group(func(r *routey.Router) {
+		r.Use(authMiddleware)
+		r.With(cacheMiddleware).Get("/foo", func(http.ResponseWriter, *http.Request) {})
+	})
+	r.Get("/bar", func(http.ResponseWriter, *http.Request) {})
+
+	sub := routey.New()
+	sub.Use(authMiddleware)
+	sub.Get("/baz", func(http.ResponseWriter, *http.Request) {})
+	r.Mount("/sub", sub)
+
+	test.MatchAsJSON(t, r.MiddlewareFor(http.MethodGet, "/foo"), []string{
+		"routey_test.loggerMiddleware",
+		"routey_test.authMiddleware",
+		"routey_test.cacheMiddleware",
+	})
+	test.MatchAsJSON(t, r.MiddlewareFor(http.MethodGet, "/bar"), []string{
+		"routey_test.loggerMiddleware",
+	})
+	test.MatchAsJSON(t, r.MiddlewareFor(http.MethodGet, "/sub/baz"), []string{
+		"routey_test.loggerMiddleware",
+		"routey_test.authMiddleware",
+	})
+	test.Equal(t, len(r.MiddlewareFor(http.MethodPost, "/bar")), 0)
+}
+
+func TestRouter_MiddlewareForDeferred(t *testing.T) {
+	r := newTestRouter(t)
+	r.DeferMiddleware = true
+
+	r.With(cacheMiddleware).Get("/foo", func(http.ResponseWriter, *http.Request) {})
+	r.Use(loggerMiddleware)
+
+	test.MatchAsJSON(t, r.MiddlewareFor(http.MethodGet, "/foo"), []string{
+		"routey_test.loggerMiddleware",
+		"routey_test.cacheMiddleware",
+	})
+}
+
 func TestRouter_HandlerWithMiddleware(t *testing.T) {
 	r := newTestRouter(t)
 	wantMW := func(h http.Handler) http.Handler {
@@ -418,6 +629,42 @@ func TestRouter_MountMiddleware(t *testing.T) {
 	}
 }
 
+type listedHandler struct {
+	routes []*route.Info
+}
+
+func (h listedHandler) Routes() []*route.Info { return h.routes }
+
+func (listedHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/users" {
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+	w.WriteHeader(http.StatusNotFound)
+}
+
+func TestRouter_MountRouteLister(t *testing.T) {
+	r := newTestRouter(t)
+
+	var added []string
+	r.OnRouteAdd = func(info *route.Info) error {
+		added = append(added, info.Method+" "+info.FullPattern)
+		return nil
+	}
+
+	handler := listedHandler{routes: []*route.Info{
+		{Method: http.MethodGet, Pattern: "/users", FullPattern: "/users"},
+	}}
+	r.Mount("/v1", handler)
+
+	test.MatchAsJSON(t, added, []string{"GET /v1/users"})
+	test.Equal(t, r.Routes()[0].FullPattern, "/v1/users")
+	test.Equal(t, handler.routes[0].FullPattern, "/users")
+
+	req := newRequest(t, http.MethodGet, "/v1/users", nil)
+	compareRespStatus(t, r, req, http.StatusCreated)
+}
+
 func TestRouter_HandleInvalidParamErr(t *testing.T) {
 	type Input struct {
 		Value routey.Query[struct{}]
@@ -654,6 +901,186 @@ func TestRouter_MountWithTrailingSlash(t *testing.T) {
 	test.MatchAsJSON(t, got, want)
 }
 
+func TestRouter_ContextParser(t *testing.T) {
+	type tenantKey struct{}
+	type input struct {
+		ID routey.Query[string]
+	}
+
+	var got string
+	h := func(p input) (any, error) {
+		got = p.ID.Value
+		return nil, nil
+	}
+
+	r := routey.New()
+	r.Params.ContextParser = func(ctx context.Context, value any, params []string) error {
+		v, ok := value.(*string)
+		if !ok {
+			return param.ErrInvalidParamType
+		}
+
+		tenant, _ := ctx.Value(tenantKey{}).(string)
+		*v = tenant + "/" + params[0]
+		return nil
+	}
+
+	routey.Get(r, "/", h)
+	req := newRequest(t, http.MethodGet, "/?id=1", nil)
+	req = req.WithContext(context.WithValue(req.Context(), tenantKey{}, "acme"))
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	test.Equal(t, got, "acme/1")
+}
+
+func TestRouter_PointerQueryParam(t *testing.T) {
+	type input struct {
+		Limit routey.Query[*int]
+	}
+
+	var got *int
+	h := func(p input) (any, error) {
+		got = p.Limit.Value
+		return nil, nil
+	}
+
+	r := newTestRouter(t)
+	routey.Get(r, "/", h)
+
+	r.ServeHTTP(httptest.NewRecorder(), newRequest(t, http.MethodGet, "/?limit=3", nil))
+	if got == nil {
+		t.Fatal("expected limit to be set, got nil")
+	}
+	test.Equal(t, *got, 3)
+
+	r.ServeHTTP(httptest.NewRecorder(), newRequest(t, http.MethodGet, "/", nil))
+	if got != nil {
+		t.Errorf("expected a missing limit to be nil, got: %v", *got)
+	}
+}
+
+func TestRouter_QueryParamSeparator(t *testing.T) {
+	type input struct {
+		Tags routey.Query[[]string] `sep:"|"`
+	}
+
+	var got []string
+	h := func(p input) (any, error) {
+		got = p.Tags.Value
+		return nil, nil
+	}
+
+	r := newTestRouter(t)
+	routey.Get(r, "/", h)
+
+	r.ServeHTTP(httptest.NewRecorder(), newRequest(t, http.MethodGet, "/?tags=a,b|c", nil))
+	test.MatchAsJSON(t, got, []string{"a,b", "c"})
+}
+
+func TestRouter_MapQueryParam(t *testing.T) {
+	type input struct {
+		Filter routey.Query[map[string]string]
+	}
+
+	var got map[string]string
+	h := func(p input) (any, error) {
+		got = p.Filter.Value
+		return nil, nil
+	}
+
+	r := newTestRouter(t)
+	routey.Get(r, "/", h)
+
+	r.ServeHTTP(httptest.NewRecorder(), newRequest(t, http.MethodGet, "/?filter=a:1,b:2", nil))
+	test.MatchAsJSON(t, got, map[string]string{"a": "1", "b": "2"})
+}
+
+func TestRouter_JSONNames(t *testing.T) {
+	type input struct {
+		UserID routey.Query[string] `json:"uid"`
+	}
+
+	var got string
+	h := func(p input) (any, error) {
+		got = p.UserID.Value
+		return nil, nil
+	}
+
+	r := newTestRouter(t)
+	r.Params.Namer = param.NamerBySource(param.NamerCapitals, nil)
+	r.Params.JSONNames = true
+	routey.Get(r, "/", h)
+
+	r.ServeHTTP(httptest.NewRecorder(), newRequest(t, http.MethodGet, "/?uid=1", nil))
+	test.Equal(t, got, "1")
+}
+
+func TestRouter_EnumQueryParam(t *testing.T) {
+	type input struct {
+		Sort routey.Query[string] `enum:"asc, desc"`
+	}
+
+	var got string
+	h := func(p input) (any, error) {
+		got = p.Sort.Value
+		return nil, nil
+	}
+
+	r := newTestRouter(t)
+	var gotErr error
+	r.Response = func(_ http.ResponseWriter, _ *http.Request, resp extractor.Response) {
+		gotErr = resp.Error
+		if resp.Error != nil {
+			test.Equal(t, resp.Status, http.StatusBadRequest)
+		}
+	}
+	routey.Get(r, "/", h)
+
+	r.ServeHTTP(httptest.NewRecorder(), newRequest(t, http.MethodGet, "/?sort=desc", nil))
+	test.NoError(t, gotErr)
+	test.Equal(t, got, "desc")
+
+	r.ServeHTTP(httptest.NewRecorder(), newRequest(t, http.MethodGet, "/?sort=up", nil))
+	test.IsError(t, gotErr, param.ErrNotInEnum)
+}
+
+func TestRouter_LengthQueryParam(t *testing.T) {
+	type input struct {
+		Name routey.Query[string] `minLength:"2" maxLength:"4"`
+	}
+
+	var got string
+	h := func(p input) (any, error) {
+		got = p.Name.Value
+		return nil, nil
+	}
+
+	r := newTestRouter(t)
+	var gotErr error
+	r.Response = func(_ http.ResponseWriter, _ *http.Request, resp extractor.Response) {
+		gotErr = resp.Error
+		if resp.Error != nil {
+			test.Equal(t, resp.Status, http.StatusBadRequest)
+		}
+	}
+	routey.Get(r, "/", h)
+
+	r.ServeHTTP(httptest.NewRecorder(), newRequest(t, http.MethodGet, "/?name=abc", nil))
+	test.NoError(t, gotErr)
+	test.Equal(t, got, "abc")
+
+	var want param.LengthError
+	r.ServeHTTP(httptest.NewRecorder(), newRequest(t, http.MethodGet, "/?name=a", nil))
+	test.WantError(t, gotErr, &want)
+	test.Equal(t, want.Limit, 2)
+	test.Equal(t, want.TooLong, false)
+
+	r.ServeHTTP(httptest.NewRecorder(), newRequest(t, http.MethodGet, "/?name=abcde", nil))
+	test.WantError(t, gotErr, &want)
+	test.Equal(t, want.Limit, 4)
+	test.Equal(t, want.TooLong, true)
+}
+
 func TestRouter_CollectAllErrors(t *testing.T) {
 	type input struct {
 		Int      routey.Query[int]
@@ -682,3 +1109,921 @@ func TestRouter_CollectAllErrors(t *testing.T) {
 	w := httptest.NewRecorder()
 	r.ServeHTTP(w, req)
 }
+
+func TestRouter_CollectAllErrorsFieldErrors(t *testing.T) {
+	type filters struct {
+		Limit routey.Query[int]
+	}
+	type input struct {
+		Int     routey.Query[int]
+		Filters filters
+	}
+	h := func(p input) (any, error) { return nil, nil }
+
+	r := routey.New()
+	r.Errors.CollectAll = true
+	r.Params.Joiner = param.JoinDot
+
+	var got []string
+	r.Response = func(_ http.ResponseWriter, _ *http.Request, resp extractor.Response) {
+		var joined interface {
+			Unwrap() []error
+		}
+		test.WantError(t, resp.Error, &joined)
+
+		for _, err := range joined.Unwrap() {
+			var fieldErr *extractor.FieldError
+			test.WantError(t, err, &fieldErr)
+			test.IsError(t, fieldErr, strconv.ErrSyntax)
+			test.Equal(t, fieldErr.Source, "query")
+			got = append(got, fieldErr.Name)
+		}
+	}
+
+	routey.Get(r, "/", h)
+	req := newRequest(t, http.MethodGet, "/?int=a&filters.limit=b", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	test.MatchAsJSON(t, got, []string{"int", "filters.limit"})
+}
+
+func TestRouter_RequiredQueryParam(t *testing.T) {
+	type input struct {
+		Int routey.Query[int] `required:"true"`
+	}
+	h := func(input) (any, error) { return nil, nil }
+
+	r := routey.New()
+	gotError := test.WantAfterTest(t, false, true, "expected an error, got none")
+	r.Response = func(_ http.ResponseWriter, _ *http.Request, resp extractor.Response) {
+		test.IsError(t, resp.Error, extractor.ErrParamRequired)
+		*gotError = true
+	}
+
+	routey.Get(r, "/", h)
+	req := newRequest(t, http.MethodGet, "/", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+}
+
+func TestRouter_UnparsableRequiredValue(t *testing.T) {
+	r := routey.New()
+	var want *param.InvalidParamError
+	r.ErrorSink = expectErrSink(t, &want)
+
+	type input struct {
+		Field routey.Query[int] `required:"yes"`
+	}
+	h := func(input) (any, error) { return nil, nil }
+	routey.Get(r, "/", h)
+
+	wantMsg := param.ErrUnparsableRequired + ": yes"
+	if got := want.Message; got != wantMsg {
+		t.Fatalf("got: %q, want: %q", got, wantMsg)
+	}
+}
+
+func TestRouter_AutoHead(t *testing.T) {
+	r := newTestRouter(t)
+	r.AutoHead = true
+	r.Use(func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Middleware", "true")
+			h.ServeHTTP(w, r)
+		})
+	})
+
+	r.Get("/foo", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("body"))
+	})
+
+	req := newRequest(t, http.MethodHead, "/foo", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	// the GET handler answers, the body is discarded by the server
+	test.Equal(t, w.Code, http.StatusOK)
+	test.Equal(t, w.Header().Get("X-Middleware"), "true")
+
+	routes := r.Routes()
+	test.Equal(t, len(routes), 2)
+	test.Equal(t, routes[1].Method, http.MethodHead)
+	test.Equal(t, routes[1].DerivedFrom, routes[0])
+}
+
+func TestRouter_AutoHeadExistingRoute(t *testing.T) {
+	r := newTestRouter(t)
+	r.AutoHead = true
+
+	want := http.StatusAccepted
+	r.Handle(http.MethodHead, "/foo", http.HandlerFunc(
+		func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(want)
+		},
+	))
+	r.Get("/foo", func(w http.ResponseWriter, _ *http.Request) {})
+
+	req := newRequest(t, http.MethodHead, "/foo", nil)
+	compareRespStatus(t, r, req, want)
+
+	routes := r.Routes()
+	test.Equal(t, len(routes), 2)
+	test.Equal(t, routes[0].DerivedFrom, nil)
+}
+
+func TestRouter_AutoHeadAddedAfterError(t *testing.T) {
+	r := newTestRouter(t)
+	r.AutoHead = true
+
+	gotError := test.WantAfterTest(t, false, true, "expected an error, got none")
+	r.ErrorSink = func(err error) {
+		test.IsError(t, err, routey.ErrAutoHeadExists)
+		*gotError = true
+	}
+
+	h := func(w http.ResponseWriter, _ *http.Request) {}
+	r.Get("/foo", h)
+	r.Handle(http.MethodHead, "/foo", http.HandlerFunc(h))
+}
+
+func TestRouter_InvalidParamStatus(t *testing.T) {
+	type input struct {
+		ID routey.Query[int]
+	}
+
+	r := newTestRouter(t)
+	var got extractor.Response
+	r.Response = func(_ http.ResponseWriter, _ *http.Request, resp extractor.Response) {
+		got = resp
+	}
+	routey.Get(r, "/", func(input) (any, error) { return nil, nil })
+
+	r.ServeHTTP(httptest.NewRecorder(), newRequest(t, http.MethodGet, "/?id=abc", nil))
+	test.IsError(t, got.Error, extractor.ErrParamFailedToExtract)
+	test.Equal(t, got.Status, http.StatusBadRequest)
+}
+
+func TestRouter_AutoHeadDisabled(t *testing.T) {
+	r := newTestRouter(t)
+	r.Get("/foo", func(w http.ResponseWriter, _ *http.Request) {})
+	r.Post("/foo", func(w http.ResponseWriter, _ *http.Request) {})
+
+	test.Equal(t, len(r.Routes()), 2)
+}
+
+func TestRouter_Any(t *testing.T) {
+	r := newTestRouter(t)
+	r.AutoOptions = true
+
+	var got []string
+	r.Any("/foo", func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Method)
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	methods := []string{
+		http.MethodGet,
+		http.MethodPut,
+		http.MethodPost,
+		http.MethodPatch,
+		http.MethodDelete,
+		http.MethodHead,
+		http.MethodOptions,
+	}
+	for _, method := range methods {
+		req := newRequest(t, method, "/foo", nil)
+		compareRespStatus(t, r, req, http.StatusAccepted)
+	}
+	test.MatchAsJSON(t, got, methods)
+
+	var routeMethods []string
+	for _, info := range r.Routes() {
+		routeMethods = append(routeMethods, info.Method)
+	}
+	test.MatchAsJSON(t, routeMethods, []string{
+		http.MethodOptions,
+		http.MethodGet,
+		http.MethodHead,
+		http.MethodPut,
+		http.MethodPost,
+		http.MethodPatch,
+		http.MethodDelete,
+	})
+}
+
+func TestRouter_AnyName(t *testing.T) {
+	r := newTestRouter(t)
+
+	r.Any("/foo", func(http.ResponseWriter, *http.Request) {}, routeOption.Name("proxy"))
+
+	var names []string
+	for _, info := range r.Routes() {
+		names = append(names, info.Name)
+	}
+	test.MatchAsJSON(t, names, []string{
+		"proxy_options",
+		"proxy_get",
+		"proxy_head",
+		"proxy_put",
+		"proxy_post",
+		"proxy_patch",
+		"proxy_delete",
+	})
+}
+
+func TestRouter_AnyAutoHead(t *testing.T) {
+	r := newTestRouter(t)
+	r.AutoHead = true
+
+	routey.Any(r, "/foo", func(struct{}) (any, error) { return nil, nil })
+
+	var heads []*route.Info
+	for _, info := range r.Routes() {
+		if info.Method == http.MethodHead {
+			heads = append(heads, info)
+		}
+	}
+
+	test.Equal(t, len(r.Routes()), 7)
+	test.Equal(t, len(heads), 1)
+	test.Equal(t, heads[0].DerivedFrom.Method, http.MethodGet)
+}
+
+func TestRouter_NestedParamJoiner(t *testing.T) {
+	type filters struct {
+		Limit routey.Query[int]
+	}
+	type input struct {
+		Users  filters
+		Groups filters
+	}
+
+	var got input
+	h := func(i input) (any, error) {
+		got = i
+		return nil, nil
+	}
+
+	r := newTestRouter(t)
+	r.Params.Joiner = param.JoinDot
+	routey.Get(r, "/", h)
+
+	req := newRequest(t, http.MethodGet, "/?users.limit=1&groups.limit=2", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	test.Equal(t, got.Users.Limit.Value, 1)
+	test.Equal(t, got.Groups.Limit.Value, 2)
+
+	routes := r.Routes()
+	test.Equal(t, routes[0].Params[0].Name, "users.limit")
+	test.Equal(t, routes[0].Params[1].Name, "groups.limit")
+}
+
+type pageParams struct {
+	Page  routey.Query[int]
+	Limit routey.Query[int] `name:"per_page"`
+}
+
+type SortParams struct {
+	Sort routey.Query[string]
+}
+
+func TestRouter_EmbeddedParams(t *testing.T) {
+	type input struct {
+		pageParams
+		*SortParams
+		Extra routey.Query[int]
+	}
+
+	var got input
+	h := func(i input) (any, error) {
+		got = i
+		return nil, nil
+	}
+
+	r := newTestRouter(t)
+	r.Params.Joiner = param.JoinDot
+	routey.Get(r, "/", h)
+
+	req := newRequest(t, http.MethodGet, "/?page=1&per_page=2&sort=name&extra=3", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	test.Equal(t, got.Page.Value, 1)
+	test.Equal(t, got.Limit.Value, 2)
+	test.Equal(t, got.Sort.Value, "name")
+	test.Equal(t, got.Extra.Value, 3)
+
+	var names []string
+	for _, p := range r.Routes()[0].Params {
+		names = append(names, p.Name)
+	}
+	test.MatchAsJSON(t, names, []string{"page", "per_page", "sort", "extra"})
+}
+
+func TestRouter_AutoOptions(t *testing.T) {
+	r := newTestRouter(t)
+	r.AutoOptions = true
+
+	h := func(w http.ResponseWriter, _ *http.Request) {}
+	r.Get("/foo", h)
+	r.Post("/foo", h)
+	r.Delete("/bar", h)
+
+	req := newRequest(t, http.MethodOptions, "/foo", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	test.Equal(t, w.Code, http.StatusNoContent)
+	test.Equal(t, w.Header().Get("Allow"), "GET, POST")
+
+	req = newRequest(t, http.MethodOptions, "/bar", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	test.Equal(t, w.Header().Get("Allow"), "DELETE")
+}
+
+func TestRouter_AutoOptionsMount(t *testing.T) {
+	r := newTestRouter(t)
+	r.AutoOptions = true
+	subRouter := newTestRouter(t)
+
+	h := func(w http.ResponseWriter, _ *http.Request) {}
+	subRouter.Get("/foo", h)
+	subRouter.Put("/foo", h)
+	r.Mount("/v1", subRouter)
+
+	req := newRequest(t, http.MethodOptions, "/v1/foo", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	test.Equal(t, w.Code, http.StatusNoContent)
+	test.Equal(t, w.Header().Get("Allow"), "GET, PUT")
+}
+
+func TestRouter_AutoOptionsExistingRoute(t *testing.T) {
+	r := newTestRouter(t)
+	r.AutoOptions = true
+
+	want := http.StatusOK
+	r.Handle(http.MethodOptions, "/foo", http.HandlerFunc(
+		func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(want)
+		},
+	))
+	r.Get("/foo", func(w http.ResponseWriter, _ *http.Request) {})
+
+	req := newRequest(t, http.MethodOptions, "/foo", nil)
+	compareRespStatus(t, r, req, want)
+}
+
+func TestRouter_AutoOptionsAddedAfterError(t *testing.T) {
+	r := newTestRouter(t)
+	r.AutoOptions = true
+
+	gotError := test.WantAfterTest(t, false, true, "expected an error, got none")
+	r.ErrorSink = func(err error) {
+		test.IsError(t, err, routey.ErrAutoOptionsExists)
+		*gotError = true
+	}
+
+	h := func(w http.ResponseWriter, _ *http.Request) {}
+	r.Get("/foo", h)
+	r.Handle(http.MethodOptions, "/foo", http.HandlerFunc(h))
+}
+
+func TestRouter_DuplicateNestedParams(t *testing.T) {
+	type filters struct {
+		Limit routey.Query[int]
+	}
+	type input struct {
+		Users  filters
+		Groups filters
+	}
+	h := func(input) (any, error) { return nil, nil }
+
+	r := routey.New()
+	var want *param.InvalidParamError
+	r.ErrorSink = expectErrSink(t, &want)
+
+	routey.Get(r, "/", h)
+	test.Equal(t, want.Message, param.ErrDuplicateParam+`: query "limit"`)
+}
+
+func TestRouter_MethodNotAllowed(t *testing.T) {
+	r := newTestRouter(t)
+	r.MethodNotAllowed = routey.MethodNotAllowedHandler()
+
+	h := func(w http.ResponseWriter, _ *http.Request) {}
+	r.Get("/foo", h)
+	r.Put("/foo", h)
+	r.Post("/bar", h)
+
+	req := newRequest(t, http.MethodDelete, "/foo", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	test.Equal(t, w.Code, http.StatusMethodNotAllowed)
+	test.Equal(t, w.Header().Get("Allow"), "GET, PUT")
+
+	req = newRequest(t, http.MethodGet, "/unknown", nil)
+	compareRespStatus(t, r, req, http.StatusNotFound)
+}
+
+func TestRouter_MethodNotAllowedPatterns(t *testing.T) {
+	r := newTestRouter(t)
+	r.MethodNotAllowed = routey.MethodNotAllowedHandler()
+
+	h := func(w http.ResponseWriter, _ *http.Request) {}
+	r.Get("/foo/bar", h)
+	r.Delete("/foo/{id}", h)
+	r.Put("/foo/{id}", h)
+
+	req := newRequest(t, http.MethodPost, "/foo/bar", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	test.Equal(t, w.Code, http.StatusMethodNotAllowed)
+	test.Equal(t, w.Header().Get("Allow"), "GET, DELETE, PUT")
+}
+
+func TestRouter_NotFoundPathValues(t *testing.T) {
+	r := newTestRouter(t)
+	r.NotFound = http.NotFoundHandler()
+
+	var got string
+	r.Get("/users/{id}", func(_ http.ResponseWriter, req *http.Request) {
+		got = req.PathValue("id")
+	})
+
+	req := newRequest(t, http.MethodGet, "/users/1", nil)
+	compareRespStatus(t, r, req, http.StatusOK)
+	test.Equal(t, got, "1")
+}
+
+func TestRouter_MethodNotAllowedCustomHandler(t *testing.T) {
+	r := newTestRouter(t)
+	want := http.StatusTeapot
+	r.MethodNotAllowed = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(want)
+	})
+
+	r.Get("/foo", func(w http.ResponseWriter, _ *http.Request) {})
+
+	req := newRequest(t, http.MethodPost, "/foo", nil)
+	compareRespStatus(t, r, req, want)
+
+	req = newRequest(t, http.MethodGet, "/foo", nil)
+	compareRespStatus(t, r, req, http.StatusOK)
+}
+
+func TestRouter_NotFound(t *testing.T) {
+	r := newTestRouter(t)
+
+	middlewareCalled := false
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			middlewareCalled = true
+			next.ServeHTTP(w, req)
+		})
+	})
+
+	r.NotFound = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	r.Get("/foo", func(w http.ResponseWriter, _ *http.Request) {})
+
+	req := newRequest(t, http.MethodGet, "/unknown", nil)
+	compareRespStatus(t, r, req, http.StatusTeapot)
+	test.Equal(t, middlewareCalled, true)
+
+	req = newRequest(t, http.MethodGet, "/foo", nil)
+	compareRespStatus(t, r, req, http.StatusOK)
+}
+
+func TestRouter_NotFoundDefault(t *testing.T) {
+	r := newTestRouter(t)
+	r.Get("/foo", func(w http.ResponseWriter, _ *http.Request) {})
+
+	req := newRequest(t, http.MethodGet, "/unknown", nil)
+	compareRespStatus(t, r, req, http.StatusNotFound)
+}
+
+func TestRouter_MethodNotAllowedDefault(t *testing.T) {
+	r := newTestRouter(t)
+	r.Get("/foo", func(w http.ResponseWriter, _ *http.Request) {})
+
+	req := newRequest(t, http.MethodPost, "/foo", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	test.Equal(t, w.Code, http.StatusMethodNotAllowed)
+	test.Equal(t, w.Header().Get("Allow"), "GET, HEAD")
+}
+
+func TestRouter_NotFoundMethodNotAllowed(t *testing.T) {
+	r := newTestRouter(t)
+	r.NotFound = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	r.Get("/foo", func(w http.ResponseWriter, _ *http.Request) {})
+
+	// paths with other methods are handled by the Mux, not NotFound
+	req := newRequest(t, http.MethodPost, "/foo", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	test.Equal(t, w.Code, http.StatusMethodNotAllowed)
+	test.Equal(t, w.Header().Get("Allow"), "GET, HEAD")
+}
+
+func TestRouter_HandleNotFoundGroups(t *testing.T) {
+	r := newTestRouter(t)
+	r.NotFound = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	notFound := func(version string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = fmt.Fprintf(w, `{"version": %q}`, version)
+		})
+	}
+
+	r.Route("/v1", func(r *routey.Router) {
+		r.HandleNotFound(notFound("v1"))
+		r.Get("/users", func(http.ResponseWriter, *http.Request) {})
+	})
+	r.Route("/v2", func(r *routey.Router) {
+		r.HandleNotFound(notFound("v2"))
+		r.Get("/users", func(http.ResponseWriter, *http.Request) {})
+	})
+	r.Route("/v2/admin", func(r *routey.Router) {
+		r.HandleNotFound(notFound("v2 admin"))
+	})
+
+	tests := []struct {
+		path     string
+		wantCode int
+		wantBody string
+	}{
+		{path: "/v1/unknown", wantCode: http.StatusNotFound, wantBody: `{"version": "v1"}`},
+		{path: "/v2/unknown", wantCode: http.StatusNotFound, wantBody: `{"version": "v2"}`},
+		{path: "/v2/admin/unknown", wantCode: http.StatusNotFound, wantBody: `{"version": "v2 admin"}`},
+		{path: "/v1", wantCode: http.StatusNotFound, wantBody: `{"version": "v1"}`},
+		{path: "/v3/unknown", wantCode: http.StatusTeapot},
+		{path: "/v10/unknown", wantCode: http.StatusTeapot},
+		{path: "/v1/users", wantCode: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, newRequest(t, http.MethodGet, tt.path, nil))
+
+		test.Equal(t, w.Code, tt.wantCode)
+		test.Equal(t, w.Body.String(), tt.wantBody)
+	}
+}
+
+func TestRouter_HandleNotFoundMiddleware(t *testing.T) {
+	r := newTestRouter(t)
+
+	middlewareCalled := false
+	r.Route("/v1", func(r *routey.Router) {
+		r.Use(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				middlewareCalled = true
+				next.ServeHTTP(w, req)
+			})
+		})
+		r.HandleNotFound(http.NotFoundHandler())
+	})
+
+	req := newRequest(t, http.MethodGet, "/v1/unknown", nil)
+	compareRespStatus(t, r, req, http.StatusNotFound)
+	test.Equal(t, middlewareCalled, true)
+}
+
+func TestRouter_QueryOrForm(t *testing.T) {
+	type input struct {
+		Name routey.QueryOrForm[string]
+	}
+
+	r := newTestRouter(t)
+	r.Response = func(w http.ResponseWriter, _ *http.Request, resp extractor.Response) {
+		test.NoError(t, resp.Error)
+		_, _ = fmt.Fprint(w, resp.Response)
+	}
+
+	h := func(i input) (string, error) { return i.Name.Value, nil }
+	routey.Get(r, "/", h)
+	routey.Post(r, "/", h)
+
+	formRequest := func(path, body string) *http.Request {
+		req := newRequest(t, http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return req
+	}
+
+	tests := []struct {
+		name string
+		req  *http.Request
+		want string
+	}{
+		{
+			name: "query",
+			req:  newRequest(t, http.MethodGet, "/?name=query", nil),
+			want: "query",
+		},
+		{
+			name: "form",
+			req:  formRequest("/", "name=form"),
+			want: "form",
+		},
+		{
+			name: "query takes precedence",
+			req:  formRequest("/?name=query", "name=form"),
+			want: "query",
+		},
+		{
+			name: "form requires the content type",
+			req:  newRequest(t, http.MethodPost, "/", strings.NewReader("name=form")),
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, tt.req)
+			test.Equal(t, w.Body.String(), tt.want)
+		})
+	}
+}
+
+func TestRouter_RawBodyAndJSON(t *testing.T) {
+	type obj struct {
+		Field string `json:"field"`
+	}
+	type Input struct {
+		Raw  routey.RawBody
+		Body routey.JSON[obj]
+	}
+
+	var got Input
+	fn := func(i Input) (any, error) {
+		got = i
+		return nil, nil
+	}
+
+	r := newTestRouter(t)
+	routey.Handle(r, http.MethodPost, "/", fn)
+
+	input := `{"field":"test"}`
+	req := newRequest(t, http.MethodPost, "/", strings.NewReader(input))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	test.Equal(t, string(got.Raw.V), input)
+	test.Equal(t, got.Body.V.Field, "test")
+}
+
+func TestRouter_Form(t *testing.T) {
+	type obj struct {
+		Name string `form:"user_name"`
+	}
+	type Input struct {
+		Body routey.Form[obj]
+	}
+
+	var got Input
+	fn := func(i Input) (any, error) {
+		got = i
+		return nil, nil
+	}
+
+	r := newTestRouter(t)
+	routey.Post(r, "/", fn)
+
+	req := newRequest(t, http.MethodPost, "/", strings.NewReader("user_name=test"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	test.Equal(t, got.Body.V.Name, "test")
+}
+
+func TestRouter_OneOfBody(t *testing.T) {
+	type obj struct {
+		Name string `json:"name" form:"name"`
+	}
+	type Input struct {
+		Body routey.OneOfBody[routey.JSON[obj], routey.Form[obj]]
+	}
+
+	var got Input
+	fn := func(i Input) (any, error) {
+		got = i
+		return nil, nil
+	}
+
+	r := newTestRouter(t)
+	routey.Post(r, "/", fn)
+
+	req := newRequest(t, http.MethodPost, "/", strings.NewReader(`{"name":"json"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	test.Equal(t, got.Body.A.V.Name, "json")
+	test.Equal(t, got.Body.B, nil)
+
+	req = newRequest(t, http.MethodPost, "/", strings.NewReader("name=form"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	test.Equal(t, got.Body.A, nil)
+	test.Equal(t, got.Body.B.V.Name, "form")
+}
+
+func TestRouter_OneOfBodyUnsupportedContentType(t *testing.T) {
+	type Input struct {
+		Body routey.OneOfBody[routey.JSON[string], routey.Form[struct{}]]
+	}
+
+	r := routey.New()
+	var gotErr error
+	r.Response = func(_ http.ResponseWriter, _ *http.Request, resp extractor.Response) {
+		gotErr = resp.Error
+	}
+	routey.Post(r, "/", func(Input) (any, error) { return nil, nil })
+
+	req := newRequest(t, http.MethodPost, "/", strings.NewReader("text"))
+	req.Header.Set("Content-Type", "text/plain")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	test.IsError(t, gotErr, extractor.ErrUnsupportedContentType)
+}
+
+func TestRouter_ExtractorsIsolated(t *testing.T) {
+	type user struct{ Name string }
+	type Input struct{ User user }
+
+	newRouter := func(name string) (*routey.Router, *string) {
+		r := newTestRouter(t)
+		extractor.RegisterExtractorIn(r.Extractors, func(*http.Request) (user, error) {
+			return user{Name: name}, nil
+		})
+
+		var got string
+		routey.Get(r, "/", func(i Input) (any, error) {
+			got = i.User.Name
+			return nil, nil
+		})
+		return r, &got
+	}
+
+	a, gotA := newRouter("a")
+	b, gotB := newRouter("b")
+
+	a.ServeHTTP(httptest.NewRecorder(), newRequest(t, http.MethodGet, "/", nil))
+	b.ServeHTTP(httptest.NewRecorder(), newRequest(t, http.MethodGet, "/", nil))
+
+	test.Equal(t, *gotA, "a")
+	test.Equal(t, *gotB, "b")
+
+	// types only registered on another router are unknown
+	c := routey.New()
+	var want *extractor.UnknownFieldTypeError
+	c.ErrorSink = func(err error) {
+		test.WantError(t, err, &want)
+	}
+	routey.Get(c, "/", func(Input) (any, error) { return nil, nil })
+
+	if want == nil {
+		t.Fatal("expected an error, got none")
+	}
+}
+
+func TestRouter_CloneIsolated(t *testing.T) {
+	type user struct{ Name string }
+
+	base := newTestRouter(t)
+	base.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Base", "true")
+			next.ServeHTTP(w, r)
+		})
+	})
+	extractor.RegisterExtractorIn(base.Extractors, func(*http.Request) (user, error) {
+		return user{Name: "base"}, nil
+	})
+	base.Get("/base", http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+
+	cloned := base.Clone()
+	cloned.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Clone", "true")
+			next.ServeHTTP(w, r)
+		})
+	})
+	extractor.RegisterExtractorIn(cloned.Extractors, func(*http.Request) (user, error) {
+		return user{Name: "clone"}, nil
+	})
+
+	var got string
+	routey.Get(cloned, "/clone", func(i struct{ User user }) (any, error) {
+		got = i.User.Name
+		return nil, nil
+	})
+
+	test.Equal(t, len(base.Routes()), 1)
+	test.Equal(t, len(cloned.Routes()), 1)
+
+	w := httptest.NewRecorder()
+	cloned.ServeHTTP(w, newRequest(t, http.MethodGet, "/clone", nil))
+	test.Equal(t, got, "clone")
+	test.Equal(t, w.Header().Get("X-Base"), "true")
+	test.Equal(t, w.Header().Get("X-Clone"), "true")
+
+	// routes and middleware added to the clone do not affect the base
+	w = httptest.NewRecorder()
+	base.ServeHTTP(w, newRequest(t, http.MethodGet, "/clone", nil))
+	test.Equal(t, w.Code, http.StatusNotFound)
+
+	w = httptest.NewRecorder()
+	base.ServeHTTP(w, newRequest(t, http.MethodGet, "/base", nil))
+	test.Equal(t, w.Code, http.StatusOK)
+	test.Equal(t, w.Header().Get("X-Clone"), "")
+
+	w = httptest.NewRecorder()
+	cloned.ServeHTTP(w, newRequest(t, http.MethodGet, "/base", nil))
+	test.Equal(t, w.Code, http.StatusNotFound)
+}
+
+func TestRouter_StructPointerParams(t *testing.T) {
+	type Filters struct {
+		Limit routey.Query[int]
+	}
+	type input struct {
+		*Filters
+	}
+
+	var got input
+	h := func(i input) (any, error) {
+		got = i
+		return nil, nil
+	}
+
+	r := newTestRouter(t)
+	routey.Get(r, "/", h)
+
+	req := newRequest(t, http.MethodGet, "/?limit=1", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	if got.Filters == nil {
+		t.Fatal("expected Filters to be set")
+	}
+	test.Equal(t, got.Limit.Value, 1)
+
+	routes := r.Routes()
+	test.Equal(t, len(routes[0].Params), 1)
+	test.Equal(t, routes[0].Params[0].Name, "limit")
+}
+
+func TestRouter_NamedRoutes(t *testing.T) {
+	h := func(http.ResponseWriter, *http.Request) {}
+
+	sub := newTestRouter(t)
+	sub.Get("/{id}", h, routeOption.Name("user"))
+
+	r := newTestRouter(t)
+	r.Get("/index", h, routeOption.Name("index"))
+	r.Mount("/users", sub)
+
+	index := r.RouteByName("index")
+	test.Equal(t, index.FullPattern, "/index")
+
+	user := r.RouteByName("user")
+	test.Equal(t, user.FullPattern, "/users/{id}")
+
+	if r.RouteByName("missing") != nil {
+		t.Error("expected no route for an unknown name")
+	}
+}
+
+func TestRouter_DuplicateRouteName(t *testing.T) {
+	h := func(struct{}) (any, error) { return nil, nil }
+
+	r := routey.New()
+	gotError := test.WantAfterTest(t, false, true, "expected an error, got none")
+	r.ErrorSink = func(err error) {
+		test.WantError(t, err, &routey.HandlerError{})
+		test.IsError(t, err, routey.ErrDuplicateRouteName)
+		*gotError = true
+	}
+
+	routey.Get(r, "/a", h, routeOption.Name("name"))
+	routey.Get(r, "/b", h, routeOption.Name("name"))
+
+	test.Equal(t, len(r.Routes()), 1)
+	test.Equal(t, r.RouteByName("name").FullPattern, "/a")
+}
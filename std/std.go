@@ -2,6 +2,8 @@ package std
 
 import (
 	"net/http"
+	"net/url"
+	"strings"
 )
 
 type Mux struct {
@@ -19,3 +21,57 @@ func (m Mux) Handle(method, pattern string, handler http.Handler) {
 func (m Mux) Param(name string, r *http.Request) string {
 	return r.PathValue(name)
 }
+
+// Match returns true if a handler is registered for the request.
+func (m Mux) Match(r *http.Request) bool {
+	_, pattern := m.ServeMux.Handler(r)
+	return pattern != ""
+}
+
+// Handler returns the handler for the request and the pattern it matched,
+// which is empty when no handler is registered. The pattern and path values
+// of the request are set like ServeHTTP, so the handler can serve it.
+func (m Mux) Handler(r *http.Request) (http.Handler, string) {
+	h, pattern := m.ServeMux.Handler(r)
+	if pattern != "" {
+		r.Pattern = pattern
+		setPathValues(r, pattern)
+	}
+	return h, pattern
+}
+
+// setPathValues sets the wildcards of the pattern to
+// the segments of the request path they match.
+func setPathValues(r *http.Request, pattern string) {
+	start := strings.Index(pattern, "/")
+	if start < 0 {
+		return
+	}
+
+	segments := strings.Split(pattern[start+1:], "/")
+	path := strings.Split(strings.TrimPrefix(r.URL.EscapedPath(), "/"), "/")
+
+	for i, segment := range segments {
+		name, isWildcard := strings.CutPrefix(segment, "{")
+		if !isWildcard || i >= len(path) {
+			continue
+		}
+		name = strings.TrimSuffix(name, "}")
+
+		if name, isRest := strings.CutSuffix(name, "..."); isRest {
+			r.SetPathValue(name, unescape(strings.Join(path[i:], "/")))
+			return
+		}
+
+		if name != "$" {
+			r.SetPathValue(name, unescape(path[i]))
+		}
+	}
+}
+
+func unescape(s string) string {
+	if u, err := url.PathUnescape(s); err == nil {
+		return u
+	}
+	return s
+}
@@ -27,3 +27,42 @@ func TestParam(t *testing.T) {
 	resp := httptest.NewRecorder()
 	r.ServeHTTP(resp, req)
 }
+
+func TestMatch(t *testing.T) {
+	r := std.Mux{&http.ServeMux{}}
+	r.Handle(http.MethodGet, "/{test}", http.NotFoundHandler())
+
+	req := httptest.NewRequest(http.MethodGet, "/value", nil)
+	if !r.Match(req) {
+		t.Errorf("expected request to match")
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/value", nil)
+	if r.Match(req) {
+		t.Errorf("expected request to not match")
+	}
+}
+
+func TestHandler(t *testing.T) {
+	r := std.Mux{&http.ServeMux{}}
+	r.Handle(http.MethodGet, "/users/{id}/files/{path...}", http.NotFoundHandler())
+
+	req := httptest.NewRequest(http.MethodGet, "/users/a%2Fb/files/docs/c.txt", nil)
+	_, pattern := r.Handler(req)
+	if want := "GET /users/{id}/files/{path...}"; pattern != want || req.Pattern != want {
+		t.Errorf("got pattern: %s, want: %s", pattern, want)
+	}
+
+	if got := req.PathValue("id"); got != "a/b" {
+		t.Errorf("got id: %s, want: a/b", got)
+	}
+
+	if got := req.PathValue("path"); got != "docs/c.txt" {
+		t.Errorf("got path: %s, want: docs/c.txt", got)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/users/1/files/a", nil)
+	if _, pattern := r.Handler(req); pattern != "" {
+		t.Errorf("expected request to not match, got pattern: %s", pattern)
+	}
+}
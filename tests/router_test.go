@@ -127,3 +127,54 @@ func BenchmarkQueryParam(b *testing.B) {
 		})
 	}
 }
+
+func BenchmarkQueryParamPoolArgs(b *testing.B) {
+	type Params struct {
+		w     http.ResponseWriter
+		Value routey.Query[string]
+	}
+
+	h := func(p Params) (any, error) {
+		p.w.WriteHeader(http.StatusCreated)
+		return nil, nil
+	}
+
+	r := routey.New()
+	routey.Get(r, "/", h)
+
+	pooled := routey.New()
+	pooled.PoolArgs = true
+	routey.Get(pooled, "/", h)
+
+	tests := []struct {
+		name    string
+		handler http.Handler
+	}{
+		{
+			name:    "router",
+			handler: r,
+		},
+		{
+			name:    "pooled router",
+			handler: pooled,
+		},
+	}
+
+	for _, test := range tests {
+		b.Run(test.name, func(b *testing.B) {
+			b.ReportAllocs()
+			resp := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/?value=1", nil)
+
+			for b.Loop() {
+				// performance included in benchmark
+				req = req.WithContext(b.Context())
+				test.handler.ServeHTTP(resp, req)
+
+				if resp.Code != http.StatusCreated {
+					b.Fatal("incorrect status code")
+				}
+			}
+		})
+	}
+}
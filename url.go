@@ -0,0 +1,97 @@
+package routey
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/zhamlin/routey/route"
+)
+
+var (
+	ErrRouteNotFound   = errors.New("route not found")
+	ErrMissingURLParam = errors.New("missing url param")
+	ErrUnknownURLParam = errors.New("unknown url param")
+)
+
+// URL returns the path for the route registered with pattern, replacing
+// each {name} and {name...} segment with the matching value from params.
+// The pattern is compared against the routes full pattern first, then
+// the pattern the route was registered with.
+func (r *Router) URL(pattern string, params map[string]string) (string, error) {
+	info, has := r.findRoute(pattern)
+	if !has {
+		return "", fmt.Errorf("%w: %q", ErrRouteNotFound, pattern)
+	}
+
+	return buildURL(info.FullPattern, params)
+}
+
+func (r *Router) findRoute(pattern string) (*route.Info, bool) {
+	for _, info := range r.routes.Routes {
+		if info.FullPattern == pattern {
+			return info, true
+		}
+	}
+
+	for _, info := range r.routes.Routes {
+		if info.Pattern == pattern {
+			return info, true
+		}
+	}
+
+	return nil, false
+}
+
+func escapeWildcard(value string) string {
+	parts := strings.Split(value, "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	return strings.Join(parts, "/")
+}
+
+func buildURL(pattern string, params map[string]string) (string, error) {
+	used := map[string]bool{}
+	segments := strings.Split(pattern, "/")
+
+	for i, segment := range segments {
+		name, isParam := strings.CutPrefix(segment, "{")
+		name, hasSuffix := strings.CutSuffix(name, "}")
+
+		if !isParam || !hasSuffix {
+			continue
+		}
+
+		// {$} only matches the end of the path
+		if name == "$" {
+			segments[i] = ""
+			continue
+		}
+
+		name, isWildcard := strings.CutSuffix(name, "...")
+		value, has := params[name]
+
+		if !has {
+			return "", fmt.Errorf("%w: %q: %q", ErrMissingURLParam, pattern, name)
+		}
+		used[name] = true
+
+		if isWildcard {
+			segments[i] = escapeWildcard(value)
+		} else {
+			segments[i] = url.PathEscape(value)
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(params)) {
+		if !used[name] {
+			return "", fmt.Errorf("%w: %q: %q", ErrUnknownURLParam, pattern, name)
+		}
+	}
+
+	return strings.Join(segments, "/"), nil
+}
@@ -0,0 +1,104 @@
+package routey_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/zhamlin/routey"
+	"github.com/zhamlin/routey/internal/test"
+)
+
+func TestRouter_URL(t *testing.T) {
+	h := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+
+	r := newTestRouter(t)
+	r.Get("/users/{id}", h)
+	r.Get("/files/{path...}", h)
+	r.Get("/exact/{$}", h)
+	r.Route("/orgs/{org}", func(r *routey.Router) {
+		r.Get("/teams/{team}", h)
+	})
+
+	tests := []struct {
+		pattern string
+		params  map[string]string
+		want    string
+	}{
+		{
+			pattern: "/users/{id}",
+			params:  map[string]string{"id": "1"},
+			want:    "/users/1",
+		},
+		{
+			pattern: "/users/{id}",
+			params:  map[string]string{"id": "a b/c"},
+			want:    "/users/a%20b%2Fc",
+		},
+		{
+			pattern: "/files/{path...}",
+			params:  map[string]string{"path": "a/b c/d"},
+			want:    "/files/a/b%20c/d",
+		},
+		{
+			pattern: "/exact/{$}",
+			want:    "/exact/",
+		},
+		{
+			pattern: "/orgs/{org}/teams/{team}",
+			params:  map[string]string{"org": "o", "team": "t"},
+			want:    "/orgs/o/teams/t",
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := r.URL(tt.pattern, tt.params)
+		test.NoError(t, err)
+		test.Equal(t, got, tt.want)
+	}
+}
+
+func TestRouter_URLMounted(t *testing.T) {
+	h := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+
+	sub := newTestRouter(t)
+	sub.Get("/{id}", h)
+
+	r := newTestRouter(t)
+	r.Mount("/users", sub)
+
+	got, err := r.URL("/users/{id}", map[string]string{"id": "1"})
+	test.NoError(t, err)
+	test.Equal(t, got, "/users/1")
+}
+
+func TestRouter_URLErrors(t *testing.T) {
+	h := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+
+	r := newTestRouter(t)
+	r.Get("/users/{id}", h)
+
+	tests := []struct {
+		pattern string
+		params  map[string]string
+		want    error
+	}{
+		{
+			pattern: "/missing",
+			want:    routey.ErrRouteNotFound,
+		},
+		{
+			pattern: "/users/{id}",
+			want:    routey.ErrMissingURLParam,
+		},
+		{
+			pattern: "/users/{id}",
+			params:  map[string]string{"id": "1", "name": "a"},
+			want:    routey.ErrUnknownURLParam,
+		},
+	}
+
+	for _, tt := range tests {
+		_, err := r.URL(tt.pattern, tt.params)
+		test.IsError(t, err, tt.want)
+	}
+}